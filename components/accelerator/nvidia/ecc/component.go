@@ -4,12 +4,14 @@ package ecc
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/leptonai/gpud/components"
 	nvidia_query "github.com/leptonai/gpud/components/accelerator/nvidia/query"
 	nvidia_query_metrics_ecc "github.com/leptonai/gpud/components/accelerator/nvidia/query/metrics/ecc"
+	components_metrics "github.com/leptonai/gpud/components/metrics"
 	"github.com/leptonai/gpud/components/query"
 	"github.com/leptonai/gpud/log"
 
@@ -18,6 +20,10 @@ import (
 
 const Name = "accelerator-nvidia-ecc"
 
+const Description = "Tracks the NVIDIA GPU ECC errors and detects the GPU resets."
+
+var tags = []string{"accelerator", "gpu", "nvidia", "ecc"}
+
 func New(ctx context.Context, cfg Config) components.Component {
 	cfg.Query.SetDefaultsIfNotSet()
 
@@ -25,6 +31,7 @@ func New(ctx context.Context, cfg Config) components.Component {
 	nvidia_query.DefaultPoller.Start(cctx, cfg.Query, Name)
 
 	return &component{
+		cfg:     cfg,
 		rootCtx: ctx,
 		cancel:  ccancel,
 		poller:  nvidia_query.DefaultPoller,
@@ -34,6 +41,7 @@ func New(ctx context.Context, cfg Config) components.Component {
 var _ components.Component = (*component)(nil)
 
 type component struct {
+	cfg      Config
 	rootCtx  context.Context
 	cancel   context.CancelFunc
 	poller   query.Poller
@@ -42,8 +50,30 @@ type component struct {
 
 func (c *component) Name() string { return Name }
 
-func (c *component) States(ctx context.Context) ([]components.State, error) {
+func (c *component) Describe() components.ComponentInfo {
+	return components.ComponentInfo{
+		Name:        Name,
+		Description: Description,
+		Tags:        append([]string(nil), tags...),
+	}
+}
+
+var _ components.Readier = (*component)(nil)
+
+// Returns true if the poller has produced at least one result.
+func (c *component) Ready() bool {
+	if c.poller == nil {
+		return false
+	}
 	last, err := c.poller.Last()
+	return err == nil && last != nil
+}
+
+func (c *component) States(ctx context.Context) ([]components.State, error) {
+	last, err := components.FetchWithTimeout(ctx, c.cfg.Query.StatesTimeout.Duration, c.poller.Last)
+	if errors.Is(err, components.ErrFetchTimeout) {
+		return []components.State{components.TimeoutState(Name, err)}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -54,17 +84,19 @@ func (c *component) States(ctx context.Context) ([]components.State, error) {
 	if last.Error != nil {
 		return []components.State{
 			{
-				Healthy: false,
-				Error:   last.Error.Error(),
-				Reason:  "last query failed",
+				Healthy:    false,
+				Error:      last.Error.Error(),
+				Reason:     "last query failed",
+				ReasonCode: components.ReasonQueryFailed,
 			},
 		}, nil
 	}
 	if last.Output == nil {
 		return []components.State{
 			{
-				Healthy: false,
-				Reason:  "no output",
+				Healthy:    false,
+				Reason:     "no output",
+				ReasonCode: components.ReasonNoOutput,
 			},
 		}, nil
 	}
@@ -73,14 +105,18 @@ func (c *component) States(ctx context.Context) ([]components.State, error) {
 	if !ok {
 		return nil, fmt.Errorf("invalid output type: %T", last.Output)
 	}
+	if allOutput.SMIMissing() {
+		return []components.State{allOutput.SMIMissingState(Name)}, nil
+	}
 	if allOutput.SMIExists && len(allOutput.SMIQueryErrors) > 0 {
 		cs := make([]components.State, 0)
 		for _, e := range allOutput.SMIQueryErrors {
 			cs = append(cs, components.State{
-				Name:    Name,
-				Healthy: false,
-				Error:   e,
-				Reason:  "nvidia-smi query failed with " + e,
+				Name:       Name,
+				Healthy:    false,
+				Error:      e,
+				Reason:     "nvidia-smi query failed with " + e,
+				ReasonCode: components.ReasonQueryFailed,
 				ExtraInfo: map[string]string{
 					nvidia_query.StateKeySMIExists: fmt.Sprintf("%v", allOutput.SMIExists),
 				},
@@ -89,29 +125,94 @@ func (c *component) States(ctx context.Context) ([]components.State, error) {
 		return cs, nil
 	}
 	output := ToOutput(allOutput)
+
+	items, err := c.poller.All(time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	output.SetLastResetDetected(detectGPUResetsFromItems(items))
+
 	return output.States()
 }
 
 func (c *component) Events(ctx context.Context, since time.Time) ([]components.Event, error) {
-	return nil, nil
+	since = c.clampSince(since)
+
+	items, err := c.poller.All(time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	evs := createGPUResetEvents(detectGPUResetsFromItems(items), since)
+
+	if c.cfg.UncorrectedThreshold <= 0 {
+		return evs, nil
+	}
+
+	aggTotalUncorrecteds, err := nvidia_query_metrics_ecc.ReadAggregateTotalUncorrected(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read aggregate total uncorrected: %w", err)
+	}
+	volTotalUncorrecteds, err := nvidia_query_metrics_ecc.ReadVolatileTotalUncorrected(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read volatile total uncorrected: %w", err)
+	}
+
+	evs = append(evs, createUncorrectedThresholdEvents(EventValueECCCounterAggregate, aggTotalUncorrecteds, c.cfg.UncorrectedThreshold)...)
+	evs = append(evs, createUncorrectedThresholdEvents(EventValueECCCounterVolatile, volTotalUncorrecteds, c.cfg.UncorrectedThreshold)...)
+	return evs, nil
+}
+
+// Clamps "since" to the max lookback window, if configured.
+func (c *component) clampSince(since time.Time) time.Time {
+	if c.cfg.MaxLookback.Duration <= 0 {
+		return since
+	}
+	oldest := time.Now().Add(-c.cfg.MaxLookback.Duration)
+	if since.Before(oldest) {
+		log.Logger.Debugw("clamping since to max lookback", "since", since, "clampedSince", oldest, "maxLookback", c.cfg.MaxLookback.Duration)
+		return oldest
+	}
+	return since
 }
 
 func (c *component) Metrics(ctx context.Context, since time.Time) ([]components.Metric, error) {
-	log.Logger.Debugw("querying metrics", "since", since)
+	return c.MetricsFiltered(ctx, components.MetricsRequest{Since: since})
+}
+
+var _ components.MetricsFilterer = (*component)(nil)
+
+func (c *component) MetricsFiltered(ctx context.Context, req components.MetricsRequest) ([]components.Metric, error) {
+	log.Logger.Debugw("querying metrics", "since", req.Since, "gpuID", req.GPUID)
+
+	since := c.clampSince(req.Since)
+
+	// the metrics are stored by the GPU UUID, but the request may use the index or the PCI bus ID
+	ids := c.gpuIDs()
+	normalize := func(id string) string {
+		if uuid, ok := ids.NormalizeToUUID(id); ok {
+			return uuid
+		}
+		return id
+	}
+
+	var opts []components_metrics.OpOption
+	if req.GPUID != "" {
+		opts = append(opts, components_metrics.WithMetricSecondaryName(normalize(req.GPUID)))
+	}
 
-	aggTotalCorrecteds, err := nvidia_query_metrics_ecc.ReadAggregateTotalCorrected(ctx, since)
+	aggTotalCorrecteds, err := nvidia_query_metrics_ecc.ReadAggregateTotalCorrected(ctx, since, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read aggregate total corrected: %w", err)
 	}
-	aggTotalUncorrecteds, err := nvidia_query_metrics_ecc.ReadAggregateTotalUncorrected(ctx, since)
+	aggTotalUncorrecteds, err := nvidia_query_metrics_ecc.ReadAggregateTotalUncorrected(ctx, since, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read aggregate total corrected: %w", err)
 	}
-	volTotalCorrecteds, err := nvidia_query_metrics_ecc.ReadVolatileTotalCorrected(ctx, since)
+	volTotalCorrecteds, err := nvidia_query_metrics_ecc.ReadVolatileTotalCorrected(ctx, since, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read volatile total corrected: %w", err)
 	}
-	volTotalUncorrecteds, err := nvidia_query_metrics_ecc.ReadVolatileTotalUncorrected(ctx, since)
+	volTotalUncorrecteds, err := nvidia_query_metrics_ecc.ReadVolatileTotalUncorrected(ctx, since, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read volatile total corrected: %w", err)
 	}
@@ -121,7 +222,7 @@ func (c *component) Metrics(ctx context.Context, since time.Time) ([]components.
 		ms = append(ms, components.Metric{
 			Metric: m,
 			ExtraInfo: map[string]string{
-				"gpu_id": m.MetricSecondaryName,
+				"gpu_id": normalize(m.MetricSecondaryName),
 			},
 		})
 	}
@@ -129,7 +230,7 @@ func (c *component) Metrics(ctx context.Context, since time.Time) ([]components.
 		ms = append(ms, components.Metric{
 			Metric: m,
 			ExtraInfo: map[string]string{
-				"gpu_id": m.MetricSecondaryName,
+				"gpu_id": normalize(m.MetricSecondaryName),
 			},
 		})
 	}
@@ -137,7 +238,7 @@ func (c *component) Metrics(ctx context.Context, since time.Time) ([]components.
 		ms = append(ms, components.Metric{
 			Metric: m,
 			ExtraInfo: map[string]string{
-				"gpu_id": m.MetricSecondaryName,
+				"gpu_id": normalize(m.MetricSecondaryName),
 			},
 		})
 	}
@@ -145,7 +246,7 @@ func (c *component) Metrics(ctx context.Context, since time.Time) ([]components.
 		ms = append(ms, components.Metric{
 			Metric: m,
 			ExtraInfo: map[string]string{
-				"gpu_id": m.MetricSecondaryName,
+				"gpu_id": normalize(m.MetricSecondaryName),
 			},
 		})
 	}
@@ -153,6 +254,20 @@ func (c *component) Metrics(ctx context.Context, since time.Time) ([]components.
 	return ms, nil
 }
 
+// Returns the GPU identifier mapping from the last query output.
+// Returns an empty mapping if no output is available yet.
+func (c *component) gpuIDs() *nvidia_query.GPUIDs {
+	if c.poller == nil {
+		return nvidia_query.NewGPUIDs(nil)
+	}
+	last, err := c.poller.Last()
+	if err != nil || last == nil || last.Output == nil {
+		return nvidia_query.NewGPUIDs(nil)
+	}
+	o, _ := last.Output.(*nvidia_query.Output)
+	return nvidia_query.NewGPUIDs(o)
+}
+
 func (c *component) Close() error {
 	log.Logger.Debugw("closing component")
 
@@ -162,6 +277,16 @@ func (c *component) Close() error {
 	return nil
 }
 
+var _ components.Drainer = (*component)(nil)
+
+// Stops the new polls and waits for the in-flight poll to finish.
+func (c *component) Drain(ctx context.Context) error {
+	if c.poller == nil {
+		return nil
+	}
+	return c.poller.Drain(ctx)
+}
+
 var _ components.PromRegisterer = (*component)(nil)
 
 func (c *component) RegisterCollectors(reg *prometheus.Registry, db *sql.DB, tableName string) error {
@@ -0,0 +1,124 @@
+package ecc
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/leptonai/gpud/components"
+	nvidia_query "github.com/leptonai/gpud/components/accelerator/nvidia/query"
+	components_metrics_state "github.com/leptonai/gpud/components/metrics/state"
+	"github.com/leptonai/gpud/components/query"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// Emitted when the uncorrected error count of a GPU reaches the configured threshold.
+	EventNameUncorrectedThresholdCrossed = "ecc_uncorrected_threshold_crossed"
+
+	EventKeyUncorrectedThresholdCrossedUnixSeconds = "unix_seconds"
+	EventKeyUncorrectedThresholdCrossedGPUID       = "gpu_id"
+	EventKeyUncorrectedThresholdCrossedCounter     = "counter"
+	EventKeyUncorrectedThresholdCrossedBefore      = "before"
+	EventKeyUncorrectedThresholdCrossedAfter       = "after"
+	EventKeyUncorrectedThresholdCrossedThreshold   = "threshold"
+
+	// Emitted when the volatile error counts of a GPU are reset
+	// (e.g., GPU reset or driver reload) while the aggregate counts persist.
+	EventNameGPUReset = "ecc_gpu_reset"
+
+	EventKeyGPUResetUnixSeconds         = "unix_seconds"
+	EventKeyGPUResetGPUID               = "gpu_id"
+	EventKeyGPUResetVolatileCorrected   = "volatile_corrected_before"
+	EventKeyGPUResetVolatileUncorrected = "volatile_uncorrected_before"
+
+	EventValueECCCounterAggregate = "aggregate"
+	EventValueECCCounterVolatile  = "volatile"
+)
+
+// Returns an event for each GPU whose uncorrected error count went from below
+// the threshold to at or above the threshold between two consecutive samples.
+// The metrics are expected in ascending order of time (as read from the metrics store).
+func createUncorrectedThresholdEvents(counter string, ms components_metrics_state.Metrics, threshold int) []components.Event {
+	perGPU := make(map[string]components_metrics_state.Metrics)
+	for _, m := range ms {
+		perGPU[m.MetricSecondaryName] = append(perGPU[m.MetricSecondaryName], m)
+	}
+
+	gpuIDs := make([]string, 0, len(perGPU))
+	for gpuID := range perGPU {
+		gpuIDs = append(gpuIDs, gpuID)
+	}
+	sort.Strings(gpuIDs)
+
+	evs := make([]components.Event, 0)
+	for _, gpuID := range gpuIDs {
+		series := perGPU[gpuID]
+		for i := 1; i < len(series); i++ {
+			before, after := series[i-1].Value, series[i].Value
+			if before >= float64(threshold) || after < float64(threshold) {
+				continue
+			}
+			evs = append(evs, components.Event{
+				Time:    metav1.Time{Time: time.Unix(series[i].UnixSeconds, 0).UTC()},
+				Name:    EventNameUncorrectedThresholdCrossed,
+				Type:    components.EventTypeError,
+				Message: fmt.Sprintf("%s uncorrected ECC errors on GPU %s increased from %.0f to %.0f (threshold %d)", counter, gpuID, before, after, threshold),
+				ExtraInfo: map[string]string{
+					EventKeyUncorrectedThresholdCrossedUnixSeconds: strconv.FormatInt(series[i].UnixSeconds, 10),
+					EventKeyUncorrectedThresholdCrossedGPUID:       gpuID,
+					EventKeyUncorrectedThresholdCrossedCounter:     counter,
+					EventKeyUncorrectedThresholdCrossedBefore:      strconv.FormatFloat(before, 'f', -1, 64),
+					EventKeyUncorrectedThresholdCrossedAfter:       strconv.FormatFloat(after, 'f', -1, 64),
+					EventKeyUncorrectedThresholdCrossedThreshold:   strconv.Itoa(threshold),
+				},
+			})
+		}
+	}
+	return evs
+}
+
+// Returns the GPU resets detected between consecutive poller items.
+// The items are expected in ascending order of time (as returned by the poller).
+func detectGPUResetsFromItems(items []query.Item) []GPUReset {
+	resets := make([]GPUReset, 0)
+	var prev *Output
+	for _, item := range items {
+		if item.Error != nil || item.Output == nil {
+			continue
+		}
+		allOutput, ok := item.Output.(*nvidia_query.Output)
+		if !ok || allOutput.NVML == nil {
+			continue
+		}
+		cur := ToOutput(allOutput)
+		resets = append(resets, DetectGPUResets(prev, cur, item.Time.Time)...)
+		prev = cur
+	}
+	return resets
+}
+
+// Returns an event for each GPU reset detected at or after "since".
+func createGPUResetEvents(resets []GPUReset, since time.Time) []components.Event {
+	evs := make([]components.Event, 0)
+	for _, r := range resets {
+		if !since.IsZero() && r.Time.Before(since) {
+			continue
+		}
+		evs = append(evs, components.Event{
+			Time:    metav1.Time{Time: r.Time.UTC()},
+			Name:    EventNameGPUReset,
+			Type:    components.EventTypeInfo,
+			Message: fmt.Sprintf("gpu %d (%s) reset detected (volatile ECC errors reset from %d corrected and %d uncorrected to zero)", r.After.Index, r.After.UUID, r.Before.VolatileCorrected, r.Before.VolatileUncorrected),
+			ExtraInfo: map[string]string{
+				EventKeyGPUResetUnixSeconds:         strconv.FormatInt(r.Time.Unix(), 10),
+				EventKeyGPUResetGPUID:               r.After.UUID,
+				EventKeyGPUResetVolatileCorrected:   strconv.FormatUint(r.Before.VolatileCorrected, 10),
+				EventKeyGPUResetVolatileUncorrected: strconv.FormatUint(r.Before.VolatileUncorrected, 10),
+			},
+		})
+	}
+	return evs
+}
@@ -0,0 +1,115 @@
+package ecc
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	nvidia_query "github.com/leptonai/gpud/components/accelerator/nvidia/query"
+	nvidia_query_nvml "github.com/leptonai/gpud/components/accelerator/nvidia/query/nvml"
+	components_metrics_state "github.com/leptonai/gpud/components/metrics/state"
+	"github.com/leptonai/gpud/components/query"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCreateUncorrectedThresholdEvents(t *testing.T) {
+	t.Parallel()
+
+	ms := components_metrics_state.Metrics{
+		{UnixSeconds: 100, MetricSecondaryName: "GPU-0", Value: 0},
+		{UnixSeconds: 100, MetricSecondaryName: "GPU-1", Value: 5},
+		{UnixSeconds: 200, MetricSecondaryName: "GPU-0", Value: 1},
+		{UnixSeconds: 200, MetricSecondaryName: "GPU-1", Value: 6},
+		{UnixSeconds: 300, MetricSecondaryName: "GPU-0", Value: 3},
+		{UnixSeconds: 400, MetricSecondaryName: "GPU-0", Value: 4},
+	}
+
+	evs := createUncorrectedThresholdEvents(EventValueECCCounterVolatile, ms, 2)
+	if len(evs) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(evs))
+	}
+	ev := evs[0]
+	if ev.Name != EventNameUncorrectedThresholdCrossed {
+		t.Fatalf("expected event name %q, got %q", EventNameUncorrectedThresholdCrossed, ev.Name)
+	}
+	if ev.ExtraInfo[EventKeyUncorrectedThresholdCrossedGPUID] != "GPU-0" {
+		t.Fatalf("expected GPU-0, got %q", ev.ExtraInfo[EventKeyUncorrectedThresholdCrossedGPUID])
+	}
+	if ev.ExtraInfo[EventKeyUncorrectedThresholdCrossedBefore] != "1" || ev.ExtraInfo[EventKeyUncorrectedThresholdCrossedAfter] != "3" {
+		t.Fatalf("unexpected before/after: %v", ev.ExtraInfo)
+	}
+	if ev.Time.Unix() != 300 {
+		t.Fatalf("expected event time 300, got %d", ev.Time.Unix())
+	}
+}
+
+func TestDetectGPUResetsFromItems(t *testing.T) {
+	t.Parallel()
+
+	newItem := func(ts time.Time, volCorrected, volUncorrected, aggCorrected, aggUncorrected uint64) query.Item {
+		return query.Item{
+			Time: metav1.Time{Time: ts},
+			Output: &nvidia_query.Output{
+				NVML: &nvidia_query_nvml.Output{
+					DeviceInfos: []*nvidia_query_nvml.DeviceInfo{
+						{
+							UUID:        "GPU-0",
+							MinorNumber: 0,
+							ECCErrors: nvidia_query_nvml.ECCErrors{
+								UUID:      "GPU-0",
+								Aggregate: nvidia_query_nvml.AllECCErrorCounts{Total: nvidia_query_nvml.ECCErrorCounts{Corrected: aggCorrected, Uncorrected: aggUncorrected}},
+								Volatile:  nvidia_query_nvml.AllECCErrorCounts{Total: nvidia_query_nvml.ECCErrorCounts{Corrected: volCorrected, Uncorrected: volUncorrected}},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	now := time.Unix(1000, 0).UTC()
+	items := []query.Item{
+		newItem(now, 3, 1, 10, 2),
+		{Time: metav1.Time{Time: now.Add(time.Second)}, Error: errors.New("query failed")},
+		// volatile counts reset, aggregate counts persist
+		newItem(now.Add(2*time.Second), 0, 0, 10, 2),
+		// still zero, not a new reset
+		newItem(now.Add(3*time.Second), 0, 0, 10, 2),
+		newItem(now.Add(4*time.Second), 1, 0, 11, 2),
+		// aggregate counts decreased (e.g., different device), not a reset
+		newItem(now.Add(5*time.Second), 0, 0, 0, 0),
+	}
+
+	resets := detectGPUResetsFromItems(items)
+	if len(resets) != 1 {
+		t.Fatalf("expected 1 reset, got %d (%+v)", len(resets), resets)
+	}
+	if !resets[0].Time.Equal(now.Add(2*time.Second)) || resets[0].Before.VolatileCorrected != 3 || resets[0].Before.VolatileUncorrected != 1 {
+		t.Fatalf("unexpected reset: %+v", resets[0])
+	}
+
+	evs := createGPUResetEvents(resets, time.Time{})
+	if len(evs) != 1 || evs[0].Name != EventNameGPUReset {
+		t.Fatalf("unexpected events: %+v", evs)
+	}
+	if evs[0].ExtraInfo[EventKeyGPUResetGPUID] != "GPU-0" || evs[0].ExtraInfo[EventKeyGPUResetVolatileCorrected] != "3" {
+		t.Fatalf("unexpected event extra info: %+v", evs[0].ExtraInfo)
+	}
+	if evs := createGPUResetEvents(resets, now.Add(3*time.Second)); len(evs) != 0 {
+		t.Fatalf("expected no events since the reset, got %+v", evs)
+	}
+
+	o := ToOutput(items[len(items)-1].Output.(*nvidia_query.Output))
+	o.SetLastResetDetected(resets)
+	if o.PerGPU[0].LastResetDetected == nil || !o.PerGPU[0].LastResetDetected.Time.Equal(now.Add(2*time.Second)) {
+		t.Fatalf("unexpected last reset detected: %+v", o.PerGPU[0].LastResetDetected)
+	}
+	states, err := o.States()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if states[1].ExtraInfo[StateKeyECCErrorsGPULastResetDetected] != "1970-01-01T00:16:42Z" {
+		t.Fatalf("unexpected state extra info: %+v", states[1].ExtraInfo)
+	}
+}
@@ -0,0 +1,141 @@
+package ecc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/leptonai/gpud/components"
+	nvidia_query "github.com/leptonai/gpud/components/accelerator/nvidia/query"
+	nvidia_query_metrics_ecc "github.com/leptonai/gpud/components/accelerator/nvidia/query/metrics/ecc"
+	"github.com/leptonai/gpud/components/accelerator/nvidia/query/nvml"
+	metrics_state "github.com/leptonai/gpud/components/metrics/state"
+	"github.com/leptonai/gpud/components/query"
+	"github.com/leptonai/gpud/components/state"
+)
+
+type lastPoller struct {
+	query.Poller
+	last *query.Item
+}
+
+func (p *lastPoller) Last() (*query.Item, error) {
+	return p.last, nil
+}
+
+func TestComponentMetricsFiltered(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	db, err := state.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	tableName := "test_metrics"
+	if err := metrics_state.CreateTable(ctx, db, tableName); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	nvidia_query_metrics_ecc.InitAveragers(db, tableName)
+
+	now := time.Now()
+	for _, gpuID := range []string{"GPU-0", "GPU-1"} {
+		if err := nvidia_query_metrics_ecc.SetAggregateTotalCorrected(ctx, gpuID, 1, now); err != nil {
+			t.Fatal(err)
+		}
+		if err := nvidia_query_metrics_ecc.SetAggregateTotalUncorrected(ctx, gpuID, 2, now); err != nil {
+			t.Fatal(err)
+		}
+		if err := nvidia_query_metrics_ecc.SetVolatileTotalCorrected(ctx, gpuID, 3, now); err != nil {
+			t.Fatal(err)
+		}
+		if err := nvidia_query_metrics_ecc.SetVolatileTotalUncorrected(ctx, gpuID, 4, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := &component{}
+	since := now.Add(-time.Minute)
+
+	all, err := c.Metrics(ctx, since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 8 {
+		t.Fatalf("expected 8 metrics for all GPUs, got %d", len(all))
+	}
+
+	single, err := c.MetricsFiltered(ctx, components.MetricsRequest{Since: since, GPUID: "GPU-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(single) != 4 {
+		t.Fatalf("expected 4 metrics for a single GPU, got %d", len(single))
+	}
+	for _, m := range single {
+		if m.MetricSecondaryName != "GPU-1" || m.ExtraInfo["gpu_id"] != "GPU-1" {
+			t.Fatalf("unexpected metric %+v", m)
+		}
+		if m.UnixSeconds != now.Unix() {
+			t.Fatalf("expected timestamp %d, got %+v", now.Unix(), m)
+		}
+	}
+
+	none, err := c.MetricsFiltered(ctx, components.MetricsRequest{Since: since, GPUID: "GPU-9"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(none) != 0 {
+		t.Fatalf("expected no metrics for unknown GPU, got %d", len(none))
+	}
+
+	// the GPU index and the PCI bus ID resolve to the UUID from the last output
+	c.poller = &lastPoller{last: &query.Item{Output: &nvidia_query.Output{
+		NVML: &nvml.Output{
+			DeviceInfos: []*nvml.DeviceInfo{
+				{UUID: "GPU-0", Index: 0, Bus: 0x53},
+				{UUID: "GPU-1", Index: 1, Bus: 0xc3},
+			},
+		},
+	}}}
+	for _, gpuID := range []string{"1", "0000:c3:00.0", "gpu-1"} {
+		ms, err := c.MetricsFiltered(ctx, components.MetricsRequest{Since: since, GPUID: gpuID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ms) != 4 {
+			t.Fatalf("expected 4 metrics for GPU %q, got %d", gpuID, len(ms))
+		}
+		for _, m := range ms {
+			if m.ExtraInfo["gpu_id"] != "GPU-1" {
+				t.Fatalf("expected gpu_id GPU-1 for GPU %q, got %+v", gpuID, m)
+			}
+			if m.UnixSeconds != now.Unix() {
+				t.Fatalf("expected timestamp %d for GPU %q, got %+v", now.Unix(), gpuID, m)
+			}
+		}
+	}
+}
+
+func TestComponentReady(t *testing.T) {
+	t.Parallel()
+
+	if (&component{}).Ready() {
+		t.Fatal("expected not ready without the poller")
+	}
+
+	p := &lastPoller{}
+	c := &component{poller: p}
+	if c.Ready() {
+		t.Fatal("expected not ready before the first poll")
+	}
+
+	p.last = &query.Item{Output: &nvidia_query.Output{}}
+	if !c.Ready() {
+		t.Fatal("expected ready after the first poll")
+	}
+	if !components.Ready(c) {
+		t.Fatal("expected ready via components.Ready")
+	}
+}
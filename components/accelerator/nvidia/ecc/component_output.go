@@ -4,30 +4,46 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/leptonai/gpud/components"
 	nvidia_query "github.com/leptonai/gpud/components/accelerator/nvidia/query"
 	nvidia_query_nvml "github.com/leptonai/gpud/components/accelerator/nvidia/query/nvml"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func ToOutput(i *nvidia_query.Output) *Output {
 	o := &Output{}
-	for _, g := range i.SMI.GPUs {
-		if g.ECCErrors == nil {
-			continue
-		}
+	if i.SMI != nil {
+		for _, g := range i.SMI.GPUs {
+			if g.ECCErrors == nil {
+				continue
+			}
 
-		o.ErrorCountsSMI = append(o.ErrorCountsSMI, *g.ECCErrors)
+			o.ErrorCountsSMI = append(o.ErrorCountsSMI, *g.ECCErrors)
 
-		if errs := g.ECCErrors.FindVolatileUncorrectableErrs(); len(errs) > 0 {
-			o.VolatileUncorrectedErrors = append(o.VolatileUncorrectedErrors, fmt.Sprintf("[%s] %s", g.ID, strings.Join(errs, ", ")))
+			if errs := g.ECCErrors.FindVolatileUncorrectableErrs(); len(errs) > 0 {
+				o.VolatileUncorrectedErrors = append(o.VolatileUncorrectedErrors, fmt.Sprintf("[%s] %s", g.ID, strings.Join(errs, ", ")))
+			}
 		}
 	}
 
 	if i.NVML != nil {
 		for _, dev := range i.NVML.DeviceInfos {
 			o.ErrorCountsNVML = append(o.ErrorCountsNVML, dev.ECCErrors)
+			o.PerGPU = append(o.PerGPU, GPUECCErrorCounts{
+				Index:                dev.MinorNumber,
+				UUID:                 dev.UUID,
+				VolatileCorrected:    dev.ECCErrors.Volatile.Total.Corrected,
+				VolatileUncorrected:  dev.ECCErrors.Volatile.Total.Uncorrected,
+				AggregateCorrected:   dev.ECCErrors.Aggregate.Total.Corrected,
+				AggregateUncorrected: dev.ECCErrors.Aggregate.Total.Uncorrected,
+				RemappedRows:         remappedRows(dev.RemappedRows),
+			})
 
 			if errs := dev.ECCErrors.Volatile.FindUncorrectedErrs(); len(errs) > 0 {
 				o.VolatileUncorrectedErrors = append(o.VolatileUncorrectedErrors, fmt.Sprintf("[%s] %s", dev.UUID, strings.Join(errs, ", ")))
@@ -35,9 +51,114 @@ func ToOutput(i *nvidia_query.Output) *Output {
 		}
 	}
 
+	sort.Slice(o.PerGPU, func(i, j int) bool {
+		return o.PerGPU[i].Index < o.PerGPU[j].Index
+	})
+
 	return o
 }
 
+// Returns nil if the device does not support the row remapping.
+func remappedRows(r nvidia_query_nvml.RemappedRows) *nvidia_query_nvml.RemappedRows {
+	if !r.Supported {
+		return nil
+	}
+	return &r
+}
+
+// GPUReset is a GPU reset detected between two consecutive outputs.
+type GPUReset struct {
+	Time   time.Time         `json:"time"`
+	Before GPUECCErrorCounts `json:"before"`
+	After  GPUECCErrorCounts `json:"after"`
+}
+
+// Returns the GPUs that were reset between the two outputs.
+// A GPU reset clears the volatile counters while the aggregate counters
+// persist, thus a reset is detected when the volatile counts drop to zero
+// from a non-zero value while the aggregate counts do not decrease.
+func DetectGPUResets(prev *Output, cur *Output, now time.Time) []GPUReset {
+	if prev == nil || cur == nil {
+		return nil
+	}
+
+	prevs := make(map[string]GPUECCErrorCounts, len(prev.PerGPU))
+	for _, g := range prev.PerGPU {
+		prevs[g.UUID] = g
+	}
+
+	resets := make([]GPUReset, 0)
+	for _, g := range cur.PerGPU {
+		p, ok := prevs[g.UUID]
+		if !ok {
+			continue
+		}
+		if p.VolatileCorrected+p.VolatileUncorrected == 0 {
+			continue
+		}
+		if g.VolatileCorrected+g.VolatileUncorrected != 0 {
+			continue
+		}
+		if g.AggregateCorrected < p.AggregateCorrected || g.AggregateUncorrected < p.AggregateUncorrected {
+			continue
+		}
+		resets = append(resets, GPUReset{Time: now, Before: p, After: g})
+	}
+	return resets
+}
+
+// Sets the last reset detected time of each GPU in the output,
+// based on the resets in ascending order of time.
+// The time is truncated to seconds, as persisted in the states,
+// so that the output parsed from the states (see ParseStatesToOutput) is identical.
+func (o *Output) SetLastResetDetected(resets []GPUReset) {
+	last := make(map[string]time.Time)
+	for _, r := range resets {
+		if r.Time.After(last[r.After.UUID]) {
+			last[r.After.UUID] = r.Time
+		}
+	}
+	for i := range o.PerGPU {
+		t, ok := last[o.PerGPU[i].UUID]
+		if !ok {
+			continue
+		}
+		o.PerGPU[i].LastResetDetected = &metav1.Time{Time: t.UTC().Truncate(time.Second)}
+	}
+}
+
+// GPUECCErrorCounts is the total ECC error counts of a single GPU.
+type GPUECCErrorCounts struct {
+	// Index is the GPU index (minor number of the device).
+	Index int    `json:"index"`
+	UUID  string `json:"uuid"`
+
+	VolatileCorrected    uint64 `json:"volatile_corrected"`
+	VolatileUncorrected  uint64 `json:"volatile_uncorrected"`
+	AggregateCorrected   uint64 `json:"aggregate_corrected"`
+	AggregateUncorrected uint64 `json:"aggregate_uncorrected"`
+
+	// LastResetDetected is the last time the GPU reset was detected
+	// (volatile counts dropped to zero while aggregate counts persisted),
+	// so the volatile counts are the counts since the last reset.
+	LastResetDetected *metav1.Time `json:"last_reset_detected,omitempty"`
+
+	// RemappedRows is the row remapping data from NVML,
+	// nil if the GPU does not support the row remapping (e.g., pre-Ampere).
+	// The remapping failure is a stronger signal for the GPU replacement than the raw ECC counts.
+	RemappedRows *nvidia_query_nvml.RemappedRows `json:"remapped_rows,omitempty"`
+}
+
+// Returns true if the row remapping failed on the GPU.
+func (g GPUECCErrorCounts) remappingFailed() bool {
+	return g.RemappedRows != nil && g.RemappedRows.RemappingFailed
+}
+
+// Returns true if the GPU has the rows pending remapping.
+func (g GPUECCErrorCounts) remappingPending() bool {
+	return g.RemappedRows != nil && g.RemappedRows.RemappingPending
+}
+
 type Output struct {
 	ErrorCountsSMI  []nvidia_query.SMIECCErrors   `json:"error_counts_smi"`
 	ErrorCountsNVML []nvidia_query_nvml.ECCErrors `json:"error_counts_nvml"`
@@ -51,6 +172,9 @@ type Output struct {
 	// For Texture memory, these are errors where the resend fails.
 	// ref. https://docs.nvidia.com/deploy/nvml-api/group__nvmlDeviceEnumvs.html#group__nvmlDeviceEnumvs_1gc5469bd68b9fdcf78734471d86becb24
 	VolatileUncorrectedErrors []string `json:"volatile_uncorrected_errors"`
+
+	// PerGPU is the per-GPU total error counts from NVML, sorted by the GPU index.
+	PerGPU []GPUECCErrorCounts `json:"per_gpu,omitempty"`
 }
 
 func (o *Output) JSON() ([]byte, error) {
@@ -71,13 +195,84 @@ const (
 	StateKeyECCErrorsData           = "data"
 	StateKeyECCErrorsEncoding       = "encoding"
 	StateValueECCErrorsEncodingJSON = "json"
+
+	// StateNamePrefixECCErrorsGPU is the prefix of the per-GPU state names
+	// (e.g., "ecc_errors_gpu_0").
+	StateNamePrefixECCErrorsGPU = "ecc_errors_gpu_"
+
+	StateKeyECCErrorsGPUIndex                = "gpu_index"
+	StateKeyECCErrorsGPUUUID                 = "gpu_uuid"
+	StateKeyECCErrorsGPUVolatileCorrected    = "volatile_corrected"
+	StateKeyECCErrorsGPUVolatileUncorrected  = "volatile_uncorrected"
+	StateKeyECCErrorsGPUAggregateCorrected   = "aggregate_corrected"
+	StateKeyECCErrorsGPUAggregateUncorrected = "aggregate_uncorrected"
+	StateKeyECCErrorsGPULastResetDetected    = "last_reset_detected"
+
+	StateKeyECCErrorsGPURemappedDueToCorrectable   = "remapped_rows_due_to_correctable_errors"
+	StateKeyECCErrorsGPURemappedDueToUncorrectable = "remapped_rows_due_to_uncorrectable_errors"
+	StateKeyECCErrorsGPURemappingPending           = "remapping_pending"
+	StateKeyECCErrorsGPURemappingFailed            = "remapping_failed"
 )
 
+// Parses the output from the summary state extra info (see States).
+// The data without the encoding key is treated as plain JSON.
 func ParseStateECCErrors(m map[string]string) (*Output, error) {
-	data := m[StateKeyECCErrorsData]
-	return ParseOutputJSON([]byte(data))
+	switch m[StateKeyECCErrorsEncoding] {
+	case "", StateValueECCErrorsEncodingJSON:
+	default:
+		return nil, fmt.Errorf("unknown state data encoding: %s", m[StateKeyECCErrorsEncoding])
+	}
+	return ParseOutputJSON([]byte(m[StateKeyECCErrorsData]))
+}
+
+// Returns the GPUs whose row remapping failed, in the "[uuid]" format.
+func (o *Output) remappingFailures() []string {
+	var failures []string
+	for _, g := range o.PerGPU {
+		if g.remappingFailed() {
+			failures = append(failures, fmt.Sprintf("[%s]", g.UUID))
+		}
+	}
+	return failures
+}
+
+// Returns the unhealthy status if any volatile uncorrected error
+// or any row remapping failure is found,
+// and the degraded status if any GPU has the volatile errors
+// or the pending row remapping otherwise
+// (e.g., the corrected single-bit errors).
+func (o *Output) status() components.Status {
+	if len(o.VolatileUncorrectedErrors) > 0 || len(o.remappingFailures()) > 0 {
+		return components.StatusUnhealthy
+	}
+	for _, g := range o.PerGPU {
+		if g.status() != components.StatusHealthy {
+			return components.StatusDegraded
+		}
+	}
+	return components.StatusHealthy
 }
 
+// Returns the unhealthy status for the volatile uncorrected errors
+// and the row remapping failure,
+// and the degraded status for the volatile corrected (single-bit) errors,
+// as the hardware corrected them, and the pending row remapping,
+// as it only requires the GPU reset.
+func (g GPUECCErrorCounts) status() components.Status {
+	switch {
+	case g.VolatileUncorrected > 0, g.remappingFailed():
+		return components.StatusUnhealthy
+	case g.VolatileCorrected > 0, g.remappingPending():
+		return components.StatusDegraded
+	default:
+		return components.StatusHealthy
+	}
+}
+
+// Returns the output from the states, the inverse of States,
+// to rehydrate the output from the stored states (e.g., for the offline analysis).
+// The whole output is parsed from the summary state,
+// and the per-GPU states are skipped in any order.
 func ParseStatesToOutput(states ...components.State) (*Output, error) {
 	for _, state := range states {
 		switch state.Name {
@@ -89,6 +284,11 @@ func ParseStatesToOutput(states ...components.State) (*Output, error) {
 			return o, nil
 
 		default:
+			// per-GPU states are derived from the summary state
+			if strings.HasPrefix(state.Name, StateNamePrefixECCErrorsGPU) {
+				continue
+			}
+
 			return nil, fmt.Errorf("unknown state name: %s", state.Name)
 		}
 	}
@@ -107,15 +307,81 @@ func (o *Output) States() ([]components.State, error) {
 		)
 	}
 
+	// the row remapping failure qualifies the GPU for the replacement
+	// regardless of the volatile counts
+	failures := o.remappingFailures()
+	if len(failures) > 0 {
+		if reasons != "" {
+			reasons += "; "
+		}
+		reasons += fmt.Sprintf("%d GPUs with the row remapping failure: %s",
+			len(failures),
+			strings.Join(failures, ", "),
+		)
+	}
+
 	b, _ := o.JSON()
 	state := components.State{
 		Name:    StateNameECCErrors,
-		Healthy: len(o.VolatileUncorrectedErrors) == 0,
+		Healthy: len(o.VolatileUncorrectedErrors) == 0 && len(failures) == 0,
 		Reason:  reasons,
 		ExtraInfo: map[string]string{
 			StateKeyECCErrorsData:     string(b),
 			StateKeyECCErrorsEncoding: StateValueECCErrorsEncodingJSON,
 		},
 	}
-	return []components.State{state}, nil
+	state.Status = o.status()
+	if !state.Healthy {
+		state.ReasonCode = components.ReasonThresholdExceeded
+	}
+	states := []components.State{state}
+	for _, g := range o.PerGPU {
+		states = append(states, g.State())
+	}
+	return states, nil
+}
+
+// Returns the state of a single GPU, where the healthy is
+// evaluated by the volatile uncorrected error count
+// (aggregate counts persist across reboots) and the row remapping failure.
+func (g GPUECCErrorCounts) State() components.State {
+	reason := fmt.Sprintf("gpu %d (%s) has %d volatile uncorrected errors, %d volatile corrected errors",
+		g.Index, g.UUID, g.VolatileUncorrected, g.VolatileCorrected)
+	if r := g.RemappedRows; r != nil {
+		reason += fmt.Sprintf(", %d rows remapped due to uncorrectable errors, %d rows remapped due to correctable errors",
+			r.RemappedDueToUncorrectableErrors, r.RemappedDueToCorrectableErrors)
+		if r.RequiresReset() {
+			reason += ", row remapping pending (requires GPU reset)"
+		}
+		if r.QualifiesForRMA() {
+			reason += ", row remapping failed (qualifies for RMA)"
+		}
+	}
+	state := components.State{
+		Name:    StateNamePrefixECCErrorsGPU + strconv.Itoa(g.Index),
+		Healthy: g.VolatileUncorrected == 0 && !g.remappingFailed(),
+		Reason:  reason,
+		ExtraInfo: map[string]string{
+			StateKeyECCErrorsGPUIndex:                strconv.Itoa(g.Index),
+			StateKeyECCErrorsGPUUUID:                 g.UUID,
+			StateKeyECCErrorsGPUVolatileCorrected:    strconv.FormatUint(g.VolatileCorrected, 10),
+			StateKeyECCErrorsGPUVolatileUncorrected:  strconv.FormatUint(g.VolatileUncorrected, 10),
+			StateKeyECCErrorsGPUAggregateCorrected:   strconv.FormatUint(g.AggregateCorrected, 10),
+			StateKeyECCErrorsGPUAggregateUncorrected: strconv.FormatUint(g.AggregateUncorrected, 10),
+		},
+	}
+	if g.LastResetDetected != nil {
+		state.ExtraInfo[StateKeyECCErrorsGPULastResetDetected] = g.LastResetDetected.UTC().Format(time.RFC3339)
+	}
+	if r := g.RemappedRows; r != nil {
+		state.ExtraInfo[StateKeyECCErrorsGPURemappedDueToCorrectable] = strconv.Itoa(r.RemappedDueToCorrectableErrors)
+		state.ExtraInfo[StateKeyECCErrorsGPURemappedDueToUncorrectable] = strconv.Itoa(r.RemappedDueToUncorrectableErrors)
+		state.ExtraInfo[StateKeyECCErrorsGPURemappingPending] = strconv.FormatBool(r.RemappingPending)
+		state.ExtraInfo[StateKeyECCErrorsGPURemappingFailed] = strconv.FormatBool(r.RemappingFailed)
+	}
+	state.Status = g.status()
+	if !state.Healthy {
+		state.ReasonCode = components.ReasonThresholdExceeded
+	}
+	return state
 }
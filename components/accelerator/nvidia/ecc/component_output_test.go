@@ -0,0 +1,336 @@
+package ecc
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leptonai/gpud/components"
+	nvidia_query "github.com/leptonai/gpud/components/accelerator/nvidia/query"
+	nvidia_query_nvml "github.com/leptonai/gpud/components/accelerator/nvidia/query/nvml"
+)
+
+func TestOutputPerGPUStates(t *testing.T) {
+	in := &nvidia_query.Output{
+		SMI: &nvidia_query.SMIOutput{},
+		NVML: &nvidia_query_nvml.Output{
+			DeviceInfos: []*nvidia_query_nvml.DeviceInfo{
+				{
+					UUID:        "GPU-1",
+					MinorNumber: 1,
+					ECCErrors: nvidia_query_nvml.ECCErrors{
+						UUID:      "GPU-1",
+						Aggregate: nvidia_query_nvml.AllECCErrorCounts{Total: nvidia_query_nvml.ECCErrorCounts{Corrected: 5, Uncorrected: 3}},
+						Volatile:  nvidia_query_nvml.AllECCErrorCounts{Total: nvidia_query_nvml.ECCErrorCounts{Corrected: 2, Uncorrected: 1}},
+					},
+				},
+				{
+					UUID:        "GPU-0",
+					MinorNumber: 0,
+					ECCErrors: nvidia_query_nvml.ECCErrors{
+						UUID:      "GPU-0",
+						Aggregate: nvidia_query_nvml.AllECCErrorCounts{Total: nvidia_query_nvml.ECCErrorCounts{Corrected: 4}},
+					},
+				},
+			},
+		},
+	}
+
+	o := ToOutput(in)
+	states, err := o.States()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(states) != 3 {
+		t.Fatalf("expected 3 states, got %d", len(states))
+	}
+	if states[0].Name != StateNameECCErrors {
+		t.Fatalf("expected summary state first, got %q", states[0].Name)
+	}
+
+	gpu0, gpu1 := states[1], states[2]
+	if gpu0.Name != "ecc_errors_gpu_0" || !gpu0.Healthy || gpu0.ReasonCode != "" {
+		t.Fatalf("unexpected gpu 0 state: %+v", gpu0)
+	}
+	if gpu0.ExtraInfo[StateKeyECCErrorsGPUAggregateCorrected] != "4" {
+		t.Fatalf("unexpected gpu 0 aggregate corrected: %q", gpu0.ExtraInfo[StateKeyECCErrorsGPUAggregateCorrected])
+	}
+	if gpu1.Name != "ecc_errors_gpu_1" || gpu1.Healthy || gpu1.ReasonCode != components.ReasonThresholdExceeded {
+		t.Fatalf("unexpected gpu 1 state: %+v", gpu1)
+	}
+	if gpu1.ExtraInfo[StateKeyECCErrorsGPUUUID] != "GPU-1" || gpu1.ExtraInfo[StateKeyECCErrorsGPUVolatileUncorrected] != "1" {
+		t.Fatalf("unexpected gpu 1 extra info: %+v", gpu1.ExtraInfo)
+	}
+
+	parsed, err := ParseStatesToOutput(states...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed.PerGPU) != 2 || parsed.PerGPU[0].UUID != "GPU-0" {
+		t.Fatalf("unexpected parsed output: %+v", parsed.PerGPU)
+	}
+}
+
+func TestOutputStatesStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		perGPU        []GPUECCErrorCounts
+		uncorrected   []string
+		wantSummary   components.Status
+		wantPerGPU    []components.Status
+		wantSummaryOK bool
+	}{
+		{
+			name:          "no errors",
+			perGPU:        []GPUECCErrorCounts{{Index: 0, UUID: "GPU-0", AggregateCorrected: 10}},
+			wantSummary:   components.StatusHealthy,
+			wantPerGPU:    []components.Status{components.StatusHealthy},
+			wantSummaryOK: true,
+		},
+		{
+			name: "corrected errors only",
+			perGPU: []GPUECCErrorCounts{
+				{Index: 0, UUID: "GPU-0"},
+				{Index: 1, UUID: "GPU-1", VolatileCorrected: 3},
+			},
+			wantSummary:   components.StatusDegraded,
+			wantPerGPU:    []components.Status{components.StatusHealthy, components.StatusDegraded},
+			wantSummaryOK: true,
+		},
+		{
+			name: "uncorrected errors",
+			perGPU: []GPUECCErrorCounts{
+				{Index: 0, UUID: "GPU-0", VolatileCorrected: 3},
+				{Index: 1, UUID: "GPU-1", VolatileCorrected: 3, VolatileUncorrected: 1},
+			},
+			uncorrected:   []string{"[GPU-1] dram"},
+			wantSummary:   components.StatusUnhealthy,
+			wantPerGPU:    []components.Status{components.StatusDegraded, components.StatusUnhealthy},
+			wantSummaryOK: false,
+		},
+		{
+			name: "row remapping pending",
+			perGPU: []GPUECCErrorCounts{
+				{Index: 0, UUID: "GPU-0", RemappedRows: &nvidia_query_nvml.RemappedRows{Supported: true}},
+				{Index: 1, UUID: "GPU-1", RemappedRows: &nvidia_query_nvml.RemappedRows{Supported: true, RemappedDueToUncorrectableErrors: 1, RemappingPending: true}},
+			},
+			wantSummary:   components.StatusDegraded,
+			wantPerGPU:    []components.Status{components.StatusHealthy, components.StatusDegraded},
+			wantSummaryOK: true,
+		},
+		{
+			name: "row remapping failed without volatile errors",
+			perGPU: []GPUECCErrorCounts{
+				{Index: 0, UUID: "GPU-0", RemappedRows: &nvidia_query_nvml.RemappedRows{Supported: true, RemappedDueToCorrectableErrors: 2}},
+				{Index: 1, UUID: "GPU-1", RemappedRows: &nvidia_query_nvml.RemappedRows{Supported: true, RemappedDueToUncorrectableErrors: 512, RemappingFailed: true}},
+			},
+			wantSummary:   components.StatusUnhealthy,
+			wantPerGPU:    []components.Status{components.StatusHealthy, components.StatusUnhealthy},
+			wantSummaryOK: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &Output{PerGPU: tt.perGPU, VolatileUncorrectedErrors: tt.uncorrected}
+			states, err := o.States()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if states[0].Status != tt.wantSummary || states[0].Healthy != tt.wantSummaryOK {
+				t.Fatalf("expected summary status %q (healthy %v), got %q (healthy %v)", tt.wantSummary, tt.wantSummaryOK, states[0].Status, states[0].Healthy)
+			}
+			for i, want := range tt.wantPerGPU {
+				got := states[i+1]
+				if got.Status != want {
+					t.Fatalf("expected gpu %d status %q, got %q", i, want, got.Status)
+				}
+				// the healthy bool is false only for the unhealthy status
+				if got.Healthy != (want != components.StatusUnhealthy) {
+					t.Fatalf("expected gpu %d healthy %v for status %q", i, want != components.StatusUnhealthy, want)
+				}
+			}
+		})
+	}
+}
+
+func TestOutputRemappedRows(t *testing.T) {
+	t.Parallel()
+
+	in := &nvidia_query.Output{
+		NVML: &nvidia_query_nvml.Output{
+			DeviceInfos: []*nvidia_query_nvml.DeviceInfo{
+				{
+					// pre-Ampere, no row remapping
+					UUID:         "GPU-0",
+					MinorNumber:  0,
+					ECCErrors:    nvidia_query_nvml.ECCErrors{UUID: "GPU-0"},
+					RemappedRows: nvidia_query_nvml.RemappedRows{UUID: "GPU-0"},
+				},
+				{
+					UUID:        "GPU-1",
+					MinorNumber: 1,
+					ECCErrors:   nvidia_query_nvml.ECCErrors{UUID: "GPU-1"},
+					RemappedRows: nvidia_query_nvml.RemappedRows{
+						UUID:                             "GPU-1",
+						Supported:                        true,
+						RemappedDueToCorrectableErrors:   3,
+						RemappedDueToUncorrectableErrors: 7,
+						RemappingFailed:                  true,
+					},
+				},
+			},
+		},
+	}
+
+	o := ToOutput(in)
+	if len(o.PerGPU) != 2 {
+		t.Fatalf("expected 2 GPUs, got %d", len(o.PerGPU))
+	}
+	if o.PerGPU[0].RemappedRows != nil {
+		t.Fatalf("expected no remapped rows for the unsupported GPU, got %+v", o.PerGPU[0].RemappedRows)
+	}
+	if r := o.PerGPU[1].RemappedRows; r == nil || !r.QualifiesForRMA() || r.RequiresReset() {
+		t.Fatalf("unexpected remapped rows: %+v", r)
+	}
+
+	states, err := o.States()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if states[0].Healthy || states[0].ReasonCode != components.ReasonThresholdExceeded {
+		t.Fatalf("expected unhealthy summary state on the remapping failure, got %+v", states[0])
+	}
+	if !strings.Contains(states[0].Reason, "row remapping failure: [GPU-1]") {
+		t.Fatalf("unexpected summary reason: %q", states[0].Reason)
+	}
+
+	gpu0, gpu1 := states[1], states[2]
+	if _, ok := gpu0.ExtraInfo[StateKeyECCErrorsGPURemappingFailed]; ok {
+		t.Fatalf("unexpected remapping keys for the unsupported GPU: %+v", gpu0.ExtraInfo)
+	}
+	if gpu1.Healthy {
+		t.Fatalf("expected unhealthy gpu 1 state, got %+v", gpu1)
+	}
+	if gpu1.ExtraInfo[StateKeyECCErrorsGPURemappedDueToCorrectable] != "3" ||
+		gpu1.ExtraInfo[StateKeyECCErrorsGPURemappedDueToUncorrectable] != "7" ||
+		gpu1.ExtraInfo[StateKeyECCErrorsGPURemappingPending] != "false" ||
+		gpu1.ExtraInfo[StateKeyECCErrorsGPURemappingFailed] != "true" {
+		t.Fatalf("unexpected gpu 1 extra info: %+v", gpu1.ExtraInfo)
+	}
+	if !strings.Contains(gpu1.Reason, "qualifies for RMA") {
+		t.Fatalf("unexpected gpu 1 reason: %q", gpu1.Reason)
+	}
+
+	// round trip through the states
+	parsed, err := ParseStatesToOutput(states...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := parsed.PerGPU[1].RemappedRows; r == nil || r.RemappedDueToUncorrectableErrors != 7 || !r.RemappingFailed {
+		t.Fatalf("unexpected parsed remapped rows: %+v", r)
+	}
+}
+
+func TestParseStatesToOutputRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	in := &nvidia_query.Output{
+		SMI: &nvidia_query.SMIOutput{
+			GPUs: []nvidia_query.NvidiaSMIGPU{
+				{
+					ID: "GPU-0",
+					ECCErrors: &nvidia_query.SMIECCErrors{
+						ID:        "GPU-0",
+						Aggregate: &nvidia_query.SMIECCErrorAggregate{DRAMCorrectable: "4", DRAMUncorrectable: "0"},
+						Volatile:  &nvidia_query.SMIECCErrorVolatile{DRAMCorrectable: "1", DRAMUncorrectable: "2"},
+					},
+				},
+			},
+		},
+		NVML: &nvidia_query_nvml.Output{
+			DeviceInfos: []*nvidia_query_nvml.DeviceInfo{
+				{
+					UUID:        "GPU-1",
+					MinorNumber: 1,
+					ECCErrors: nvidia_query_nvml.ECCErrors{
+						UUID:      "GPU-1",
+						Aggregate: nvidia_query_nvml.AllECCErrorCounts{Total: nvidia_query_nvml.ECCErrorCounts{Corrected: 5, Uncorrected: 3}},
+						Volatile:  nvidia_query_nvml.AllECCErrorCounts{Total: nvidia_query_nvml.ECCErrorCounts{Corrected: 2, Uncorrected: 1}},
+					},
+					RemappedRows: nvidia_query_nvml.RemappedRows{
+						UUID:                             "GPU-1",
+						Supported:                        true,
+						RemappedDueToUncorrectableErrors: 2,
+						RemappingPending:                 true,
+					},
+				},
+				{
+					UUID:        "GPU-0",
+					MinorNumber: 0,
+					ECCErrors: nvidia_query_nvml.ECCErrors{
+						UUID:      "GPU-0",
+						Aggregate: nvidia_query_nvml.AllECCErrorCounts{Total: nvidia_query_nvml.ECCErrorCounts{Corrected: 4}},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		output *Output
+	}{
+		{name: "empty", output: ToOutput(&nvidia_query.Output{})},
+		{name: "smi and nvml", output: ToOutput(in)},
+		{
+			name: "with reset detected",
+			output: func() *Output {
+				o := ToOutput(in)
+				o.SetLastResetDetected([]GPUReset{{Time: time.Date(2024, time.July, 9, 18, 14, 7, 123456789, time.UTC), After: o.PerGPU[1]}})
+				return o
+			}(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			states, err := tt.output.States()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the order of the states does not matter
+			reversed := make([]components.State, 0, len(states))
+			for i := len(states) - 1; i >= 0; i-- {
+				reversed = append(reversed, states[i])
+			}
+			for _, ss := range [][]components.State{states, reversed} {
+				parsed, err := ParseStatesToOutput(ss...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				// the JSON decoded time is in the local time zone
+				for i := range parsed.PerGPU {
+					if r := parsed.PerGPU[i].LastResetDetected; r != nil {
+						r.Time = r.Time.UTC()
+					}
+				}
+				if !reflect.DeepEqual(parsed, tt.output) {
+					t.Fatalf("round trip mismatch\nexpected: %+v\ngot:      %+v", tt.output, parsed)
+				}
+			}
+		})
+	}
+
+	if _, err := ParseStatesToOutput(); err == nil {
+		t.Fatal("expected error for no states")
+	}
+	if _, err := ParseStatesToOutput(components.State{Name: StateNameECCErrors, ExtraInfo: map[string]string{
+		StateKeyECCErrorsData:     "{}",
+		StateKeyECCErrorsEncoding: "gzip",
+	}}); err == nil {
+		t.Fatal("expected error for the unknown encoding")
+	}
+}
@@ -0,0 +1,49 @@
+package ecc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/components/query"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type blockingPoller struct {
+	query.Poller
+	blockc chan struct{}
+}
+
+func (p *blockingPoller) Last() (*query.Item, error) {
+	<-p.blockc
+	return nil, nil
+}
+
+func TestComponentStatesTimeout(t *testing.T) {
+	t.Parallel()
+
+	blockc := make(chan struct{})
+	defer close(blockc)
+
+	c := &component{poller: &blockingPoller{blockc: blockc}}
+	c.cfg.Query.StatesTimeout = metav1.Duration{Duration: 100 * time.Millisecond}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, ctx := range []context.Context{ctx, context.Background()} {
+		start := time.Now()
+		states, err := c.States(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Fatalf("expected prompt return, took %v", elapsed)
+		}
+		if len(states) != 1 || states[0].Healthy || states[0].ReasonCode != components.ReasonTimeout {
+			t.Fatalf("unexpected states: %+v", states)
+		}
+	}
+}
@@ -5,10 +5,22 @@ import (
 	"encoding/json"
 
 	query_config "github.com/leptonai/gpud/components/query/config"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type Config struct {
 	Query query_config.Config `json:"query"`
+
+	// Maximum duration to look back when reading the metrics,
+	// in order to avoid scanning the whole metrics table with a very old "since".
+	// Zero means no limit.
+	MaxLookback metav1.Duration `json:"max_lookback,omitempty"`
+
+	// Emits an event when the volatile or aggregate uncorrected error count
+	// of a GPU reaches this threshold.
+	// Zero disables the events.
+	UncorrectedThreshold int `json:"uncorrected_threshold,omitempty"`
 }
 
 func ParseConfig(b any, db *sql.DB) (*Config, error) {
@@ -15,13 +15,15 @@ import (
 
 const Name = "accelerator-nvidia-error-sxid"
 
-func New() components.Component {
-	return &component{}
+func New(cfg Config) components.Component {
+	return &component{cfg: cfg}
 }
 
 var _ components.Component = (*component)(nil)
 
-type component struct{}
+type component struct {
+	cfg Config
+}
 
 func (c *component) Name() string { return Name }
 
@@ -56,7 +58,7 @@ func (c *component) States(ctx context.Context) ([]components.State, error) {
 			continue
 		}
 
-		ev, err := nvidia_query_sxid.ParseDmesgLogLine(logItem.Line)
+		ev, err := nvidia_query_sxid.ParseDmesgLogLineWithTopology(logItem.Line, c.cfg.Topology)
 		if err != nil {
 			return nil, err
 		}
@@ -96,7 +98,7 @@ func (c *component) Events(ctx context.Context, since time.Time) ([]components.E
 			continue
 		}
 
-		ev, err := nvidia_query_sxid.ParseDmesgLogLine(logItem.Line)
+		ev, err := nvidia_query_sxid.ParseDmesgLogLineWithTopology(logItem.Line, c.cfg.Topology)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,32 @@
+package sxid
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	nvidia_query_sxid "github.com/leptonai/gpud/components/accelerator/nvidia/query/sxid"
+)
+
+type Config struct {
+	// Topology is the platform topology hint to classify the NVSwitch ports
+	// that the SXids occurred on (trunk or access), to select the impact.
+	// The port type is "unknown" if not configured.
+	Topology *nvidia_query_sxid.Topology `json:"topology,omitempty"`
+}
+
+func ParseConfig(b any, db *sql.DB) (*Config, error) {
+	raw, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	cfg := new(Config)
+	err = json.Unmarshal(raw, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (cfg Config) Validate() error {
+	return cfg.Topology.Validate()
+}
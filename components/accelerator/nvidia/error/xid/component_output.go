@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/leptonai/gpud/components"
 	nvidia_query_nvml "github.com/leptonai/gpud/components/accelerator/nvidia/query/nvml"
@@ -196,7 +197,9 @@ func getDefaultPoller() query.Poller {
 // the query.GetFunc is already called periodically in a loop by the poller
 func CreateGet() query.GetFunc {
 	return func(ctx context.Context) (_ any, e error) {
+		start := time.Now()
 		defer func() {
+			components_metrics.SetGetLatency(Name, time.Since(start))
 			if e != nil {
 				components_metrics.SetGetFailed(Name)
 			} else {
@@ -4,58 +4,109 @@ package fabricmanager
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/leptonai/gpud/components"
 	nvidia_query "github.com/leptonai/gpud/components/accelerator/nvidia/query"
 	fabric_manager_log "github.com/leptonai/gpud/components/accelerator/nvidia/query/fabric-manager-log"
+	"github.com/leptonai/gpud/components/accelerator/nvidia/query/sxid"
 	"github.com/leptonai/gpud/components/query"
 	query_log "github.com/leptonai/gpud/components/query/log"
 	"github.com/leptonai/gpud/log"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 const Name = "accelerator-nvidia-fabric-manager"
 
+const Description = "Tracks the NVIDIA fabric manager status and the NVSwitch errors."
+
+var tags = []string{"accelerator", "gpu", "nvidia", "fabric-manager", "nvswitch"}
+
 func New(ctx context.Context, cfg Config) (components.Component, error) {
 	cfg.Query.SetDefaultsIfNotSet()
 
 	cctx, ccancel := context.WithCancel(ctx)
 	nvidia_query.DefaultPoller.Start(cctx, cfg.Query, Name)
 
-	if err := cfg.Log.Validate(); err != nil {
+	if err := cfg.Validate(); err != nil {
 		ccancel()
 		return nil, err
 	}
 	cfg.Log.SetDefaultsIfNotSet()
 
-	if err := fabric_manager_log.CreateDefaultPoller(ctx, cfg.Log); err != nil {
+	// already validated
+	minSeverity := sxid.SeverityInfo
+	if cfg.MinSeverity != "" {
+		minSeverity, _ = sxid.ParseSeverity(cfg.MinSeverity)
+	}
+
+	// count each line once as read, the states and events
+	// query the same lines multiple times
+	thermal := newThermalTracker()
+	sxidMetrics := newSXidMetrics(thermal)
+	cfg.Log.LineObserver = sxidMetrics.observeLine
+
+	if err := fabric_manager_log.CreateDefaultPoller(ctx, cfg.Log, nil); err != nil {
 		ccancel()
 		return nil, err
 	}
 	fabric_manager_log.GetDefaultPoller().Start(cctx, cfg.Query, Name)
 
 	return &component{
-		rootCtx:   ctx,
-		cancel:    ccancel,
-		poller:    nvidia_query.DefaultPoller,
-		logPoller: fabric_manager_log.GetDefaultPoller(),
+		cfg:         cfg,
+		minSeverity: minSeverity,
+		rootCtx:     ctx,
+		cancel:      ccancel,
+		poller:      nvidia_query.DefaultPoller,
+		logPoller:   fabric_manager_log.GetDefaultPoller(),
+		thermal:     thermal,
+		sxidMetrics: sxidMetrics,
 	}, nil
 }
 
 var _ components.Component = (*component)(nil)
 
 type component struct {
-	rootCtx   context.Context
-	cancel    context.CancelFunc
-	poller    query.Poller
-	logPoller query_log.Poller
+	cfg         Config
+	minSeverity sxid.Severity
+	rootCtx     context.Context
+	cancel      context.CancelFunc
+	poller      query.Poller
+	logPoller   query_log.Poller
+	thermal     *thermalTracker
+	sxidMetrics *sxidMetrics
 }
 
 func (c *component) Name() string { return Name }
 
-func (c *component) States(ctx context.Context) ([]components.State, error) {
+func (c *component) Describe() components.ComponentInfo {
+	return components.ComponentInfo{
+		Name:        Name,
+		Description: Description,
+		Tags:        append([]string(nil), tags...),
+	}
+}
+
+var _ components.Readier = (*component)(nil)
+
+// Returns true if the poller has produced at least one result.
+func (c *component) Ready() bool {
+	if c.poller == nil {
+		return false
+	}
 	last, err := c.poller.Last()
+	return err == nil && last != nil
+}
+
+func (c *component) States(ctx context.Context) ([]components.State, error) {
+	last, err := components.FetchWithTimeout(ctx, c.cfg.Query.StatesTimeout.Duration, c.poller.Last)
+	if errors.Is(err, components.ErrFetchTimeout) {
+		return []components.State{components.TimeoutState(Name, err)}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -66,17 +117,19 @@ func (c *component) States(ctx context.Context) ([]components.State, error) {
 	if last.Error != nil {
 		return []components.State{
 			{
-				Healthy: false,
-				Error:   last.Error.Error(),
-				Reason:  "last query failed",
+				Healthy:    false,
+				Error:      last.Error.Error(),
+				Reason:     "last query failed",
+				ReasonCode: components.ReasonQueryFailed,
 			},
 		}, nil
 	}
 	if last.Output == nil {
 		return []components.State{
 			{
-				Healthy: false,
-				Reason:  "no output",
+				Healthy:    false,
+				Reason:     "no output",
+				ReasonCode: components.ReasonNoOutput,
 			},
 		}, nil
 	}
@@ -98,10 +151,11 @@ func (c *component) States(ctx context.Context) ([]components.State, error) {
 		cs := make([]components.State, 0)
 		for _, e := range allOutput.FabricManagerErrors {
 			cs = append(cs, components.State{
-				Name:    Name,
-				Healthy: false,
-				Error:   e,
-				Reason:  "fabric manager query failed with " + e,
+				Name:       Name,
+				Healthy:    false,
+				Error:      e,
+				Reason:     "fabric manager query failed with " + e,
+				ReasonCode: components.ReasonQueryFailed,
 				ExtraInfo: map[string]string{
 					nvidia_query.StateKeyFabricManagerExists: fmt.Sprintf("%v", allOutput.FabricManagerExists),
 				},
@@ -110,7 +164,29 @@ func (c *component) States(ctx context.Context) ([]components.State, error) {
 		return cs, nil
 	}
 	output := ToOutput(allOutput)
-	return output.States()
+	output.skipDriverVersionCheck = c.cfg.SkipDriverVersionCheck
+	states, err := output.States()
+	if err != nil {
+		return nil, err
+	}
+
+	// catch up with the log items not yet seen via Events
+	items, err := c.logPoller.Find(c.thermal.since())
+	if err != nil {
+		return nil, err
+	}
+	c.thermal.update(items)
+	states = append(states, c.thermal.state())
+
+	window := c.cfg.SXidStatusWindow.Duration
+	if window <= 0 {
+		window = DefaultSXidStatusWindow
+	}
+	items, err = c.logPoller.Find(time.Now().Add(-window))
+	if err != nil {
+		return nil, err
+	}
+	return append(states, sxidState(items)), nil
 }
 
 const (
@@ -118,6 +194,8 @@ const (
 	EventKeyFabricManagerNVSwitchLogLine        = "fabricmanager_nvswitch_log_line"
 	EventKeyFabricManagerNVSwitchLogFilter      = "fabricmanager_nvswitch_log_filter"
 	EventKeyFabricManagerNVSwitchLogError       = "fabricmanager_nvswitch_log_error"
+	EventKeyFabricManagerNVSwitchSXidName       = "fabricmanager_nvswitch_sxid_name"
+	EventKeyFabricManagerNVSwitchSXidSeverity   = "fabricmanager_nvswitch_sxid_severity"
 )
 
 func (c *component) Events(ctx context.Context, since time.Time) ([]components.Event, error) {
@@ -125,25 +203,12 @@ func (c *component) Events(ctx context.Context, since time.Time) ([]components.E
 	if err != nil {
 		return nil, err
 	}
+	c.thermal.update(items)
+
+	evs := createEvents(items, c.minSeverity)
+	evs = dedupEvents(evs, c.cfg.Log.DedupWindow.Duration)
+	evs = components.CapEvents(evs, c.cfg.MaxEvents)
 
-	evs := make([]components.Event, 0)
-	for _, ev := range items {
-		b, _ := ev.Matched.JSON()
-		es := ""
-		if ev.Error != nil {
-			es = ev.Error.Error()
-		}
-		evs = append(evs, components.Event{
-			Time: ev.Time,
-			Name: Name,
-			ExtraInfo: map[string]string{
-				EventKeyFabricManagerNVSwitchLogUnixSeconds: fmt.Sprintf("%d", ev.Time.Unix()),
-				EventKeyFabricManagerNVSwitchLogLine:        ev.Line,
-				EventKeyFabricManagerNVSwitchLogFilter:      string(b),
-				EventKeyFabricManagerNVSwitchLogError:       es,
-			},
-		})
-	}
 	if len(evs) == 0 {
 		return nil, nil
 	}
@@ -156,6 +221,15 @@ func (c *component) Metrics(ctx context.Context, since time.Time) ([]components.
 	return nil, nil
 }
 
+var _ components.PromRegisterer = (*component)(nil)
+
+func (c *component) RegisterCollectors(reg *prometheus.Registry, db *sql.DB, tableName string) error {
+	if c.sxidMetrics == nil {
+		c.sxidMetrics = newSXidMetrics(c.thermal)
+	}
+	return c.sxidMetrics.register(reg)
+}
+
 func (c *component) Close() error {
 	log.Logger.Debugw("closing component")
 
@@ -165,3 +239,17 @@ func (c *component) Close() error {
 
 	return nil
 }
+
+var _ components.Drainer = (*component)(nil)
+
+// Stops the new polls and waits for the in-flight polls to finish.
+func (c *component) Drain(ctx context.Context) error {
+	var errs []error
+	if c.poller != nil {
+		errs = append(errs, c.poller.Drain(ctx))
+	}
+	if c.logPoller != nil {
+		errs = append(errs, c.logPoller.Drain(ctx))
+	}
+	return errors.Join(errs...)
+}
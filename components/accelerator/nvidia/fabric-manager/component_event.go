@@ -0,0 +1,185 @@
+package fabricmanager
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/leptonai/gpud/components"
+	fabric_manager_log "github.com/leptonai/gpud/components/accelerator/nvidia/query/fabric-manager-log"
+	"github.com/leptonai/gpud/components/accelerator/nvidia/query/sxid"
+	query_log "github.com/leptonai/gpud/components/query/log"
+)
+
+const (
+	// Number of the identical events collapsed into this event
+	// (only set when the de-duplication is enabled).
+	EventKeyFabricManagerNVSwitchLogOccurrences = "fabricmanager_nvswitch_log_occurrences"
+)
+
+const (
+	// The structured fields of the "detected NVSwitch error" log line
+	// (only set when the line is an NVSwitch error).
+	EventKeyFabricManagerNVSwitchSXidCode       = "fabricmanager_nvswitch_sxid_code"
+	EventKeyFabricManagerNVSwitchSXidFatal      = "fabricmanager_nvswitch_sxid_fatal"
+	EventKeyFabricManagerNVSwitchSXidFID        = "fabricmanager_nvswitch_sxid_fid"
+	EventKeyFabricManagerNVSwitchSXidPCIBusID   = "fabricmanager_nvswitch_sxid_pci_bus_id"
+	EventKeyFabricManagerNVSwitchSXidPhysicalID = "fabricmanager_nvswitch_sxid_physical_id"
+	EventKeyFabricManagerNVSwitchSXidPort       = "fabricmanager_nvswitch_sxid_port"
+)
+
+// Returns the events from the matched fabric manager log items,
+// with the resolved SXid detail name and severity.
+// The events below the minimum severity are dropped.
+func createEvents(items []query_log.Item, minSeverity sxid.Severity) []components.Event {
+	evs := make([]components.Event, 0)
+	for _, item := range items {
+		ev, _, ok := createEvent(item, minSeverity)
+		if !ok {
+			continue
+		}
+		evs = append(evs, ev)
+	}
+	return evs
+}
+
+// Returns the event from the matched fabric manager log item,
+// and the resolved SXid detail (nil if the line has no known SXid).
+// The severity of the unknown SXid is from the fatal flag of the log line
+// (see severityOfNVSwitchError).
+// Returns false if the event is below the minimum severity.
+func createEvent(item query_log.Item, minSeverity sxid.Severity) (components.Event, *sxid.Detail, bool) {
+	b, _ := item.Matched.JSON()
+	es := ""
+	if item.Error != nil {
+		es = item.Error.Error()
+	}
+
+	var detail *sxid.Detail
+	severity := sxid.SeverityInfo
+	nvswitchErr, isNVSwitchErr := fabric_manager_log.ParseNVSwitchError(item.Line)
+	if isNVSwitchErr {
+		if d, found := sxid.GetDetail(nvswitchErr.Code); found {
+			detail = d
+		}
+		// same as the states, so the unknown fatal SXid is not dropped
+		severity = severityOfNVSwitchError(nvswitchErr)
+	}
+	if severity < minSeverity {
+		return components.Event{}, nil, false
+	}
+	name := ""
+	if detail != nil {
+		name = detail.Name
+	}
+
+	extraInfo := map[string]string{
+		EventKeyFabricManagerNVSwitchLogUnixSeconds: fmt.Sprintf("%d", item.Time.Unix()),
+		EventKeyFabricManagerNVSwitchLogLine:        item.Line,
+		EventKeyFabricManagerNVSwitchLogFilter:      string(b),
+		EventKeyFabricManagerNVSwitchLogError:       es,
+		EventKeyFabricManagerNVSwitchSXidName:       name,
+		EventKeyFabricManagerNVSwitchSXidSeverity:   severity.String(),
+	}
+	if isNVSwitchErr {
+		setNVSwitchErrorExtraInfo(extraInfo, nvswitchErr)
+	}
+
+	return components.Event{
+		Time:      item.Time,
+		Name:      Name,
+		ExtraInfo: extraInfo,
+	}, detail, true
+}
+
+// Sets the structured NVSwitch error fields into the event extra info,
+// skipping the ones not present in the log line.
+func setNVSwitchErrorExtraInfo(extraInfo map[string]string, e fabric_manager_log.NVSwitchError) {
+	extraInfo[EventKeyFabricManagerNVSwitchSXidCode] = strconv.Itoa(e.Code)
+	extraInfo[EventKeyFabricManagerNVSwitchSXidFatal] = strconv.FormatBool(e.Fatal)
+	if e.FID >= 0 {
+		extraInfo[EventKeyFabricManagerNVSwitchSXidFID] = strconv.Itoa(e.FID)
+	}
+	if e.PCIBusID != "" {
+		extraInfo[EventKeyFabricManagerNVSwitchSXidPCIBusID] = e.PCIBusID
+	}
+	if e.PhysicalID >= 0 {
+		extraInfo[EventKeyFabricManagerNVSwitchSXidPhysicalID] = strconv.Itoa(e.PhysicalID)
+	}
+	if e.Port >= 0 {
+		extraInfo[EventKeyFabricManagerNVSwitchSXidPort] = strconv.Itoa(e.Port)
+	}
+}
+
+// sxidSignature identifies the identical SXid events.
+type sxidSignature struct {
+	code int
+	link string
+	pci  string
+}
+
+// Returns the SXid signature of the fabric manager log line
+// (e.g., "detected NVSwitch non-fatal error 12028 on fid 0 on NVSwitch pci bus id 00000000:86:00.0 physical id 3 port 61").
+// Returns false if the line has no SXid code.
+func parseSXidSignature(line string) (sxidSignature, bool) {
+	e, ok := fabric_manager_log.ParseNVSwitchError(line)
+	if !ok {
+		return sxidSignature{}, false
+	}
+
+	sig := sxidSignature{code: e.Code, pci: e.PCIBusID}
+	if e.Port >= 0 {
+		sig.link = strconv.Itoa(e.Port)
+	}
+	return sig, true
+}
+
+// Collapses the identical SXid events (same code, link, and PCI bus ID)
+// that repeat within the window into the first event, with the occurrence
+// count in its extra info. The events must be sorted in chronological order.
+// Events without an SXid code are returned as is.
+func dedupEvents(evs []components.Event, window time.Duration) []components.Event {
+	if window <= 0 || len(evs) == 0 {
+		return evs
+	}
+
+	type lastSeen struct {
+		pci   string
+		idx   int
+		count int
+		time  time.Time
+	}
+	// keyed by the SXid code and link
+	seen := make(map[string]*lastSeen)
+
+	deduped := make([]components.Event, 0, len(evs))
+	for _, ev := range evs {
+		sig, ok := parseSXidSignature(ev.ExtraInfo[EventKeyFabricManagerNVSwitchLogLine])
+		if !ok {
+			deduped = append(deduped, ev)
+			continue
+		}
+
+		key := fmt.Sprintf("%d/%s", sig.code, sig.link)
+		prev, found := seen[key]
+		if found && prev.pci == sig.pci && ev.Time.Sub(prev.time) <= window {
+			prev.count++
+			prev.time = ev.Time.Time
+			deduped[prev.idx].ExtraInfo[EventKeyFabricManagerNVSwitchLogOccurrences] = strconv.Itoa(prev.count)
+			continue
+		}
+
+		if ev.ExtraInfo == nil {
+			ev.ExtraInfo = make(map[string]string)
+		}
+		ev.ExtraInfo[EventKeyFabricManagerNVSwitchLogOccurrences] = "1"
+		seen[key] = &lastSeen{
+			pci:   sig.pci,
+			idx:   len(deduped),
+			count: 1,
+			time:  ev.Time.Time,
+		}
+		deduped = append(deduped, ev)
+	}
+	return deduped
+}
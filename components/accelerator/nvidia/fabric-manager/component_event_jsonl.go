@@ -0,0 +1,75 @@
+package fabricmanager
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/components/accelerator/nvidia/query/sxid"
+	query_log "github.com/leptonai/gpud/components/query/log"
+)
+
+// EventLine is a single line of the JSON Lines events output.
+type EventLine struct {
+	components.Event
+
+	// The resolved SXid detail, nil if the log line has no known SXid.
+	SXid *sxid.Detail `json:"sxid,omitempty"`
+}
+
+// Writes the events since the given time as JSON Lines (newline-delimited JSON),
+// one event per line, flushing the writer after each line if it supports flushing
+// (e.g., bufio.Writer, http.ResponseWriter).
+// Useful to pipe the events into the log pipelines (e.g., Fluent Bit, Vector).
+//
+// Unlike Events, the events are written as they are created without buffering,
+// so the identical SXid events are not de-duplicated and the max events cap is not applied.
+// Returns the number of the events written.
+func (c *component) WriteEventsJSONLines(ctx context.Context, w io.Writer, since time.Time) (int, error) {
+	items, err := c.logPoller.Find(since)
+	if err != nil {
+		return 0, err
+	}
+	c.thermal.update(items)
+
+	return writeEventsJSONLines(ctx, w, items, c.minSeverity)
+}
+
+func writeEventsJSONLines(ctx context.Context, w io.Writer, items []query_log.Item, minSeverity sxid.Severity) (int, error) {
+	// the encoder terminates each value with a newline
+	enc := json.NewEncoder(w)
+
+	written := 0
+	for _, item := range items {
+		if err := ctx.Err(); err != nil {
+			return written, err
+		}
+
+		ev, detail, ok := createEvent(item, minSeverity)
+		if !ok {
+			continue
+		}
+		if err := enc.Encode(EventLine{Event: ev, SXid: detail}); err != nil {
+			return written, err
+		}
+		written++
+
+		if err := flush(w); err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+func flush(w io.Writer) error {
+	switch f := w.(type) {
+	case interface{ Flush() error }:
+		return f.Flush()
+	case http.Flusher:
+		f.Flush()
+	}
+	return nil
+}
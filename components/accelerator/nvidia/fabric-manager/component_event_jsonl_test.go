@@ -0,0 +1,98 @@
+package fabricmanager
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/leptonai/gpud/components/accelerator/nvidia/query/sxid"
+	query_log "github.com/leptonai/gpud/components/query/log"
+)
+
+type flushCountingWriter struct {
+	bytes.Buffer
+	flushes int
+}
+
+func (w *flushCountingWriter) Flush() error {
+	w.flushes++
+	return nil
+}
+
+func TestWriteEventsJSONLines(t *testing.T) {
+	now := metav1.NewTime(time.Date(2024, time.July, 9, 18, 14, 7, 0, time.UTC))
+	items := []query_log.Item{
+		{Time: now, Line: "detected NVSwitch non-fatal error 12028 on fid 0 on NVSwitch pci bus id 00000000:86:00.0 physical id 3 port 61"},
+		{Time: now, Line: "detected NVSwitch fatal error 20034 on fid 0 on NVSwitch pci bus id 00000000:86:00.0 physical id 3 port 61"},
+		{Time: now, Line: "fabric manager started"},
+	}
+
+	w := &flushCountingWriter{}
+	n, err := writeEventsJSONLines(context.Background(), w, items, sxid.SeverityInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Fatalf("expected 3 events written, got %d", n)
+	}
+	if w.flushes != 3 {
+		t.Fatalf("expected 3 flushes, got %d", w.flushes)
+	}
+
+	lines := 0
+	scanner := bufio.NewScanner(bytes.NewReader(w.Bytes()))
+	for scanner.Scan() {
+		if !json.Valid(scanner.Bytes()) {
+			t.Fatalf("line %d is not valid JSON: %q", lines, scanner.Text())
+		}
+
+		var line EventLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatal(err)
+		}
+		if !line.Time.Equal(&now) {
+			t.Errorf("line %d: expected time %v, got %v", lines, now, line.Time)
+		}
+		switch lines {
+		case 0, 1:
+			if line.SXid == nil {
+				t.Fatalf("line %d: expected sxid detail", lines)
+			}
+			if line.SXid.Name != line.ExtraInfo[EventKeyFabricManagerNVSwitchSXidName] {
+				t.Errorf("line %d: expected sxid name %q, got %q", lines, line.ExtraInfo[EventKeyFabricManagerNVSwitchSXidName], line.SXid.Name)
+			}
+		case 2:
+			if line.SXid != nil {
+				t.Errorf("line %d: unexpected sxid detail %+v", lines, line.SXid)
+			}
+		}
+		lines++
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if lines != 3 {
+		t.Fatalf("expected 3 lines, got %d", lines)
+	}
+
+	// below the minimum severity
+	w = &flushCountingWriter{}
+	n, err = writeEventsJSONLines(context.Background(), w, items, sxid.SeverityPotentialFatal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 || bytes.Count(w.Bytes(), []byte("\n")) != 1 {
+		t.Fatalf("expected 1 line, got %d (%q)", n, w.String())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := writeEventsJSONLines(ctx, &flushCountingWriter{}, items, sxid.SeverityInfo); err == nil {
+		t.Fatal("expected error for the canceled context")
+	}
+}
@@ -0,0 +1,149 @@
+package fabricmanager
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/components/accelerator/nvidia/query/sxid"
+	query_log "github.com/leptonai/gpud/components/query/log"
+)
+
+func TestParseSXidSignature(t *testing.T) {
+	sig, ok := parseSXidSignature("[Jul 09 2024 18:14:07] [ERROR] [tid 12727] detected NVSwitch non-fatal error 12028 on fid 0 on NVSwitch pci bus id 00000000:86:00.0 physical id 3 port 61")
+	if !ok {
+		t.Fatal("expected signature")
+	}
+	if sig.code != 12028 || sig.link != "61" || sig.pci != "00000000:86:00.0" {
+		t.Fatalf("unexpected signature %+v", sig)
+	}
+
+	if _, ok := parseSXidSignature("[Jul 09 2024 18:14:07] [INFO] [tid 12727] fabric manager started"); ok {
+		t.Fatal("unexpected signature")
+	}
+}
+
+func TestDedupEvents(t *testing.T) {
+	base := time.Date(2024, time.July, 9, 18, 14, 7, 0, time.UTC)
+	newEvent := func(d time.Duration, line string) components.Event {
+		return components.Event{
+			Time:      metav1.NewTime(base.Add(d)),
+			Name:      Name,
+			ExtraInfo: map[string]string{EventKeyFabricManagerNVSwitchLogLine: line},
+		}
+	}
+	const (
+		port61 = "detected NVSwitch non-fatal error 12028 on fid 0 on NVSwitch pci bus id 00000000:86:00.0 physical id 3 port 61"
+		port62 = "detected NVSwitch non-fatal error 12028 on fid 0 on NVSwitch pci bus id 00000000:86:00.0 physical id 3 port 62"
+		noSXid = "fabric manager started"
+	)
+
+	evs := []components.Event{
+		newEvent(0, port61),
+		newEvent(time.Second, port61),
+		newEvent(2*time.Second, port62),
+		newEvent(3*time.Second, noSXid),
+		newEvent(4*time.Second, port61),
+		newEvent(time.Minute, port61),
+	}
+
+	if got := dedupEvents(evs, 0); len(got) != len(evs) {
+		t.Fatalf("expected no de-duplication when disabled, got %d events", len(got))
+	}
+
+	got := dedupEvents(evs, 10*time.Second)
+	if len(got) != 4 {
+		t.Fatalf("expected 4 events, got %d", len(got))
+	}
+	wantCounts := []string{"3", "1", "", "1"}
+	for i, want := range wantCounts {
+		if c := got[i].ExtraInfo[EventKeyFabricManagerNVSwitchLogOccurrences]; c != want {
+			t.Errorf("event %d: expected occurrences %q, got %q", i, want, c)
+		}
+	}
+}
+
+func TestCreateEventsMinSeverity(t *testing.T) {
+	now := metav1.NewTime(time.Date(2024, time.July, 9, 18, 14, 7, 0, time.UTC))
+	items := []query_log.Item{
+		{Time: now, Line: "detected NVSwitch non-fatal error 12028 on fid 0 on NVSwitch pci bus id 00000000:86:00.0 physical id 3 port 61"},
+		{Time: now, Line: "detected NVSwitch fatal error 20034 on fid 0 on NVSwitch pci bus id 00000000:86:00.0 physical id 3 port 61"},
+		{Time: now, Line: "fabric manager started"},
+	}
+
+	evs := createEvents(items, sxid.SeverityInfo)
+	if len(evs) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(evs))
+	}
+	if evs[0].ExtraInfo[EventKeyFabricManagerNVSwitchSXidName] != "egress nonposted PRIV error" {
+		t.Errorf("unexpected name %q", evs[0].ExtraInfo[EventKeyFabricManagerNVSwitchSXidName])
+	}
+	if evs[0].ExtraInfo[EventKeyFabricManagerNVSwitchSXidSeverity] != sxid.SeverityNonFatal.String() {
+		t.Errorf("unexpected severity %q", evs[0].ExtraInfo[EventKeyFabricManagerNVSwitchSXidSeverity])
+	}
+	if evs[2].ExtraInfo[EventKeyFabricManagerNVSwitchSXidSeverity] != sxid.SeverityInfo.String() {
+		t.Errorf("unexpected severity %q", evs[2].ExtraInfo[EventKeyFabricManagerNVSwitchSXidSeverity])
+	}
+
+	// the structured fields of the nvswitch error line
+	wantExtraInfo := map[string]string{
+		EventKeyFabricManagerNVSwitchSXidCode:       "20034",
+		EventKeyFabricManagerNVSwitchSXidFatal:      "true",
+		EventKeyFabricManagerNVSwitchSXidFID:        "0",
+		EventKeyFabricManagerNVSwitchSXidPCIBusID:   "00000000:86:00.0",
+		EventKeyFabricManagerNVSwitchSXidPhysicalID: "3",
+		EventKeyFabricManagerNVSwitchSXidPort:       "61",
+	}
+	for k, want := range wantExtraInfo {
+		if got := evs[1].ExtraInfo[k]; got != want {
+			t.Errorf("unexpected %s %q, want %q", k, got, want)
+		}
+	}
+	if _, ok := evs[2].ExtraInfo[EventKeyFabricManagerNVSwitchSXidCode]; ok {
+		t.Errorf("unexpected sxid code for the non-nvswitch error line")
+	}
+
+	evs = createEvents(items, sxid.SeverityPotentialFatal)
+	if len(evs) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(evs))
+	}
+	if evs[0].ExtraInfo[EventKeyFabricManagerNVSwitchSXidName] != "LTSSM Fault Up" {
+		t.Errorf("unexpected name %q", evs[0].ExtraInfo[EventKeyFabricManagerNVSwitchSXidName])
+	}
+}
+
+func TestCreateEventsUnknownSXid(t *testing.T) {
+	now := metav1.NewTime(time.Date(2024, time.July, 9, 18, 14, 7, 0, time.UTC))
+	items := []query_log.Item{
+		{Time: now, Line: "detected NVSwitch fatal error 99999 on fid 0 on NVSwitch pci bus id 00000000:86:00.0 physical id 3 port 61"},
+		{Time: now, Line: "detected NVSwitch non-fatal error 99998 on fid 0 on NVSwitch pci bus id 00000000:86:00.0 physical id 3 port 61"},
+	}
+	if _, found := sxid.GetDetail(99999); found {
+		t.Fatal("expected the sxid 99999 not in the catalog")
+	}
+
+	// the unknown fatal error is not dropped, as in the states
+	evs := createEvents(items, sxid.SeverityFatal)
+	if len(evs) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(evs))
+	}
+	if evs[0].ExtraInfo[EventKeyFabricManagerNVSwitchSXidCode] != "99999" {
+		t.Errorf("unexpected code %q", evs[0].ExtraInfo[EventKeyFabricManagerNVSwitchSXidCode])
+	}
+	if evs[0].ExtraInfo[EventKeyFabricManagerNVSwitchSXidSeverity] != sxid.SeverityFatal.String() {
+		t.Errorf("unexpected severity %q", evs[0].ExtraInfo[EventKeyFabricManagerNVSwitchSXidSeverity])
+	}
+	if evs[0].ExtraInfo[EventKeyFabricManagerNVSwitchSXidName] != "" {
+		t.Errorf("unexpected name %q", evs[0].ExtraInfo[EventKeyFabricManagerNVSwitchSXidName])
+	}
+
+	evs = createEvents(items, sxid.SeverityInfo)
+	if len(evs) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(evs))
+	}
+	if evs[1].ExtraInfo[EventKeyFabricManagerNVSwitchSXidSeverity] != sxid.SeverityNonFatal.String() {
+		t.Errorf("unexpected severity %q", evs[1].ExtraInfo[EventKeyFabricManagerNVSwitchSXidSeverity])
+	}
+}
@@ -0,0 +1,62 @@
+package fabricmanager
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	fabric_manager_log "github.com/leptonai/gpud/components/accelerator/nvidia/query/fabric-manager-log"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// sxidMetrics counts the NVSwitch errors in the fabric manager log lines,
+// and reports the number of the NVSwitches in the thermal event.
+// Each line is counted once as the log poller reads it (see observeLine),
+// so the counts do not depend on how often the states or events are queried.
+type sxidMetrics struct {
+	total     *prometheus.CounterVec
+	throttled prometheus.GaugeFunc
+}
+
+func newSXidMetrics(thermal *thermalTracker) *sxidMetrics {
+	return &sxidMetrics{
+		total: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "gpud",
+				Subsystem: "nvswitch",
+				Name:      "sxid_total",
+				Help:      "tracks the total number of the NVSwitch SXid errors in the fabric manager log",
+			},
+			[]string{"code", "severity"},
+		),
+		throttled: prometheus.NewGaugeFunc(
+			prometheus.GaugeOpts{
+				Namespace: "gpud",
+				Subsystem: "nvswitch",
+				Name:      "thermal_throttled",
+				Help:      "tracks the current number of the NVSwitches in the thermal event",
+			},
+			func() float64 {
+				return float64(len(thermal.throttled()))
+			},
+		),
+	}
+}
+
+func (m *sxidMetrics) register(reg *prometheus.Registry) error {
+	return errors.Join(reg.Register(m.total), reg.Register(m.throttled))
+}
+
+// Counts the NVSwitch error in the log line, if any.
+// Called once for each line read by the log poller.
+func (m *sxidMetrics) observeLine(line string, _ time.Time) {
+	if m == nil {
+		return
+	}
+	e, ok := fabric_manager_log.ParseNVSwitchError(line)
+	if !ok {
+		return
+	}
+	m.total.WithLabelValues(strconv.Itoa(e.Code), severityOfNVSwitchError(e).String()).Inc()
+}
@@ -0,0 +1,97 @@
+package fabricmanager
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	query_log "github.com/leptonai/gpud/components/query/log"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestSXidMetrics(t *testing.T) {
+	t.Parallel()
+
+	base := time.Date(2024, time.July, 9, 18, 14, 7, 0, time.UTC)
+	newItem := func(d time.Duration, line string) query_log.Item {
+		return query_log.Item{Time: metav1.NewTime(base.Add(d)), Line: line}
+	}
+	const (
+		nonFatal     = "detected NVSwitch non-fatal error 12028 on fid 0 on NVSwitch pci bus id 00000000:86:00.0 physical id 3 port 61"
+		fatal        = "detected NVSwitch fatal error 20034 on fid 0 on NVSwitch pci bus id 00000000:86:00.0 physical id 3 port 61"
+		thermalStart = "detected NVSwitch non-fatal error 10004 on fid 0 on NVSwitch pci bus id 00000000:87:00.0 physical id 4 port 0"
+		noSXid       = "fabric manager started"
+	)
+
+	thermal := newThermalTracker()
+	m := newSXidMetrics(thermal)
+	reg := prometheus.NewRegistry()
+	if err := m.register(reg); err != nil {
+		t.Fatal(err)
+	}
+
+	// the log timestamps have the one-second resolution,
+	// so the lines in the same second are all counted
+	items := []query_log.Item{
+		newItem(0, noSXid),
+		newItem(time.Second, nonFatal),
+		newItem(time.Second, nonFatal),
+		newItem(time.Second, fatal),
+		newItem(2*time.Second, thermalStart),
+		newItem(2*time.Second, fatal),
+	}
+	thermal.update(items)
+	for _, item := range items {
+		m.observeLine(item.Line, item.Time.Time)
+	}
+
+	totals, throttled := gatherSXidMetrics(t, reg)
+	want := map[string]float64{
+		"12028/non-fatal":       2,
+		"20034/potential-fatal": 2,
+		"10004/potential-fatal": 1,
+	}
+	if len(totals) != len(want) {
+		t.Fatalf("expected %d series, got %v", len(want), totals)
+	}
+	for k, v := range want {
+		if totals[k] != v {
+			t.Errorf("expected %s total %v, got %v", k, v, totals[k])
+		}
+	}
+	if throttled != 1 {
+		t.Errorf("expected 1 throttled nvswitch, got %v", throttled)
+	}
+
+	// nil metrics (e.g., not created) is a no-op
+	var nilMetrics *sxidMetrics
+	nilMetrics.observeLine(fatal, base)
+}
+
+// Returns the SXid totals keyed by "code/severity" and the throttled gauge.
+func gatherSXidMetrics(t *testing.T, reg *prometheus.Registry) (map[string]float64, float64) {
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	totals := make(map[string]float64)
+	throttled := -1.0
+	for _, mf := range mfs {
+		switch mf.GetName() {
+		case "gpud_nvswitch_sxid_total":
+			for _, metric := range mf.GetMetric() {
+				labels := make(map[string]string)
+				for _, l := range metric.GetLabel() {
+					labels[l.GetName()] = l.GetValue()
+				}
+				totals[labels["code"]+"/"+labels["severity"]] = metric.GetCounter().GetValue()
+			}
+		case "gpud_nvswitch_thermal_throttled":
+			throttled = mf.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+	return totals, throttled
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/leptonai/gpud/components"
 	nvidia_query "github.com/leptonai/gpud/components/accelerator/nvidia/query"
@@ -13,11 +14,18 @@ func ToOutput(i *nvidia_query.Output) *Output {
 	o := &Output{
 		FabricManager: *i.FabricManager,
 	}
+	if i.SMI != nil {
+		o.DriverVersion = i.SMI.DriverVersion
+	}
 	return o
 }
 
 type Output struct {
 	FabricManager nvidia_query.FabricManagerOutput `json:"fabric_manager"`
+	DriverVersion string                           `json:"driver_version,omitempty"`
+
+	// set true to not evaluate the driver version mismatch
+	skipDriverVersionCheck bool
 }
 
 func (o *Output) JSON() ([]byte, error) {
@@ -39,16 +47,15 @@ const (
 	StateKeyFabricManagerEncoding       = "encoding"
 	StateValueFabricManagerEncodingJSON = "json"
 
+	StateKeyFabricManagerVersion = "fabric_manager_version"
+	StateKeyDriverVersion        = "driver_version"
+
 	// TODO: support compressed gzip
 )
 
 func ParseStateFabricManager(m map[string]string) (*Output, error) {
-	o := &Output{}
 	data := m[StateKeyFabricManagerData]
-	if err := json.Unmarshal([]byte(data), &o.FabricManager); err != nil {
-		return nil, err
-	}
-	return o, nil
+	return ParseOutputJSON([]byte(data))
 }
 
 func ParseStatesToOutput(states ...components.State) (*Output, error) {
@@ -61,6 +68,10 @@ func ParseStatesToOutput(states ...components.State) (*Output, error) {
 			}
 			return o, nil
 
+		case StateNameNVSwitchThermal, StateNameNVSwitchSXid:
+			// derived from the fabric manager logs, not the output
+			continue
+
 		default:
 			return nil, fmt.Errorf("unknown state name: %s", state.Name)
 		}
@@ -70,6 +81,18 @@ func ParseStatesToOutput(states ...components.State) (*Output, error) {
 
 // Returns the output evaluation reason and its healthy-ness.
 func (o *Output) Evaluate() (string, bool, error) {
+	if !o.skipDriverVersionCheck {
+		fmMajor, driverMajor := majorVersion(o.FabricManager.Version), majorVersion(o.DriverVersion)
+		if fmMajor != "" && driverMajor != "" && fmMajor != driverMajor {
+			return fmt.Sprintf("fabric-manager version %q does not match driver version %q (major version %s != %s)",
+				o.FabricManager.Version,
+				o.DriverVersion,
+				fmMajor,
+				driverMajor,
+			), false, nil
+		}
+	}
+
 	if o.FabricManager.Active {
 		return "fabric-manager active", true, nil
 	}
@@ -89,7 +112,25 @@ func (o *Output) States() ([]components.State, error) {
 		ExtraInfo: map[string]string{
 			StateKeyFabricManagerData:     string(b),
 			StateKeyFabricManagerEncoding: StateValueFabricManagerEncodingJSON,
+			StateKeyFabricManagerVersion:  o.FabricManager.Version,
+			StateKeyDriverVersion:         o.DriverVersion,
 		},
 	}
+	state.Status = components.StatusHealthy
+	if !healthy {
+		// the only unhealthy evaluation is the version mismatch
+		state.Status = components.StatusUnhealthy
+		state.ReasonCode = components.ReasonVersionMismatch
+	}
 	return []components.State{state}, nil
 }
+
+// Returns the major version of the version string
+// (e.g., "535" for "535.161.08"), or empty if unknown.
+func majorVersion(v string) string {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return ""
+	}
+	return strings.SplitN(v, ".", 2)[0]
+}
@@ -0,0 +1,65 @@
+package fabricmanager
+
+import (
+	"testing"
+
+	"github.com/leptonai/gpud/components"
+	nvidia_query "github.com/leptonai/gpud/components/accelerator/nvidia/query"
+)
+
+func TestOutputDriverVersionMismatch(t *testing.T) {
+	tests := []struct {
+		name          string
+		fmVersion     string
+		driverVersion string
+		skip          bool
+		wantHealthy   bool
+	}{
+		{name: "match", fmVersion: "535.161.08", driverVersion: "535.161.08", wantHealthy: true},
+		{name: "minor mismatch", fmVersion: "535.161.08", driverVersion: "535.129.03", wantHealthy: true},
+		{name: "major mismatch", fmVersion: "535.161.08", driverVersion: "550.54.15", wantHealthy: false},
+		{name: "major mismatch skipped", fmVersion: "535.161.08", driverVersion: "550.54.15", skip: true, wantHealthy: true},
+		{name: "unknown driver version", fmVersion: "535.161.08", driverVersion: "", wantHealthy: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := ToOutput(&nvidia_query.Output{
+				FabricManager: &nvidia_query.FabricManagerOutput{Version: tt.fmVersion, Active: true},
+				SMI:           &nvidia_query.SMIOutput{DriverVersion: tt.driverVersion},
+			})
+			o.skipDriverVersionCheck = tt.skip
+
+			states, err := o.States()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(states) != 1 {
+				t.Fatalf("expected 1 state, got %d", len(states))
+			}
+			if states[0].Healthy != tt.wantHealthy {
+				t.Fatalf("expected healthy %v, got %v (%s)", tt.wantHealthy, states[0].Healthy, states[0].Reason)
+			}
+			wantCode := components.ReasonCode("")
+			if !tt.wantHealthy {
+				wantCode = components.ReasonVersionMismatch
+			}
+			if states[0].ReasonCode != wantCode {
+				t.Fatalf("expected reason code %q, got %q", wantCode, states[0].ReasonCode)
+			}
+			if states[0].ExtraInfo[StateKeyFabricManagerVersion] != tt.fmVersion {
+				t.Fatalf("unexpected fabric manager version %q", states[0].ExtraInfo[StateKeyFabricManagerVersion])
+			}
+			if states[0].ExtraInfo[StateKeyDriverVersion] != tt.driverVersion {
+				t.Fatalf("unexpected driver version %q", states[0].ExtraInfo[StateKeyDriverVersion])
+			}
+
+			parsed, err := ParseStatesToOutput(states...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if parsed.FabricManager.Version != tt.fmVersion || parsed.DriverVersion != tt.driverVersion {
+				t.Fatalf("unexpected parsed output %+v", parsed)
+			}
+		})
+	}
+}
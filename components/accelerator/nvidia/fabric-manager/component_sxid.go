@@ -0,0 +1,92 @@
+package fabricmanager
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/leptonai/gpud/components"
+	fabric_manager_log "github.com/leptonai/gpud/components/accelerator/nvidia/query/fabric-manager-log"
+	"github.com/leptonai/gpud/components/accelerator/nvidia/query/sxid"
+	query_log "github.com/leptonai/gpud/components/query/log"
+)
+
+const (
+	StateNameNVSwitchSXid = "nvswitch_sxid"
+
+	// The most severe SXid severity within the status window (e.g., "non-fatal").
+	StateKeyNVSwitchSXidSeverity = "sxid_severity"
+	// The SXid code of the most severe error.
+	StateKeyNVSwitchSXidCode = "sxid_code"
+	// The number of the NVSwitch errors within the status window.
+	StateKeyNVSwitchSXidCount = "sxid_count"
+)
+
+// DefaultSXidStatusWindow is the default window of the NVSwitch errors
+// to evaluate the SXid state status.
+const DefaultSXidStatusWindow = time.Hour
+
+// Returns the status of the SXid severity, where the non-fatal errors
+// are degraded, and the (potential) fatal errors are unhealthy.
+func statusOfSXidSeverity(sev sxid.Severity) components.Status {
+	switch sev {
+	case sxid.SeverityFatal, sxid.SeverityPotentialFatal:
+		return components.StatusUnhealthy
+	case sxid.SeverityNonFatal:
+		return components.StatusDegraded
+	default:
+		return components.StatusHealthy
+	}
+}
+
+// Returns the severity of the NVSwitch error from the SXid catalog,
+// or from the fatal flag of the log line if the SXid is unknown.
+func severityOfNVSwitchError(e fabric_manager_log.NVSwitchError) sxid.Severity {
+	if d, ok := sxid.GetDetail(e.Code); ok {
+		return d.Severity()
+	}
+	if e.Fatal {
+		return sxid.SeverityFatal
+	}
+	return sxid.SeverityNonFatal
+}
+
+// Returns the state of the NVSwitch errors in the log items,
+// evaluated by the most severe error.
+// The thermal events are excluded, as tracked by the thermal state.
+func sxidState(items []query_log.Item) components.State {
+	count := 0
+	worst := sxid.SeverityInfo
+	worstCode := 0
+	for _, item := range items {
+		e, ok := fabric_manager_log.ParseNVSwitchError(item.Line)
+		if !ok || e.Code == SXidThermalEventStart || e.Code == SXidThermalEventEnd {
+			continue
+		}
+		count++
+		if sev := severityOfNVSwitchError(e); sev > worst || worstCode == 0 {
+			worst = sev
+			worstCode = e.Code
+		}
+	}
+
+	status := statusOfSXidSeverity(worst)
+	state := components.State{
+		Name:    StateNameNVSwitchSXid,
+		Healthy: status != components.StatusUnhealthy,
+		Status:  status,
+		Reason:  "no nvswitch error found",
+		ExtraInfo: map[string]string{
+			StateKeyNVSwitchSXidCount: strconv.Itoa(count),
+		},
+	}
+	if count > 0 {
+		state.Reason = fmt.Sprintf("%d nvswitch error(s) found, most severe SXid %d (%s)", count, worstCode, worst)
+		state.ExtraInfo[StateKeyNVSwitchSXidSeverity] = worst.String()
+		state.ExtraInfo[StateKeyNVSwitchSXidCode] = strconv.Itoa(worstCode)
+	}
+	if !state.Healthy {
+		state.ReasonCode = components.ReasonThresholdExceeded
+	}
+	return state
+}
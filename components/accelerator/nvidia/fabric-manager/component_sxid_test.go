@@ -0,0 +1,66 @@
+package fabricmanager
+
+import (
+	"testing"
+
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/components/accelerator/nvidia/query/sxid"
+	query_log "github.com/leptonai/gpud/components/query/log"
+)
+
+func TestSXidState(t *testing.T) {
+	t.Parallel()
+
+	nonFatal := query_log.Item{Line: "detected NVSwitch non-fatal error 12028 on fid 0 on NVSwitch pci bus id 00000000:86:00.0 physical id 3 port 61"}
+	fatal := query_log.Item{Line: "detected NVSwitch fatal error 20034 on fid 0 on NVSwitch pci bus id 00000000:86:00.0 physical id 3 port 30"}
+	thermal := query_log.Item{Line: "detected NVSwitch non-fatal error 10004 on fid 0 on NVSwitch pci bus id 00000000:86:00.0 physical id 3"}
+	other := query_log.Item{Line: "fabric manager started"}
+
+	tests := []struct {
+		name         string
+		items        []query_log.Item
+		wantStatus   components.Status
+		wantSeverity string
+		wantCode     string
+		wantCount    string
+	}{
+		{
+			name:       "no errors",
+			items:      []query_log.Item{other, thermal},
+			wantStatus: components.StatusHealthy,
+			wantCount:  "0",
+		},
+		{
+			name:         "non-fatal errors",
+			items:        []query_log.Item{nonFatal, other, nonFatal},
+			wantStatus:   components.StatusDegraded,
+			wantSeverity: sxid.SeverityNonFatal.String(),
+			wantCode:     "12028",
+			wantCount:    "2",
+		},
+		{
+			name:         "fatal errors",
+			items:        []query_log.Item{nonFatal, fatal, nonFatal},
+			wantStatus:   components.StatusUnhealthy,
+			wantSeverity: sxid.SeverityPotentialFatal.String(),
+			wantCode:     "20034",
+			wantCount:    "3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := sxidState(tt.items)
+			if s.Name != StateNameNVSwitchSXid || s.Status != tt.wantStatus {
+				t.Fatalf("expected status %q, got %+v", tt.wantStatus, s)
+			}
+			if s.Healthy != (tt.wantStatus != components.StatusUnhealthy) {
+				t.Fatalf("unexpected healthy %v for status %q", s.Healthy, s.Status)
+			}
+			if s.ExtraInfo[StateKeyNVSwitchSXidSeverity] != tt.wantSeverity ||
+				s.ExtraInfo[StateKeyNVSwitchSXidCode] != tt.wantCode ||
+				s.ExtraInfo[StateKeyNVSwitchSXidCount] != tt.wantCount {
+				t.Fatalf("unexpected extra info %+v", s.ExtraInfo)
+			}
+		})
+	}
+}
@@ -8,6 +8,8 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/components/query"
 	query_config "github.com/leptonai/gpud/components/query/config"
 	query_log_config "github.com/leptonai/gpud/components/query/log/config"
 )
@@ -75,3 +77,68 @@ func TestComponentLog(t *testing.T) {
 		t.Errorf("expected 2 events, got %d", len(events))
 	}
 }
+
+type blockingPoller struct {
+	query.Poller
+	blockc chan struct{}
+}
+
+func (p *blockingPoller) Last() (*query.Item, error) {
+	<-p.blockc
+	return nil, nil
+}
+
+func TestComponentStatesTimeout(t *testing.T) {
+	t.Parallel()
+
+	blockc := make(chan struct{})
+	defer close(blockc)
+
+	c := &component{poller: &blockingPoller{blockc: blockc}}
+	c.cfg.Query.StatesTimeout = metav1.Duration{Duration: 100 * time.Millisecond}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, ctx := range []context.Context{ctx, context.Background()} {
+		start := time.Now()
+		states, err := c.States(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Fatalf("expected prompt return, took %v", elapsed)
+		}
+		if len(states) != 1 || states[0].Healthy || states[0].ReasonCode != components.ReasonTimeout {
+			t.Fatalf("unexpected states: %+v", states)
+		}
+	}
+}
+
+type lastPoller struct {
+	query.Poller
+	last *query.Item
+}
+
+func (p *lastPoller) Last() (*query.Item, error) {
+	return p.last, nil
+}
+
+func TestComponentReady(t *testing.T) {
+	t.Parallel()
+
+	p := &lastPoller{}
+	c := &component{poller: p}
+	if c.Ready() {
+		t.Fatal("expected not ready before the first poll")
+	}
+
+	// failed queries also count, as the poller has produced a result
+	p.last = &query.Item{Time: metav1.Now()}
+	if !c.Ready() {
+		t.Fatal("expected ready after the first poll")
+	}
+	if !components.Ready(c) {
+		t.Fatal("expected ready via components.Ready")
+	}
+}
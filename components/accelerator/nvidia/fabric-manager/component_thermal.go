@@ -0,0 +1,118 @@
+package fabricmanager
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/leptonai/gpud/components"
+	query_log "github.com/leptonai/gpud/components/query/log"
+)
+
+const (
+	// SXid of the NVSwitch thermal event start ("Host_thermal_event_start").
+	SXidThermalEventStart = 10004
+	// SXid of the NVSwitch thermal event end ("Host_thermal_event_end").
+	SXidThermalEventEnd = 10005
+
+	StateNameNVSwitchThermal = "nvswitch_thermal"
+
+	// Comma-separated PCI bus IDs of the NVSwitches in the thermal event.
+	StateKeyNVSwitchThermalThrottled = "thermal_throttled_nvswitches"
+)
+
+// thermalTracker tracks the NVSwitches between the thermal event
+// start and end SXids, keyed by the NVSwitch PCI bus ID.
+// Updates are idempotent, so the same log items can be applied
+// more than once (e.g., from both States and Events).
+type thermalTracker struct {
+	mu sync.RWMutex
+	// the last start and end event times per NVSwitch
+	starts map[string]time.Time
+	ends   map[string]time.Time
+	// the latest log item time applied
+	lastSeen time.Time
+}
+
+func newThermalTracker() *thermalTracker {
+	return &thermalTracker{
+		starts: make(map[string]time.Time),
+		ends:   make(map[string]time.Time),
+	}
+}
+
+// Applies the thermal event SXids in the log items.
+func (t *thermalTracker) update(items []query_log.Item) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, item := range items {
+		if item.Time.After(t.lastSeen) {
+			t.lastSeen = item.Time.Time
+		}
+
+		sig, ok := parseSXidSignature(item.Line)
+		if !ok {
+			continue
+		}
+		var m map[string]time.Time
+		switch sig.code {
+		case SXidThermalEventStart:
+			m = t.starts
+		case SXidThermalEventEnd:
+			m = t.ends
+		default:
+			continue
+		}
+		if item.Time.After(m[sig.pci]) {
+			m[sig.pci] = item.Time.Time
+		}
+	}
+}
+
+// Returns the latest log item time applied.
+func (t *thermalTracker) since() time.Time {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.lastSeen
+}
+
+// Returns the sorted PCI bus IDs of the NVSwitches whose last thermal event
+// start has not been followed by the matching end event.
+func (t *thermalTracker) throttled() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	pcis := make([]string, 0)
+	for pci, start := range t.starts {
+		if end, ok := t.ends[pci]; ok && !end.Before(start) {
+			continue
+		}
+		pcis = append(pcis, pci)
+	}
+	sort.Strings(pcis)
+	return pcis
+}
+
+// Returns the live thermal status of the NVSwitches,
+// unhealthy while any NVSwitch is in the thermal event.
+func (t *thermalTracker) state() components.State {
+	throttled := t.throttled()
+	state := components.State{
+		Name:    StateNameNVSwitchThermal,
+		Healthy: len(throttled) == 0,
+		Status:  components.StatusHealthy,
+		Reason:  "no nvswitch in thermal event",
+		ExtraInfo: map[string]string{
+			StateKeyNVSwitchThermalThrottled: strings.Join(throttled, ","),
+		},
+	}
+	if !state.Healthy {
+		state.Reason = fmt.Sprintf("%d nvswitch(es) in thermal event (SXid %d without %d): %s", len(throttled), SXidThermalEventStart, SXidThermalEventEnd, strings.Join(throttled, ", "))
+		state.Status = components.StatusUnhealthy
+		state.ReasonCode = components.ReasonThresholdExceeded
+	}
+	return state
+}
@@ -0,0 +1,71 @@
+package fabricmanager
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/leptonai/gpud/components"
+	query_log "github.com/leptonai/gpud/components/query/log"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestThermalTracker(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 7, 9, 18, 14, 7, 0, time.UTC)
+	newItem := func(d time.Duration, code int, pci string) query_log.Item {
+		return query_log.Item{
+			Time: metav1.Time{Time: now.Add(d)},
+			Line: "detected NVSwitch non-fatal error " + strconv.Itoa(code) + " on fid 0 on NVSwitch pci bus id " + pci + " physical id 3",
+		}
+	}
+
+	tr := newThermalTracker()
+	if s := tr.state(); !s.Healthy || s.Name != StateNameNVSwitchThermal {
+		t.Fatalf("expected healthy with no events, got %+v", s)
+	}
+
+	tr.update([]query_log.Item{
+		newItem(0, SXidThermalEventStart, "00000000:86:00.0"),
+		newItem(time.Second, SXidThermalEventStart, "00000000:87:00.0"),
+		newItem(2*time.Second, 12028, "00000000:88:00.0"),
+	})
+	s := tr.state()
+	if s.Healthy || s.ReasonCode != components.ReasonThresholdExceeded {
+		t.Fatalf("expected unhealthy, got %+v", s)
+	}
+	if got := s.ExtraInfo[StateKeyNVSwitchThermalThrottled]; got != "00000000:86:00.0,00000000:87:00.0" {
+		t.Fatalf("unexpected throttled nvswitches %q", got)
+	}
+	if !tr.since().Equal(now.Add(2 * time.Second)) {
+		t.Fatalf("unexpected last seen %v", tr.since())
+	}
+
+	// the matching end event clears only its nvswitch,
+	// and re-applying the same items is a no-op
+	tr.update([]query_log.Item{
+		newItem(time.Second, SXidThermalEventStart, "00000000:87:00.0"),
+		newItem(3*time.Second, SXidThermalEventEnd, "00000000:86:00.0"),
+	})
+	if got := tr.throttled(); len(got) != 1 || got[0] != "00000000:87:00.0" {
+		t.Fatalf("unexpected throttled nvswitches %v", got)
+	}
+
+	tr.update([]query_log.Item{newItem(4*time.Second, SXidThermalEventEnd, "00000000:87:00.0")})
+	if s := tr.state(); !s.Healthy || s.ExtraInfo[StateKeyNVSwitchThermalThrottled] != "" {
+		t.Fatalf("expected healthy after the end events, got %+v", s)
+	}
+
+	// a new start after the end puts the nvswitch back into the thermal event
+	tr.update([]query_log.Item{newItem(5*time.Second, SXidThermalEventStart, "00000000:86:00.0")})
+	if got := tr.throttled(); len(got) != 1 || got[0] != "00000000:86:00.0" {
+		t.Fatalf("unexpected throttled nvswitches %v", got)
+	}
+
+	// the thermal state is skipped when parsing the output
+	if _, err := ParseStatesToOutput(tr.state()); err == nil {
+		t.Fatal("expected no fabric manager state found")
+	}
+}
@@ -3,17 +3,41 @@ package fabricmanager
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
 
 	"k8s.io/utils/ptr"
 
+	"github.com/leptonai/gpud/components/accelerator/nvidia/query/sxid"
 	query_config "github.com/leptonai/gpud/components/query/config"
 	query_log_config "github.com/leptonai/gpud/components/query/log/config"
 	query_log_filter "github.com/leptonai/gpud/components/query/log/filter"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type Config struct {
 	Query query_config.Config     `json:"query"`
 	Log   query_log_config.Config `json:"log"`
+
+	// Set true to skip the check that the fabric manager major version
+	// matches the NVIDIA driver major version
+	// (e.g., air-gapped hosts with custom builds).
+	SkipDriverVersionCheck bool `json:"skip_driver_version_check,omitempty"`
+
+	// Only returns the events whose SXid severity is at or above this level
+	// (one of "info", "non-fatal", "potential-fatal", "fatal").
+	// Empty to return all events (default).
+	MinSeverity string `json:"min_severity,omitempty"`
+
+	// Maximum number of the most recent events returned by a single Events call
+	// (see components.CapEvents).
+	// Zero uses components.DefaultMaxEvents, and negative disables the cap.
+	MaxEvents int `json:"max_events,omitempty"`
+
+	// The window of the NVSwitch errors to evaluate the SXid state
+	// (non-fatal errors are degraded, fatal errors are unhealthy).
+	// Zero uses DefaultSXidStatusWindow.
+	SXidStatusWindow metav1.Duration `json:"sxid_status_window,omitempty"`
 }
 
 func ParseConfig(b any, db *sql.DB) (*Config, error) {
@@ -33,6 +57,14 @@ func ParseConfig(b any, db *sql.DB) (*Config, error) {
 }
 
 func (cfg Config) Validate() error {
+	if cfg.SXidStatusWindow.Duration < 0 {
+		return fmt.Errorf("invalid sxid status window: %v", cfg.SXidStatusWindow.Duration)
+	}
+	if cfg.MinSeverity != "" {
+		if _, err := sxid.ParseSeverity(cfg.MinSeverity); err != nil {
+			return err
+		}
+	}
 	return cfg.Log.Validate()
 }
 
@@ -12,11 +12,19 @@ import (
 func ToOutput(i *nvidia_query.Output) *Output {
 	var totalMem uint64
 	var totalMemHumanized string
-	parsed, err := i.SMI.GPUs[0].FBMemoryUsage.Parse()
-	if err == nil {
-		totalMem = parsed.TotalBytes
-		totalMemHumanized = parsed.TotalHumanized
-	} else if i.NVML != nil && len(i.NVML.DeviceInfos) > 0 {
+
+	// the per-GPU nvidia-smi fields may be empty
+	// (e.g., driver fields populated via NVML without nvidia-smi)
+	parsed := false
+	if len(i.SMI.GPUs) > 0 && i.SMI.GPUs[0].FBMemoryUsage != nil {
+		mem, err := i.SMI.GPUs[0].FBMemoryUsage.Parse()
+		if err == nil {
+			totalMem = mem.TotalBytes
+			totalMemHumanized = mem.TotalHumanized
+			parsed = true
+		}
+	}
+	if !parsed && i.NVML != nil && len(i.NVML.DeviceInfos) > 0 {
 		totalMem = i.NVML.DeviceInfos[0].Memory.TotalBytes
 		totalMemHumanized = humanize.Bytes(i.NVML.DeviceInfos[0].Memory.TotalBytes)
 	}
@@ -38,6 +46,9 @@ func ToOutput(i *nvidia_query.Output) *Output {
 		}
 		break
 	}
+	if o.Product.Name == "" && i.NVML != nil && len(i.NVML.DeviceInfos) > 0 {
+		o.Product.Name = i.NVML.DeviceInfos[0].Name
+	}
 	return o
 }
 
@@ -0,0 +1,92 @@
+package info
+
+import (
+	"context"
+	"testing"
+
+	"github.com/leptonai/gpud/components"
+	nvidia_query "github.com/leptonai/gpud/components/accelerator/nvidia/query"
+	"github.com/leptonai/gpud/components/accelerator/nvidia/query/nvml"
+	"github.com/leptonai/gpud/components/query"
+)
+
+type fakeNVMLInstance struct {
+	nvml.Instance
+}
+
+func (f *fakeNVMLInstance) NVMLExists() bool               { return true }
+func (f *fakeNVMLInstance) DriverVersion() (string, error) { return "535.183.01", nil }
+func (f *fakeNVMLInstance) CUDAVersion() (string, error)   { return "12.4", nil }
+
+type fakePoller struct {
+	query.Poller
+
+	output *nvidia_query.Output
+}
+
+func (f *fakePoller) Last() (*query.Item, error) {
+	return &query.Item{Output: f.output}, nil
+}
+
+func TestComponentStatesPreferNVML(t *testing.T) {
+	t.Parallel()
+
+	nvmlOutput := &nvml.Output{
+		DeviceInfos: []*nvml.DeviceInfo{
+			{UUID: "GPU-0", Name: "NVIDIA H100 80GB HBM3", Memory: nvml.Memory{TotalBytes: 85520809984}},
+			{UUID: "GPU-1", Name: "NVIDIA H100 80GB HBM3", Memory: nvml.Memory{TotalBytes: 85520809984}},
+		},
+	}
+	smi, err := nvidia_query.GetSMIOutputFromNVML(&fakeNVMLInstance{}, nvmlOutput)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// no nvidia-smi installed, all populated via NVML
+	c := &component{
+		rootCtx: context.Background(),
+		poller: &fakePoller{output: &nvidia_query.Output{
+			SMIExists: false,
+			SMI:       smi,
+			NVML:      nvmlOutput,
+		}},
+	}
+	states, err := c.States(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]components.State)
+	for _, s := range states {
+		byName[s.Name] = s
+	}
+
+	if v := byName[StateKeyDriver].ExtraInfo[StateKeyDriverVersion]; v != "535.183.01" {
+		t.Errorf("expected the driver version from nvml, got %q", v)
+	}
+	if v := byName[StateKeyCUDA].ExtraInfo[StateKeyCUDAVersion]; v != "12.4" {
+		t.Errorf("expected the cuda version from nvml, got %q", v)
+	}
+	if v := byName[StateKeyGPU].ExtraInfo[StateKeyGPUAttached]; v != "2" {
+		t.Errorf("expected 2 attached gpus, got %q", v)
+	}
+	if v := byName[StateKeyMemory].ExtraInfo[StateKeyMemoryTotalBytes]; v != "85520809984" {
+		t.Errorf("unexpected total memory %q", v)
+	}
+	if v := byName[StateKeyProduct].ExtraInfo[StateKeyProductName]; v != "NVIDIA H100 80GB HBM3" {
+		t.Errorf("unexpected product name %q", v)
+	}
+
+	// no device found via NVML, thus no per-GPU output
+	smi, err = nvidia_query.GetSMIOutputFromNVML(&fakeNVMLInstance{}, &nvml.Output{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.poller = &fakePoller{output: &nvidia_query.Output{SMI: smi, NVML: &nvml.Output{}}}
+	states, err = c.States(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(states) != 1 || states[0].ReasonCode != components.ReasonSMIMissing {
+		t.Fatalf("expected the nvidia-smi missing state, got %+v", states)
+	}
+}
@@ -13,9 +13,14 @@ func ToOutput(i *nvidia_query.Output) *Output {
 	o := &Output{
 		LsmodPeermem: *i.LsmodPeermem,
 	}
-	if len(i.SMI.GPUs) > 0 {
+	if i.SMI != nil && len(i.SMI.GPUs) > 0 {
 		o.ProductName = i.SMI.GPUs[0].ProductName
 	}
+	// the per-GPU nvidia-smi fields may be empty
+	// (e.g., driver fields populated via NVML without nvidia-smi)
+	if o.ProductName == "" && i.NVML != nil && len(i.NVML.DeviceInfos) > 0 {
+		o.ProductName = i.NVML.DeviceInfos[0].Name
+	}
 	return o
 }
 
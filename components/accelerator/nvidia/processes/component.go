@@ -73,6 +73,9 @@ func (c *component) States(ctx context.Context) ([]components.State, error) {
 	if !ok {
 		return nil, fmt.Errorf("invalid output type: %T", last.Output)
 	}
+	if allOutput.SMIMissing() {
+		return []components.State{allOutput.SMIMissingState(Name)}, nil
+	}
 	if allOutput.SMIExists && len(allOutput.SMIQueryErrors) > 0 {
 		cs := make([]components.State, 0)
 		for _, e := range allOutput.SMIQueryErrors {
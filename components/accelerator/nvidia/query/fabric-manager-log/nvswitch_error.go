@@ -0,0 +1,70 @@
+package fabricmanagerlog
+
+import (
+	"regexp"
+	"strconv"
+)
+
+var (
+	regexNVSwitchError           = regexp.MustCompile(`detected NVSwitch (non-fatal|fatal) error (\d+)`)
+	regexNVSwitchErrorFID        = regexp.MustCompile(`\bon fid (\d+)\b`)
+	regexNVSwitchErrorPCIBusID   = regexp.MustCompile(`\bpci bus id ([0-9a-fA-F:.]+)`)
+	regexNVSwitchErrorPhysicalID = regexp.MustCompile(`\bphysical id (\d+)\b`)
+	regexNVSwitchErrorPort       = regexp.MustCompile(`\bport (\d+)\b`)
+)
+
+// NVSwitchError is the structured NVSwitch error from the fabric manager log line.
+// The numeric fields are -1 if not present in the line.
+type NVSwitchError struct {
+	// Fatal is true for the "detected NVSwitch fatal error" lines.
+	Fatal bool `json:"fatal"`
+	// Code is the SXid error code.
+	Code int `json:"code"`
+	// FID is the fabric ID.
+	FID int `json:"fid"`
+	// PCIBusID is the PCI bus ID of the NVSwitch (e.g., "00000000:86:00.0").
+	PCIBusID string `json:"pci_bus_id,omitempty"`
+	// PhysicalID is the physical ID of the NVSwitch.
+	PhysicalID int `json:"physical_id"`
+	// Port is the NVLink port of the NVSwitch.
+	Port int `json:"port"`
+}
+
+// Parses the NVSwitch error from the fabric manager log line
+// (e.g., "detected NVSwitch non-fatal error 12028 on fid 0 on NVSwitch pci bus id 00000000:86:00.0 physical id 3 port 61").
+// Returns false if the line is not an NVSwitch error.
+func ParseNVSwitchError(line string) (NVSwitchError, bool) {
+	m := regexNVSwitchError.FindStringSubmatch(line)
+	if len(m) < 3 {
+		return NVSwitchError{}, false
+	}
+	code, err := strconv.Atoi(m[2])
+	if err != nil {
+		return NVSwitchError{}, false
+	}
+
+	e := NVSwitchError{
+		Fatal:      m[1] == "fatal",
+		Code:       code,
+		FID:        findInt(regexNVSwitchErrorFID, line),
+		PhysicalID: findInt(regexNVSwitchErrorPhysicalID, line),
+		Port:       findInt(regexNVSwitchErrorPort, line),
+	}
+	if m := regexNVSwitchErrorPCIBusID.FindStringSubmatch(line); len(m) > 1 {
+		e.PCIBusID = m[1]
+	}
+	return e, true
+}
+
+// Returns the first integer submatch of the regex, or -1 if not found.
+func findInt(re *regexp.Regexp, line string) int {
+	m := re.FindStringSubmatch(line)
+	if len(m) < 2 {
+		return -1
+	}
+	v, err := strconv.Atoi(m[1])
+	if err != nil {
+		return -1
+	}
+	return v
+}
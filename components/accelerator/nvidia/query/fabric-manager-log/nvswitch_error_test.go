@@ -0,0 +1,52 @@
+package fabricmanagerlog
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseNVSwitchError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		line   string
+		want   NVSwitchError
+		wantOK bool
+	}{
+		{
+			name:   "non-fatal with all fields",
+			line:   "[Jul 09 2024 18:14:07] [ERROR] [tid 12727] detected NVSwitch non-fatal error 12028 on fid 0 on NVSwitch pci bus id 00000000:86:00.0 physical id 3 port 61",
+			want:   NVSwitchError{Fatal: false, Code: 12028, FID: 0, PCIBusID: "00000000:86:00.0", PhysicalID: 3, Port: 61},
+			wantOK: true,
+		},
+		{
+			name:   "fatal with all fields",
+			line:   "[Jul 23 2024 07:53:55] [ERROR] [tid 841] detected NVSwitch fatal error 20034 on fid 0 on NVSwitch pci bus id 00000000:88:00.0 physical id 1 port 0",
+			want:   NVSwitchError{Fatal: true, Code: 20034, FID: 0, PCIBusID: "00000000:88:00.0", PhysicalID: 1, Port: 0},
+			wantOK: true,
+		},
+		{
+			name:   "without the switch location",
+			line:   "detected NVSwitch non-fatal error 12028 on fid 2",
+			want:   NVSwitchError{Code: 12028, FID: 2, PhysicalID: -1, Port: -1},
+			wantOK: true,
+		},
+		{
+			name:   "not an nvswitch error",
+			line:   "[May 02 2024 18:41:23] [INFO] [tid 404868] Abort CUDA jobs when FM exits = 1",
+			wantOK: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseNVSwitchError(tt.line)
+			if ok != tt.wantOK {
+				t.Fatalf("ParseNVSwitchError() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ParseNVSwitchError() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
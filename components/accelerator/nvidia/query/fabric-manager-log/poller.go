@@ -17,14 +17,16 @@ var (
 	defaultLogPoller     query_log.Poller
 )
 
-func CreateDefaultPoller(ctx context.Context, cfg query_log_config.Config) error {
+// Creates the default fabric manager log poller once, with the custom line parser.
+// If the parser is nil, uses the default parser (see ExtractTimeFromLogLine).
+func CreateDefaultPoller(ctx context.Context, cfg query_log_config.Config, parser query_log.LineParser) error {
 	var err error
 	defaultLogPollerOnce.Do(func() {
-		defaultLogPoller, err = query_log.New(
-			ctx,
-			cfg,
-			ExtractTimeFromLogLine,
-		)
+		if parser != nil {
+			defaultLogPoller, err = query_log.NewWithLineParser(ctx, cfg, parser)
+		} else {
+			defaultLogPoller, err = query_log.New(ctx, cfg, ExtractTimeFromLogLine)
+		}
 		if err != nil {
 			panic(err)
 		}
@@ -38,6 +40,14 @@ func GetDefaultPoller() query_log.Poller {
 
 var regexForFabricmanagerLog = regexp.MustCompile(`^\[([^\]]+)\]`)
 
+// The timestamp layouts of the fabric manager logs, tried in order.
+var fabricmanagerLogTimeLayouts = []string{
+	// e.g., "[Jul 09 2024 18:14:07]"
+	"Jan 02 2006 15:04:05",
+	// e.g., "[2024-07-09 18:14:07]" (newer fabric manager builds)
+	"2006-01-02 15:04:05",
+}
+
 // does not return error for now
 // example log line: "[May 02 2024 18:41:23] [INFO] [tid 404868] Abort CUDA jobs when FM exits = 1"
 // TODO: once stable return error
@@ -49,10 +59,14 @@ func ExtractTimeFromLogLine(line []byte) (time.Time, error) {
 	}
 
 	s := matches[1]
-	timestamp, err := time.Parse("Jan 02 2006 15:04:05", s)
-	if err != nil {
-		log.Logger.Debugw("failed to parse timestamp", "line", string(line), "error", err)
-		return time.Time{}, nil
+	var err error
+	for _, layout := range fabricmanagerLogTimeLayouts {
+		var timestamp time.Time
+		timestamp, err = time.Parse(layout, s)
+		if err == nil {
+			return timestamp, nil
+		}
 	}
-	return timestamp, nil
+	log.Logger.Debugw("failed to parse timestamp", "line", string(line), "error", err)
+	return time.Time{}, nil
 }
@@ -27,10 +27,26 @@ func TestExtractTimeFromLogLine(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name: "unexpected log",
+			name: "iso log",
 			args: args{
 				line: []byte("[2024-07-09 18:14:07] [ERROR] [tid 12727] detected NVSwitch non-fatal error 12028 on fid 0 on NVSwitch pci bus id 00000000:86:00.0 physical id 3 port 61"),
 			},
+			want:    time.Date(2024, time.July, 9, 18, 14, 07, 0, time.UTC),
+			wantErr: false,
+		},
+		{
+			name: "unexpected log",
+			args: args{
+				line: []byte("[07/09/2024 18:14:07] [ERROR] [tid 12727] detected NVSwitch non-fatal error 12028 on fid 0 on NVSwitch pci bus id 00000000:86:00.0 physical id 3 port 61"),
+			},
+			want:    time.Time{},
+			wantErr: false,
+		},
+		{
+			name: "no timestamp",
+			args: args{
+				line: []byte("detected NVSwitch non-fatal error 12028 on fid 0"),
+			},
 			want:    time.Time{},
 			wantErr: false,
 		},
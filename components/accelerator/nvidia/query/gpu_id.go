@@ -0,0 +1,115 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// GPUIDs maps the GPU identifiers (index, UUID, or PCI bus ID)
+// to the canonical GPU UUID, as reported by NVML.
+type GPUIDs struct {
+	// lower-cased UUID to the canonical UUID
+	uuids map[string]string
+	// NVML index (not the minor number) to the UUID
+	byIndex map[int]string
+	// normalized PCI bus ID ("domain:bus:device") to the UUID
+	byBusID map[string]string
+}
+
+// Returns the GPU identifier mapping built from the NVML device info in the output.
+// Returns an empty mapping if the NVML output is not available,
+// where NormalizeToUUID only resolves the UUIDs as-is.
+func NewGPUIDs(o *Output) *GPUIDs {
+	ids := &GPUIDs{
+		uuids:   make(map[string]string),
+		byIndex: make(map[int]string),
+		byBusID: make(map[string]string),
+	}
+	if o == nil || o.NVML == nil {
+		return ids
+	}
+	for _, dev := range o.NVML.DeviceInfos {
+		if dev == nil || dev.UUID == "" {
+			continue
+		}
+		ids.uuids[strings.ToLower(dev.UUID)] = dev.UUID
+		ids.byIndex[dev.Index] = dev.UUID
+		ids.byBusID[formatPCIBusID(dev.Domain, dev.Bus, dev.Device)] = dev.UUID
+	}
+	return ids
+}
+
+// Returns the canonical UUID of the GPU identified by the index (e.g., "0"),
+// the UUID (e.g., "GPU-a1b2c3d4-..."), or the PCI bus ID
+// (e.g., "00000000:53:00.0", "0000:53:00.0", "PCI:0000:53:00", or "GPU 00000000:53:00.0").
+// Returns the UUID as-is if the mapping does not know the GPU.
+// Returns false if the identifier cannot be resolved to a UUID.
+func (ids *GPUIDs) NormalizeToUUID(id string) (string, bool) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return "", false
+	}
+
+	if isGPUUUID(id) {
+		if uuid, ok := ids.uuids[strings.ToLower(id)]; ok {
+			return uuid, true
+		}
+		return id, true
+	}
+
+	if idx, err := strconv.Atoi(id); err == nil {
+		uuid, ok := ids.byIndex[idx]
+		return uuid, ok
+	}
+
+	if busID, ok := normalizePCIBusID(id); ok {
+		uuid, ok := ids.byBusID[busID]
+		return uuid, ok
+	}
+	return "", false
+}
+
+func isGPUUUID(id string) bool {
+	lower := strings.ToLower(id)
+	return strings.HasPrefix(lower, "gpu-") || strings.HasPrefix(lower, "mig-")
+}
+
+// Returns the "domain:bus:device" in lower-case hex from the PCI bus ID,
+// ignoring the function. The domain defaults to zero if not set (e.g., "53:00.0").
+func normalizePCIBusID(id string) (string, bool) {
+	id = strings.ToLower(id)
+	id = strings.TrimPrefix(id, "gpu ")
+	id = strings.TrimPrefix(id, "pci:")
+
+	// drop the function
+	if i := strings.LastIndex(id, "."); i >= 0 {
+		id = id[:i]
+	}
+
+	parts := strings.Split(id, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return "", false
+	}
+	var domain uint64
+	if len(parts) == 3 {
+		var err error
+		domain, err = strconv.ParseUint(parts[0], 16, 32)
+		if err != nil {
+			return "", false
+		}
+	}
+	bus, err := strconv.ParseUint(parts[len(parts)-2], 16, 32)
+	if err != nil {
+		return "", false
+	}
+	device, err := strconv.ParseUint(parts[len(parts)-1], 16, 32)
+	if err != nil {
+		return "", false
+	}
+	return formatPCIBusID(uint32(domain), uint32(bus), uint32(device)), true
+}
+
+func formatPCIBusID(domain uint32, bus uint32, device uint32) string {
+	return fmt.Sprintf("%08x:%02x:%02x", domain, bus, device)
+}
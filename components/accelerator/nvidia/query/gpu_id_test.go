@@ -0,0 +1,73 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/leptonai/gpud/components/accelerator/nvidia/query/nvml"
+)
+
+func TestGPUIDsNormalizeToUUID(t *testing.T) {
+	t.Parallel()
+
+	ids := NewGPUIDs(&Output{
+		NVML: &nvml.Output{
+			DeviceInfos: []*nvml.DeviceInfo{
+				// the minor numbers differ from the NVML indexes
+				{UUID: "GPU-aaaa", Index: 0, MinorNumber: 1, Bus: 0x53, Device: 0},
+				{UUID: "GPU-bbbb", Index: 1, MinorNumber: 0, Bus: 0xc3, Device: 0},
+				// same bus and device in another PCI domain
+				{UUID: "GPU-cccc", Index: 2, MinorNumber: 2, Domain: 1, Bus: 0x53, Device: 0},
+			},
+		},
+	})
+
+	tests := []struct {
+		id       string
+		wantUUID string
+		wantOK   bool
+	}{
+		// index
+		{id: "0", wantUUID: "GPU-aaaa", wantOK: true},
+		{id: "1", wantUUID: "GPU-bbbb", wantOK: true},
+		{id: "2", wantUUID: "GPU-cccc", wantOK: true},
+		{id: "3", wantOK: false},
+
+		// UUID
+		{id: "GPU-bbbb", wantUUID: "GPU-bbbb", wantOK: true},
+		{id: "gpu-BBBB", wantUUID: "GPU-bbbb", wantOK: true},
+		{id: "GPU-unknown", wantUUID: "GPU-unknown", wantOK: true},
+
+		// PCI bus ID
+		{id: "00000000:53:00.0", wantUUID: "GPU-aaaa", wantOK: true},
+		{id: "0000:C3:00.0", wantUUID: "GPU-bbbb", wantOK: true},
+		{id: "PCI:0000:c3:00", wantUUID: "GPU-bbbb", wantOK: true},
+		{id: "GPU 00000000:53:00.0", wantUUID: "GPU-aaaa", wantOK: true},
+		{id: "00000001:53:00.0", wantUUID: "GPU-cccc", wantOK: true},
+		{id: "0001:53:00.0", wantUUID: "GPU-cccc", wantOK: true},
+		{id: "53:00.0", wantUUID: "GPU-aaaa", wantOK: true},
+		{id: "0000:99:00.0", wantOK: false},
+		{id: "zz:53:00.0", wantOK: false},
+
+		// invalid
+		{id: "", wantOK: false},
+		{id: "not-a-gpu", wantOK: false},
+	}
+	for _, tt := range tests {
+		uuid, ok := ids.NormalizeToUUID(tt.id)
+		if uuid != tt.wantUUID || ok != tt.wantOK {
+			t.Errorf("NormalizeToUUID(%q) = (%q, %v), want (%q, %v)", tt.id, uuid, ok, tt.wantUUID, tt.wantOK)
+		}
+	}
+}
+
+func TestGPUIDsWithoutNVML(t *testing.T) {
+	t.Parallel()
+
+	ids := NewGPUIDs(nil)
+	if uuid, ok := ids.NormalizeToUUID("GPU-aaaa"); !ok || uuid != "GPU-aaaa" {
+		t.Fatalf("expected the UUID as-is, got (%q, %v)", uuid, ok)
+	}
+	if _, ok := ids.NormalizeToUUID("0"); ok {
+		t.Fatal("expected the index to not resolve without NVML")
+	}
+}
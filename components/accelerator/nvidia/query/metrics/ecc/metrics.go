@@ -67,6 +67,43 @@ var (
 		[]string{"gpu_id"},
 	)
 	volatileTotalUncorrectedAverager = components_metrics.NewNoOpAverager()
+
+	remappedDueToCorrectable = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "remapped_rows_due_to_correctable_errors",
+			Help:      "tracks the current number of the rows remapped due to the correctable errors",
+		},
+		[]string{"gpu_id"},
+	)
+	remappedDueToUncorrectable = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "remapped_rows_due_to_uncorrectable_errors",
+			Help:      "tracks the current number of the rows remapped due to the uncorrectable errors",
+		},
+		[]string{"gpu_id"},
+	)
+	remappingPending = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "remapping_pending",
+			Help:      "set to 1 if the GPU has the rows pending remapping (requires the GPU reset)",
+		},
+		[]string{"gpu_id"},
+	)
+	remappingFailed = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "",
+			Subsystem: SubSystem,
+			Name:      "remapping_failed",
+			Help:      "set to 1 if the GPU row remapping failed (qualifies for the RMA)",
+		},
+		[]string{"gpu_id"},
+	)
 )
 
 func InitAveragers(db *sql.DB, tableName string) {
@@ -76,20 +113,22 @@ func InitAveragers(db *sql.DB, tableName string) {
 	volatileTotalUncorrectedAverager = components_metrics.NewAverager(db, tableName, SubSystem+"_volatile_total_uncorrected")
 }
 
-func ReadAggregateTotalCorrected(ctx context.Context, since time.Time) (components_metrics_state.Metrics, error) {
-	return aggregateTotalCorrectedAverager.Read(ctx, components_metrics.WithSince(since))
+// Reads the aggregate total corrected metrics since the given time.
+// Pass components_metrics.WithMetricSecondaryName to scope to a single GPU.
+func ReadAggregateTotalCorrected(ctx context.Context, since time.Time, opts ...components_metrics.OpOption) (components_metrics_state.Metrics, error) {
+	return aggregateTotalCorrectedAverager.Read(ctx, append([]components_metrics.OpOption{components_metrics.WithSince(since)}, opts...)...)
 }
 
-func ReadAggregateTotalUncorrected(ctx context.Context, since time.Time) (components_metrics_state.Metrics, error) {
-	return aggregateTotalUncorrectedAverager.Read(ctx, components_metrics.WithSince(since))
+func ReadAggregateTotalUncorrected(ctx context.Context, since time.Time, opts ...components_metrics.OpOption) (components_metrics_state.Metrics, error) {
+	return aggregateTotalUncorrectedAverager.Read(ctx, append([]components_metrics.OpOption{components_metrics.WithSince(since)}, opts...)...)
 }
 
-func ReadVolatileTotalCorrected(ctx context.Context, since time.Time) (components_metrics_state.Metrics, error) {
-	return volatileTotalCorrectedAverager.Read(ctx, components_metrics.WithSince(since))
+func ReadVolatileTotalCorrected(ctx context.Context, since time.Time, opts ...components_metrics.OpOption) (components_metrics_state.Metrics, error) {
+	return volatileTotalCorrectedAverager.Read(ctx, append([]components_metrics.OpOption{components_metrics.WithSince(since)}, opts...)...)
 }
 
-func ReadVolatileTotalUncorrected(ctx context.Context, since time.Time) (components_metrics_state.Metrics, error) {
-	return volatileTotalUncorrectedAverager.Read(ctx, components_metrics.WithSince(since))
+func ReadVolatileTotalUncorrected(ctx context.Context, since time.Time, opts ...components_metrics.OpOption) (components_metrics_state.Metrics, error) {
+	return volatileTotalUncorrectedAverager.Read(ctx, append([]components_metrics.OpOption{components_metrics.WithSince(since)}, opts...)...)
 }
 
 func SetLastUpdateUnixSeconds(unixSeconds float64) {
@@ -156,6 +195,21 @@ func SetVolatileTotalUncorrected(ctx context.Context, gpuID string, cnt float64,
 	return nil
 }
 
+// Sets the row remapping metrics of the GPU.
+func SetRemappedRows(gpuID string, dueToCorrectable int, dueToUncorrectable int, pending bool, failed bool) {
+	remappedDueToCorrectable.WithLabelValues(gpuID).Set(float64(dueToCorrectable))
+	remappedDueToUncorrectable.WithLabelValues(gpuID).Set(float64(dueToUncorrectable))
+	remappingPending.WithLabelValues(gpuID).Set(boolToFloat(pending))
+	remappingFailed.WithLabelValues(gpuID).Set(boolToFloat(failed))
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
 func Register(reg *prometheus.Registry, db *sql.DB, tableName string) error {
 	InitAveragers(db, tableName)
 
@@ -174,5 +228,17 @@ func Register(reg *prometheus.Registry, db *sql.DB, tableName string) error {
 	if err := reg.Register(volatileTotalUncorrected); err != nil {
 		return err
 	}
+	if err := reg.Register(remappedDueToCorrectable); err != nil {
+		return err
+	}
+	if err := reg.Register(remappedDueToUncorrectable); err != nil {
+		return err
+	}
+	if err := reg.Register(remappingPending); err != nil {
+		return err
+	}
+	if err := reg.Register(remappingFailed); err != nil {
+		return err
+	}
 	return nil
 }
@@ -34,6 +34,10 @@ type Instance interface {
 	GPMMetricsSupported() bool
 	RecvGPMEvents() <-chan *GPMEvent
 
+	// Returns the driver and CUDA versions, as reported by nvidia-smi.
+	DriverVersion() (string, error)
+	CUDAVersion() (string, error)
+
 	Shutdown() error
 	Get() (*Output, error)
 }
@@ -77,8 +81,13 @@ type DeviceInfo struct {
 	// TODO: implement MIG device UUID fetching using NVML.
 	UUID string `json:"uuid"`
 
-	// MinorNumber is the minor number of the device.
+	// Index is the NVML index of the device (same as the nvidia-smi GPU index),
+	// in the PCI bus enumeration order, and may differ from the minor number.
+	Index int `json:"index"`
+	// MinorNumber is the minor number of the device (e.g., "/dev/nvidia0").
 	MinorNumber int `json:"minor_number"`
+	// Domain is the PCI domain from PCI info API.
+	Domain uint32 `json:"domain"`
 	// Bus is the bus ID from PCI info API.
 	Bus uint32 `json:"bus"`
 	// Device ID is the device ID from PCI info API.
@@ -102,6 +111,8 @@ type DeviceInfo struct {
 	Utilization Utilization `json:"utilization"`
 	Processes   Processes   `json:"processes"`
 	ECCErrors   ECCErrors   `json:"ecc_errors"`
+	// RemappedRows is the row remapping data (only supported on Ampere or newer GPUs).
+	RemappedRows RemappedRows `json:"remapped_rows"`
 
 	device device.Device `json:"-"`
 }
@@ -198,6 +209,11 @@ func (inst *instance) Start() error {
 			return errors.New("device uuid is empty")
 		}
 
+		index, ret := d.GetIndex()
+		if ret != nvml.SUCCESS {
+			return fmt.Errorf("failed to get device index: %v", nvml.ErrorString(ret))
+		}
+
 		// TODO: this returns 0 for all GPUs...
 		minorNumber, ret := d.GetMinorNumber()
 		if ret != nvml.SUCCESS {
@@ -243,7 +259,9 @@ func (inst *instance) Start() error {
 		inst.devices[uuid] = &DeviceInfo{
 			UUID: uuid,
 
+			Index:           index,
 			MinorNumber:     minorNumber,
+			Domain:          pciInfo.Domain,
 			Bus:             pciInfo.Bus,
 			Device:          pciInfo.Device,
 			Name:            name,
@@ -334,7 +352,9 @@ func (inst *instance) Get() (*Output, error) {
 		latestInfo := &DeviceInfo{
 			UUID: devInfo.UUID,
 
+			Index:       devInfo.Index,
 			MinorNumber: devInfo.MinorNumber,
+			Domain:      devInfo.Domain,
 			Bus:         devInfo.Bus,
 			Device:      devInfo.Device,
 
@@ -394,6 +414,11 @@ func (inst *instance) Get() (*Output, error) {
 		if err != nil {
 			return st, err
 		}
+
+		latestInfo.RemappedRows, err = GetRemappedRows(devInfo.UUID, devInfo.device)
+		if err != nil {
+			return st, err
+		}
 	}
 
 	sort.Slice(st.DeviceInfos, func(i, j int) bool {
@@ -0,0 +1,67 @@
+package nvml
+
+import (
+	"fmt"
+
+	"github.com/NVIDIA/go-nvlib/pkg/nvlib/device"
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/leptonai/gpud/log"
+)
+
+// RemappedRows represents the row remapping data.
+// The row remapping replaces the memory rows with the ECC errors
+// with the spare rows, and is only supported on Ampere or newer GPUs.
+// ref. https://docs.nvidia.com/deploy/a100-gpu-mem-error-mgmt/index.html#row-remapping
+type RemappedRows struct {
+	// Represents the GPU UUID.
+	UUID string `json:"uuid"`
+
+	// Set true if the device supports the row remapping.
+	Supported bool `json:"supported"`
+
+	// The number of the rows remapped due to the correctable errors.
+	RemappedDueToCorrectableErrors int `json:"remapped_due_to_correctable_errors"`
+	// The number of the rows remapped due to the uncorrectable errors.
+	RemappedDueToUncorrectableErrors int `json:"remapped_due_to_uncorrectable_errors"`
+
+	// Set true if a row is pending remapping,
+	// which requires the GPU reset to take effect.
+	RemappingPending bool `json:"remapping_pending"`
+	// Set true if the row remapping failed in the past,
+	// which qualifies the GPU for the RMA (replacement).
+	RemappingFailed bool `json:"remapping_failed"`
+}
+
+// Returns true if the GPU needs to be reset to remap the pending rows.
+func (r RemappedRows) RequiresReset() bool {
+	return r.RemappingPending
+}
+
+// Returns true if the row remapping failed,
+// which is the definitive signal that the GPU needs to be replaced.
+func (r RemappedRows) QualifiesForRMA() bool {
+	return r.RemappingFailed
+}
+
+func GetRemappedRows(uuid string, dev device.Device) (RemappedRows, error) {
+	result := RemappedRows{
+		UUID: uuid,
+	}
+
+	// ref. https://docs.nvidia.com/deploy/nvml-api/group__nvmlDeviceQueries.html#group__nvmlDeviceQueries_1g055e7c34f7f15b6ae9aac1dabd60870d
+	corrRows, uncRows, isPending, failureOccurred, ret := dev.GetRemappedRows()
+	if ret != nvml.SUCCESS {
+		if ret == nvml.ERROR_NOT_SUPPORTED {
+			log.Logger.Debugw("get remapped rows not supported", "error", nvml.ErrorString(ret))
+			return result, nil
+		}
+		return result, fmt.Errorf("failed to get remapped rows: %s", nvml.ErrorString(ret))
+	}
+
+	result.Supported = true
+	result.RemappedDueToCorrectableErrors = corrRows
+	result.RemappedDueToUncorrectableErrors = uncRows
+	result.RemappingPending = isPending
+	result.RemappingFailed = failureOccurred
+	return result, nil
+}
@@ -0,0 +1,44 @@
+package nvml
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+)
+
+// Returns the NVIDIA driver version (e.g., "535.161.08").
+func (inst *instance) DriverVersion() (string, error) {
+	inst.mu.RLock()
+	defer inst.mu.RUnlock()
+
+	if inst.nvmlLib == nil {
+		return "", errors.New("nvml not initialized")
+	}
+	ver, ret := inst.nvmlLib.SystemGetDriverVersion()
+	if ret != nvml.SUCCESS {
+		return "", fmt.Errorf("failed to get driver version: %v", nvml.ErrorString(ret))
+	}
+	return ver, nil
+}
+
+// Returns the CUDA version supported by the driver (e.g., "12.2").
+func (inst *instance) CUDAVersion() (string, error) {
+	inst.mu.RLock()
+	defer inst.mu.RUnlock()
+
+	if inst.nvmlLib == nil {
+		return "", errors.New("nvml not initialized")
+	}
+	ver, ret := inst.nvmlLib.SystemGetCudaDriverVersion_v2()
+	if ret != nvml.SUCCESS {
+		return "", fmt.Errorf("failed to get cuda version: %v", nvml.ErrorString(ret))
+	}
+	return FormatCUDAVersion(ver), nil
+}
+
+// Formats the CUDA version returned by NVML (e.g., 12020)
+// in the nvidia-smi format (e.g., "12.2").
+func FormatCUDAVersion(ver int) string {
+	return fmt.Sprintf("%d.%d", ver/1000, (ver%1000)/10)
+}
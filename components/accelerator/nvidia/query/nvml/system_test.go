@@ -0,0 +1,21 @@
+package nvml
+
+import "testing"
+
+func TestFormatCUDAVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		ver  int
+		want string
+	}{
+		{ver: 12020, want: "12.2"},
+		{ver: 11080, want: "11.8"},
+		{ver: 12000, want: "12.0"},
+	}
+	for _, tt := range tests {
+		if got := FormatCUDAVersion(tt.ver); got != tt.want {
+			t.Errorf("FormatCUDAVersion(%d) = %q, want %q", tt.ver, got, tt.want)
+		}
+	}
+}
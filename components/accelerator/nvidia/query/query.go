@@ -18,6 +18,7 @@ import (
 	metrics_temperature "github.com/leptonai/gpud/components/accelerator/nvidia/query/metrics/temperature"
 	metrics_utilization "github.com/leptonai/gpud/components/accelerator/nvidia/query/metrics/utilization"
 	"github.com/leptonai/gpud/components/accelerator/nvidia/query/nvml"
+	components_metrics "github.com/leptonai/gpud/components/metrics"
 	"github.com/leptonai/gpud/components/query"
 	query_config "github.com/leptonai/gpud/components/query/config"
 	"github.com/leptonai/gpud/components/systemd"
@@ -27,8 +28,11 @@ import (
 	"sigs.k8s.io/yaml"
 )
 
+// SharedPollerName is the name of the shared NVIDIA poller.
+const SharedPollerName = "shared-nvidia-poller"
+
 var DefaultPoller = query.New(
-	"shared-nvidia-poller",
+	SharedPollerName,
 	query_config.Config{
 		Interval:  metav1.Duration{Duration: query_config.DefaultPollInterval},
 		QueueSize: query_config.DefaultQueueSize,
@@ -50,6 +54,11 @@ func GetSuccessOnce() <-chan any {
 
 // Get all nvidia component queries.
 func Get(ctx context.Context) (output any, err error) {
+	start := time.Now()
+	defer func() {
+		components_metrics.SetGetLatency(SharedPollerName, time.Since(start))
+	}()
+
 	if err := nvml.StartDefaultInstance(ctx); err != nil {
 		return nil, err
 	}
@@ -70,14 +79,10 @@ func Get(ctx context.Context) (output any, err error) {
 		})
 	}()
 
-	if o.SMIExists {
-		o.SMI, err = GetSMIOutput(cctx)
-		if err != nil {
-			o.SMIQueryErrors = append(o.SMIQueryErrors, err.Error())
-		}
-		if o.SMI != nil && o.SMI.SummaryFailure != nil {
-			o.SMIQueryErrors = append(o.SMIQueryErrors, o.SMI.SummaryFailure.Error())
-		}
+	// query nvidia-smi only if NVML is not preferred (or not available)
+	preferNVML := defaultPreferNVML.Load() && nvml.DefaultInstance() != nil && nvml.DefaultInstance().NVMLExists()
+	if o.SMIExists && !preferNVML {
+		querySMI(cctx, o)
 	}
 
 	if o.FabricManagerExists {
@@ -119,10 +124,11 @@ func Get(ctx context.Context) (output any, err error) {
 		log.Logger.Debugw("default nvml instance ready")
 	}
 
-	o.NVML, err = nvml.DefaultInstance().Get()
-	if err != nil {
-		log.Logger.Warnw("nvml get failed", "error", err)
-		o.NVMLErrors = append(o.NVMLErrors, err.Error())
+	var nvmlErr error
+	o.NVML, nvmlErr = nvml.DefaultInstance().Get()
+	if nvmlErr != nil {
+		log.Logger.Warnw("nvml get failed", "error", nvmlErr)
+		o.NVMLErrors = append(o.NVMLErrors, nvmlErr.Error())
 	} else {
 		now := time.Now().UTC()
 		nowUnix := float64(now.Unix())
@@ -138,7 +144,7 @@ func Get(ctx context.Context) (output any, err error) {
 		metrics_processes.SetLastUpdateUnixSeconds(nowUnix)
 
 		for _, dev := range o.NVML.DeviceInfos {
-			log.Logger.Debugw("setting metrics for device", "uuid", dev.UUID, "bus", dev.Bus, "device", dev.Device, "minorNumber", dev.MinorNumber)
+			log.Logger.Debugw("setting metrics for device", "uuid", dev.UUID, "index", dev.Index, "bus", dev.Bus, "device", dev.Device, "minorNumber", dev.MinorNumber)
 
 			if err := metrics_clock.SetHWSlowdown(ctx, dev.UUID, dev.ClockEvents.HWSlowdown, now); err != nil {
 				return nil, err
@@ -169,6 +175,15 @@ func Get(ctx context.Context) (output any, err error) {
 			if err := metrics_ecc.SetVolatileTotalUncorrected(ctx, dev.UUID, float64(dev.ECCErrors.Volatile.Total.Uncorrected), now); err != nil {
 				return nil, err
 			}
+			if dev.RemappedRows.Supported {
+				metrics_ecc.SetRemappedRows(
+					dev.UUID,
+					dev.RemappedRows.RemappedDueToCorrectableErrors,
+					dev.RemappedRows.RemappedDueToUncorrectableErrors,
+					dev.RemappedRows.RemappingPending,
+					dev.RemappedRows.RemappingFailed,
+				)
+			}
 
 			if err := metrics_memory.SetTotalBytes(ctx, dev.UUID, float64(dev.Memory.TotalBytes), now); err != nil {
 				return nil, err
@@ -249,9 +264,37 @@ func Get(ctx context.Context) (output any, err error) {
 		}
 	}
 
+	if preferNVML {
+		if nvmlErr == nil {
+			o.SMI, err = GetSMIOutputFromNVML(nvml.DefaultInstance(), o.NVML)
+		} else {
+			err = nvmlErr
+		}
+		if err != nil {
+			log.Logger.Warnw("failed to populate nvidia-smi output from nvml", "error", err)
+			o.SMI = nil
+			if o.SMIExists {
+				log.Logger.Warnw("falling back to nvidia-smi")
+				querySMI(cctx, o)
+			}
+		}
+	}
+
 	return o, nil
 }
 
+// Queries nvidia-smi and sets the output or the errors.
+func querySMI(ctx context.Context, o *Output) {
+	var err error
+	o.SMI, err = GetSMIOutput(ctx)
+	if err != nil {
+		o.SMIQueryErrors = append(o.SMIQueryErrors, err.Error())
+	}
+	if o.SMI != nil && o.SMI.SummaryFailure != nil {
+		o.SMIQueryErrors = append(o.SMIQueryErrors, o.SMI.SummaryFailure.Error())
+	}
+}
+
 const (
 	StateKeySMIExists           = "smi_exists"
 	StateKeyFabricManagerExists = "fabric_manager_exists"
@@ -0,0 +1,102 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/leptonai/gpud/components/accelerator/nvidia/query/nvml"
+)
+
+var defaultPreferNVML atomic.Bool
+
+// Set true to populate the nvidia-smi output fields (e.g., driver version, ECC error counts)
+// from NVML directly, instead of running and parsing nvidia-smi,
+// which is brittle across the driver versions and slow on large nodes.
+// Falls back to nvidia-smi if NVML is not available or fails.
+// Applies to the shared NVIDIA poller (DefaultPoller), thus to all NVIDIA components,
+// and must be set once before the poller starts (e.g., from the server config).
+func SetDefaultPreferNVML(prefer bool) {
+	defaultPreferNVML.Store(prefer)
+}
+
+// Returns the nvidia-smi output populated via NVML, with one GPU entry per NVML device.
+// The nvidia-smi specific fields (e.g., raw output, summary, clock event reasons) are left empty,
+// and the components read the NVML output for those instead.
+func GetSMIOutputFromNVML(inst nvml.Instance, nvmlOutput *nvml.Output) (*SMIOutput, error) {
+	if inst == nil || !inst.NVMLExists() {
+		return nil, errors.New("nvml not available")
+	}
+	if nvmlOutput == nil {
+		return nil, errors.New("nvml output not available")
+	}
+
+	driverVersion, err := inst.DriverVersion()
+	if err != nil {
+		return nil, err
+	}
+	cudaVersion, err := inst.CUDAVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	o := &SMIOutput{
+		Timestamp:     time.Now().UTC().Format(time.ANSIC),
+		DriverVersion: driverVersion,
+		CUDAVersion:   cudaVersion,
+		AttachedGPUs:  len(nvmlOutput.DeviceInfos),
+	}
+	for _, dev := range nvmlOutput.DeviceInfos {
+		o.GPUs = append(o.GPUs, convertNVMLDeviceInfo(dev))
+	}
+	return o, nil
+}
+
+// Returns the nvidia-smi GPU fields populated from the NVML device info,
+// formatted the same as the nvidia-smi query output (e.g., "81559 MiB", "35 C").
+// The power readings are left empty, as NVML does not report all the limits.
+func convertNVMLDeviceInfo(dev *nvml.DeviceInfo) NvidiaSMIGPU {
+	return NvidiaSMIGPU{
+		ID:          dev.UUID,
+		ProductName: dev.Name,
+
+		ECCErrors: &SMIECCErrors{
+			ID: dev.UUID,
+			Aggregate: &SMIECCErrorAggregate{
+				DRAMCorrectable:   strconv.FormatUint(dev.ECCErrors.Aggregate.DRAM.Corrected, 10),
+				DRAMUncorrectable: strconv.FormatUint(dev.ECCErrors.Aggregate.DRAM.Uncorrected, 10),
+				SRAMCorrectable:   strconv.FormatUint(dev.ECCErrors.Aggregate.SRAM.Corrected, 10),
+				SRAMUncorrectable: strconv.FormatUint(dev.ECCErrors.Aggregate.SRAM.Uncorrected, 10),
+			},
+			Volatile: &SMIECCErrorVolatile{
+				DRAMCorrectable:   strconv.FormatUint(dev.ECCErrors.Volatile.DRAM.Corrected, 10),
+				DRAMUncorrectable: strconv.FormatUint(dev.ECCErrors.Volatile.DRAM.Uncorrected, 10),
+				SRAMCorrectable:   strconv.FormatUint(dev.ECCErrors.Volatile.SRAM.Corrected, 10),
+				SRAMUncorrectable: strconv.FormatUint(dev.ECCErrors.Volatile.SRAM.Uncorrected, 10),
+			},
+		},
+		Temperature: &SMIGPUTemperature{
+			ID:                      dev.UUID,
+			Current:                 fmt.Sprintf("%d C", dev.Temperature.CurrentCelsiusGPUCore),
+			Limit:                   "N/A",
+			Shutdown:                fmt.Sprintf("%d C", dev.Temperature.ThresholdCelsiusShutdown),
+			Slowdown:                fmt.Sprintf("%d C", dev.Temperature.ThresholdCelsiusSlowdown),
+			MaxOperatingLimit:       fmt.Sprintf("%d C", dev.Temperature.ThresholdCelsiusGPUMax),
+			Target:                  "N/A",
+			MemoryMaxOperatingLimit: fmt.Sprintf("%d C", dev.Temperature.ThresholdCelsiusMemMax),
+		},
+		FBMemoryUsage: &SMIFBMemoryUsage{
+			ID:       dev.UUID,
+			Total:    formatMiB(dev.Memory.TotalBytes),
+			Reserved: formatMiB(dev.Memory.ReservedBytes),
+			Used:     formatMiB(dev.Memory.UsedBytes),
+			Free:     formatMiB(dev.Memory.FreeBytes),
+		},
+	}
+}
+
+func formatMiB(b uint64) string {
+	return fmt.Sprintf("%d MiB", b/1024/1024)
+}
@@ -0,0 +1,113 @@
+package query
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/leptonai/gpud/components/accelerator/nvidia/query/nvml"
+)
+
+type fakeNVMLInstance struct {
+	nvml.Instance
+
+	exists        bool
+	driverVersion string
+	cudaVersion   string
+	err           error
+}
+
+func (f *fakeNVMLInstance) NVMLExists() bool               { return f.exists }
+func (f *fakeNVMLInstance) DriverVersion() (string, error) { return f.driverVersion, f.err }
+func (f *fakeNVMLInstance) CUDAVersion() (string, error)   { return f.cudaVersion, f.err }
+
+func TestGetSMIOutputFromNVML(t *testing.T) {
+	t.Parallel()
+
+	nvmlOutput := &nvml.Output{DeviceInfos: []*nvml.DeviceInfo{
+		{
+			UUID: "GPU-0",
+			Name: "NVIDIA H100 80GB HBM3",
+			Memory: nvml.Memory{
+				TotalBytes:    81559 * 1024 * 1024,
+				ReservedBytes: 551 * 1024 * 1024,
+				UsedBytes:     1024 * 1024 * 1024,
+				FreeBytes:     79984 * 1024 * 1024,
+			},
+			Temperature: nvml.Temperature{CurrentCelsiusGPUCore: 35, ThresholdCelsiusShutdown: 92, ThresholdCelsiusSlowdown: 89},
+			ECCErrors: nvml.ECCErrors{
+				Aggregate: nvml.AllECCErrorCounts{DRAM: nvml.ECCErrorCounts{Corrected: 3}},
+				Volatile:  nvml.AllECCErrorCounts{DRAM: nvml.ECCErrorCounts{Uncorrected: 2}},
+			},
+		},
+		{UUID: "GPU-1", Name: "NVIDIA H100 80GB HBM3"},
+	}}
+
+	o, err := GetSMIOutputFromNVML(&fakeNVMLInstance{exists: true, driverVersion: "535.161.08", cudaVersion: "12.2"}, nvmlOutput)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.DriverVersion != "535.161.08" || o.CUDAVersion != "12.2" || o.AttachedGPUs != 2 {
+		t.Fatalf("unexpected output %+v", o)
+	}
+	if len(o.GPUs) != 2 || o.GPUs[0].ID != "GPU-0" || o.GPUs[1].ID != "GPU-1" || o.GPUs[0].ProductName != "NVIDIA H100 80GB HBM3" {
+		t.Fatalf("expected the per-GPU fields from nvml, got %+v", o.GPUs)
+	}
+	if errs := o.FindGPUErrs(); len(errs) != 0 {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+
+	g := o.GPUs[0]
+	if g.ECCErrors.Aggregate.DRAMCorrectable != "3" || g.ECCErrors.Volatile.DRAMUncorrectable != "2" {
+		t.Fatalf("unexpected ECC errors %+v", g.ECCErrors)
+	}
+	if errs := g.ECCErrors.FindVolatileUncorrectableErrs(); len(errs) != 1 {
+		t.Fatalf("expected 1 volatile uncorrectable error, got %v", errs)
+	}
+	mem, err := g.FBMemoryUsage.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mem.TotalBytes != 81559*1024*1024 || mem.UsedBytes != 1024*1024*1024 {
+		t.Fatalf("unexpected memory usage %+v", mem)
+	}
+	temp, err := g.Temperature.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if temp.CurrentCelsius != "35.00" || temp.ShutdownCelsius != "92.00" || temp.SlowdownCelsius != "89.00" {
+		t.Fatalf("unexpected temperature %+v", temp)
+	}
+
+	if _, err := GetSMIOutputFromNVML(nil, nvmlOutput); err == nil {
+		t.Fatal("expected error without nvml instance")
+	}
+	if _, err := GetSMIOutputFromNVML(&fakeNVMLInstance{exists: false}, nvmlOutput); err == nil {
+		t.Fatal("expected error without nvml")
+	}
+	if _, err := GetSMIOutputFromNVML(&fakeNVMLInstance{exists: true}, nil); err == nil {
+		t.Fatal("expected error without nvml output")
+	}
+	errFailed := errors.New("failed")
+	if _, err := GetSMIOutputFromNVML(&fakeNVMLInstance{exists: true, err: errFailed}, nvmlOutput); !errors.Is(err, errFailed) {
+		t.Fatalf("expected %v, got %v", errFailed, err)
+	}
+}
+
+func TestOutputSMIMissing(t *testing.T) {
+	t.Parallel()
+
+	if !(&Output{}).SMIMissing() {
+		t.Fatal("expected nvidia-smi to be missing")
+	}
+	// populated via NVML
+	if (&Output{SMI: &SMIOutput{GPUs: []NvidiaSMIGPU{{ID: "GPU-0"}}}}).SMIMissing() {
+		t.Fatal("expected nvidia-smi output to be populated")
+	}
+	// no per-GPU output (e.g., no device found via NVML)
+	if !(&Output{SMI: &SMIOutput{DriverVersion: "535.161.08"}}).SMIMissing() {
+		t.Fatal("expected nvidia-smi to be missing without the per-GPU output")
+	}
+	if (&Output{SMIExists: true}).SMIMissing() {
+		t.Fatal("expected nvidia-smi to exist")
+	}
+}
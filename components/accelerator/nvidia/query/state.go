@@ -0,0 +1,40 @@
+package query
+
+import (
+	"fmt"
+
+	"github.com/leptonai/gpud/components"
+)
+
+// Returns true if any NVIDIA GPU is found on the host (via NVML).
+func (o *Output) GPUFound() bool {
+	return o != nil && o.NVML != nil && len(o.NVML.DeviceInfos) > 0
+}
+
+// Returns true if nvidia-smi is not installed,
+// and its per-GPU output is not populated otherwise (e.g., via NVML).
+func (o *Output) SMIMissing() bool {
+	return !o.SMIExists && (o.SMI == nil || len(o.SMI.GPUs) == 0)
+}
+
+// Returns the component state when nvidia-smi is not installed,
+// to distinguish the missing GPU tooling from the failing one
+// (see "SMIQueryErrors").
+// It is healthy on a non-GPU node, as nvidia-smi is not expected,
+// and unhealthy on a GPU node, as nvidia-smi is expected but missing.
+func (o *Output) SMIMissingState(name string) components.State {
+	gpuFound := o.GPUFound()
+	reason := "nvidia-smi not found (no NVIDIA GPU found)"
+	if gpuFound {
+		reason = "nvidia-smi not found but NVIDIA GPU found"
+	}
+	return components.State{
+		Name:       name,
+		Healthy:    !gpuFound,
+		Reason:     reason,
+		ReasonCode: components.ReasonSMIMissing,
+		ExtraInfo: map[string]string{
+			StateKeySMIExists: fmt.Sprintf("%v", o.SMIExists),
+		},
+	}
+}
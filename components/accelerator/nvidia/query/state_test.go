@@ -0,0 +1,30 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/components/accelerator/nvidia/query/nvml"
+)
+
+func TestOutputSMIMissingState(t *testing.T) {
+	t.Parallel()
+
+	noGPU := &Output{}
+	s := noGPU.SMIMissingState("test")
+	if !s.Healthy {
+		t.Fatalf("expected healthy on a non-GPU node, got %+v", s)
+	}
+	if s.Name != "test" || s.ReasonCode != components.ReasonSMIMissing || s.ExtraInfo[StateKeySMIExists] != "false" {
+		t.Fatalf("unexpected state %+v", s)
+	}
+
+	gpu := &Output{NVML: &nvml.Output{DeviceInfos: []*nvml.DeviceInfo{{UUID: "GPU-0"}}}}
+	s = gpu.SMIMissingState("test")
+	if s.Healthy {
+		t.Fatalf("expected unhealthy on a GPU node, got %+v", s)
+	}
+	if s.ReasonCode != components.ReasonSMIMissing {
+		t.Fatalf("unexpected reason code %q", s.ReasonCode)
+	}
+}
@@ -19,26 +19,108 @@ const (
 	// "D.4 Non-Fatal NVSwitch SXid Errors"
 	// https://docs.nvidia.com/datacenter/tesla/pdf/fabric-manager-user-guide.pdf
 	RegexNVSwitchSXidDmesg = `SXid.*?: (\d+),`
+
+	// e.g.,
+	// [111111111.111] nvidia-nvswitch3: SXid (PCI:0000:05:00.0): 12028, Non-fatal, Link 32 egress non-posted PRIV error (First)
+	RegexNVSwitchSXidLinkID = `SXid.*?: \d+,.*?\bLink (\d+)\b`
+
+	// e.g.,
+	// [111111111.111] nvidia-nvswitch3: SXid (PCI:0000:05:00.0): 12028, Non-fatal, Link 32 egress non-posted PRIV error (First)
+	RegexNVSwitchSXidPCIBusID = `SXid \(PCI:([0-9a-fA-F]+:[0-9a-fA-F]+:[0-9a-fA-F]+\.[0-9a-fA-F]+)\)`
+
+	// e.g.,
+	// [111111111.111] nvidia-nvswitch3: SXid (PCI:0000:05:00.0): 12028, Non-fatal, Link 32 egress non-posted PRIV error (First)
+	RegexNVSwitchSXidFirst = `SXid.*?: \d+,.*\(First\)\s*$`
+)
+
+var (
+	CompiledRegexNVSwitchSXidDmesg    = regexp.MustCompile(RegexNVSwitchSXidDmesg)
+	CompiledRegexNVSwitchSXidLinkID   = regexp.MustCompile(RegexNVSwitchSXidLinkID)
+	CompiledRegexNVSwitchSXidPCIBusID = regexp.MustCompile(RegexNVSwitchSXidPCIBusID)
+	CompiledRegexNVSwitchSXidFirst    = regexp.MustCompile(RegexNVSwitchSXidFirst)
 )
 
-var CompiledRegexNVSwitchSXidDmesg = regexp.MustCompile(RegexNVSwitchSXidDmesg)
+const (
+	// The SXid line is the first occurrence of the error (marked with "(First)").
+	OccurrenceFirst = "first"
+	// The SXid line is a later occurrence of the error (without the "(First)" marker),
+	// which is often suppressed for the repeated fatal SXids.
+	OccurrenceRepeat = "repeat"
+)
 
 // Extracts the nvidia NVSwitch SXid error code from the dmesg log line.
 // Returns 0 if the error code is not found.
+// If the line contains multiple SXid error codes, returns the first one.
 // https://docs.nvidia.com/datacenter/tesla/pdf/fabric-manager-user-guide.pdf
 func ExtractNVSwitchSXid(line string) int {
-	if match := CompiledRegexNVSwitchSXidDmesg.FindStringSubmatch(line); match != nil {
+	ids := ExtractNVSwitchSXids(line)
+	if len(ids) == 0 {
+		return 0
+	}
+	return ids[0]
+}
+
+// Extracts all the nvidia NVSwitch SXid error codes from the dmesg log line,
+// in the order they appear (e.g., fabric manager dumps may concatenate multiple events).
+// Returns an empty slice if no error code is found.
+// https://docs.nvidia.com/datacenter/tesla/pdf/fabric-manager-user-guide.pdf
+func ExtractNVSwitchSXids(line string) []int {
+	ids := make([]int, 0)
+	for _, match := range CompiledRegexNVSwitchSXidDmesg.FindAllStringSubmatch(line, -1) {
+		if id, err := strconv.Atoi(match[1]); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// Extracts the NVSwitch link (port) number from the SXid dmesg log line.
+// Returns nil if the link number is not found.
+func ExtractNVSwitchSXidLinkID(line string) *int {
+	if match := CompiledRegexNVSwitchSXidLinkID.FindStringSubmatch(line); match != nil {
 		if id, err := strconv.Atoi(match[1]); err == nil {
-			return id
+			return &id
 		}
 	}
-	return 0
+	return nil
+}
+
+// Extracts the NVSwitch PCI bus ID (e.g., "0000:05:00.0") from the SXid dmesg log line.
+// Returns an empty string if the PCI bus ID is not found.
+func ExtractNVSwitchSXidPCIBusID(line string) string {
+	if match := CompiledRegexNVSwitchSXidPCIBusID.FindStringSubmatch(line); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// Returns the occurrence of the SXid dmesg log line
+// (OccurrenceFirst or OccurrenceRepeat).
+// Returns an empty string if the line is not an SXid line.
+func ExtractNVSwitchSXidOccurrence(line string) string {
+	if !CompiledRegexNVSwitchSXidDmesg.MatchString(line) {
+		return ""
+	}
+	if CompiledRegexNVSwitchSXidFirst.MatchString(line) {
+		return OccurrenceFirst
+	}
+	return OccurrenceRepeat
 }
 
 type DmesgError struct {
-	Detail      *Detail        `json:"detail,omitempty"`
-	DetailFound bool           `json:"detail_found"`
-	LogItem     query_log.Item `json:"log_item"`
+	Detail      *Detail `json:"detail,omitempty"`
+	DetailFound bool    `json:"detail_found"`
+	LinkID      *int    `json:"link_id,omitempty"`
+	PCIBusID    string  `json:"pci_bus_id,omitempty"`
+	// Occurrence is OccurrenceFirst, OccurrenceRepeat, or empty if unknown.
+	Occurrence string `json:"occurrence,omitempty"`
+	// PortType is the type of the port (link) the error occurred on,
+	// PortTypeUnknown if the topology is not configured.
+	PortType PortType `json:"port_type,omitempty"`
+	// PortImpact is the impact of the error on the port type
+	// (see Detail.ImpactOn), empty if the detail is not found.
+	PortImpact string         `json:"port_impact,omitempty"`
+	LogItem    query_log.Item `json:"log_item"`
 }
 
 func (de *DmesgError) JSON() ([]byte, error) {
@@ -65,12 +147,23 @@ func ParseDmesgErrorYAML(data []byte) (*DmesgError, error) {
 	return de, nil
 }
 
+// Parses the SXid dmesg log line without the topology,
+// so the port type is PortTypeUnknown.
 func ParseDmesgLogLine(line string) (DmesgError, error) {
+	return ParseDmesgLogLineWithTopology(line, nil)
+}
+
+// Parses the SXid dmesg log line, classifying the port (link) type
+// with the topology (nil if not configured).
+func ParseDmesgLogLineWithTopology(line string, topo *Topology) (DmesgError, error) {
 	de := DmesgError{
 		LogItem: query_log.Item{
 			Line:    line,
 			Matched: nil,
 		},
+		LinkID:     ExtractNVSwitchSXidLinkID(line),
+		PCIBusID:   ExtractNVSwitchSXidPCIBusID(line),
+		Occurrence: ExtractNVSwitchSXidOccurrence(line),
 	}
 
 	errCode := ExtractNVSwitchSXid(line)
@@ -83,5 +176,8 @@ func ParseDmesgLogLine(line string) (DmesgError, error) {
 		de.DetailFound = true
 	}
 
+	de.PortType = topo.ClassifyPort(de.LinkID)
+	de.PortImpact = de.Detail.ImpactOn(de.PortType)
+
 	return de, nil
 }
@@ -1,6 +1,9 @@
 package sxid
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func TestExtractNVSwitchSXid(t *testing.T) {
 	t.Parallel()
@@ -59,3 +62,136 @@ func TestExtractNVSwitchSXid(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractNVSwitchSXids(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []int
+	}{
+		{
+			name:     "no match",
+			input:    "Regular log content without Xid errors",
+			expected: []int{},
+		},
+		{
+			name:     "single match",
+			input:    "[131453.740743] nvidia-nvswitch0: SXid (PCI:0000:a9:00.0): 20034, Fatal, Link 30 LTSSM Fault Up",
+			expected: []int{20034},
+		},
+		{
+			name:     "two matches",
+			input:    "[111111111.111] nvidia-nvswitch3: SXid (PCI:0000:05:00.0): 12028, Non-fatal, Link 32 egress non-posted PRIV error (First) nvidia-nvswitch0: SXid (PCI:0000:a9:00.0): 20034, Fatal, Link 30 LTSSM Fault Up",
+			expected: []int{12028, 20034},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ExtractNVSwitchSXids(tt.input)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("ExtractNVSwitchSXids(%q) = %v, want %v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseDmesgLogLineLinkAndPCIBusID(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		input        string
+		wantLinkID   *int
+		wantPCIBusID string
+	}{
+		{
+			name:         "with (First) suffix",
+			input:        "[111111111.111] nvidia-nvswitch3: SXid (PCI:0000:05:00.0): 12028, Non-fatal, Link 32 egress non-posted PRIV error (First)",
+			wantLinkID:   intPtr(32),
+			wantPCIBusID: "0000:05:00.0",
+		},
+		{
+			name:         "without (First) suffix",
+			input:        "[131453.740743] nvidia-nvswitch0: SXid (PCI:0000:a9:00.0): 20034, Fatal, Link 30 LTSSM Fault Up",
+			wantLinkID:   intPtr(30),
+			wantPCIBusID: "0000:a9:00.0",
+		},
+		{
+			name:         "no link",
+			input:        "[131453.740754] nvidia-nvswitch0: SXid (PCI:0000:a9:00.0): 20034, Severity 1 Engine instance 30 Sub-engine instance 00",
+			wantLinkID:   nil,
+			wantPCIBusID: "0000:a9:00.0",
+		},
+		{
+			name:         "no context",
+			input:        "Some log content SXid error: 31, other info",
+			wantLinkID:   nil,
+			wantPCIBusID: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			de, err := ParseDmesgLogLine(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (de.LinkID == nil) != (tt.wantLinkID == nil) || (de.LinkID != nil && *de.LinkID != *tt.wantLinkID) {
+				t.Errorf("LinkID = %v, want %v", de.LinkID, tt.wantLinkID)
+			}
+			if de.PCIBusID != tt.wantPCIBusID {
+				t.Errorf("PCIBusID = %q, want %q", de.PCIBusID, tt.wantPCIBusID)
+			}
+		})
+	}
+}
+
+func TestParseDmesgLogLineOccurrence(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "with (First) marker",
+			input: "[111111111.111] nvidia-nvswitch3: SXid (PCI:0000:05:00.0): 12028, Non-fatal, Link 32 egress non-posted PRIV error (First)",
+			want:  OccurrenceFirst,
+		},
+		{
+			name:  "with (First) marker and trailing spaces",
+			input: "[111111111.111] nvidia-nvswitch3: SXid (PCI:0000:05:00.0): 12028, Non-fatal, Link 32 egress non-posted PRIV error (First)  ",
+			want:  OccurrenceFirst,
+		},
+		{
+			name:  "without (First) marker",
+			input: "[131453.740743] nvidia-nvswitch0: SXid (PCI:0000:a9:00.0): 20034, Fatal, Link 30 LTSSM Fault Up",
+			want:  OccurrenceRepeat,
+		},
+		{
+			name:  "not an SXid line",
+			input: "[131453.740743] some other log line (First)",
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			de, err := ParseDmesgLogLine(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if de.Occurrence != tt.want {
+				t.Errorf("Occurrence = %q, want %q", de.Occurrence, tt.want)
+			}
+		})
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
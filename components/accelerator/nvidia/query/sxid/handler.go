@@ -0,0 +1,80 @@
+package sxid
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// URLPath is the path to list all the SXid details,
+	// and "URLPath/{id}" serves the detail of a single SXid.
+	URLPath = "/sxid"
+
+	RequestHeaderContentType = "Content-Type"
+	RequestHeaderJSON        = "application/json"
+	RequestHeaderYAML        = "application/yaml"
+	RequestHeaderJSONIndent  = "json-indent"
+)
+
+// Returns the HTTP handler that serves the SXid catalog:
+// "GET /sxid" lists all the details sorted by the SXid, and
+// "GET /sxid/{id}" returns the detail of the SXid (404 if unknown).
+// Set the "Content-Type: application/yaml" request header for the YAML response.
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+URLPath, func(w http.ResponseWriter, r *http.Request) {
+		writeResponse(w, r, http.StatusOK, AllDetails())
+	})
+	mux.HandleFunc("GET "+URLPath+"/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeResponse(w, r, http.StatusBadRequest, errorResponse{Code: http.StatusBadRequest, Message: "invalid sxid " + strconv.Quote(r.PathValue("id"))})
+			return
+		}
+		d, ok := GetDetail(id)
+		if !ok {
+			writeResponse(w, r, http.StatusNotFound, errorResponse{Code: http.StatusNotFound, Message: "sxid " + strconv.Itoa(id) + " not found"})
+			return
+		}
+		writeResponse(w, r, http.StatusOK, d)
+	})
+	return mux
+}
+
+type errorResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func writeResponse(w http.ResponseWriter, r *http.Request, code int, v any) {
+	var (
+		b           []byte
+		err         error
+		contentType = RequestHeaderJSON
+	)
+	switch r.Header.Get(RequestHeaderContentType) {
+	case RequestHeaderYAML:
+		b, err = yaml.Marshal(v)
+		contentType = RequestHeaderYAML
+	case RequestHeaderJSON, "":
+		if r.Header.Get(RequestHeaderJSONIndent) == "true" {
+			b, err = json.MarshalIndent(v, "", "    ")
+		} else {
+			b, err = json.Marshal(v)
+		}
+	default:
+		code = http.StatusBadRequest
+		b, err = json.Marshal(errorResponse{Code: http.StatusBadRequest, Message: "invalid content type"})
+	}
+	if err != nil {
+		http.Error(w, "failed to marshal response "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(RequestHeaderContentType, contentType)
+	w.WriteHeader(code)
+	_, _ = w.Write(b)
+}
@@ -0,0 +1,105 @@
+package sxid
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(Handler())
+	defer srv.Close()
+
+	get := func(t *testing.T, path string, contentType string) (*http.Response, []byte) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if contentType != "" {
+			req.Header.Set(RequestHeaderContentType, contentType)
+		}
+		resp, err := srv.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp, b
+	}
+
+	t.Run("list", func(t *testing.T) {
+		resp, b := get(t, URLPath, "")
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+		}
+		var ds []Detail
+		if err := json.Unmarshal(b, &ds); err != nil {
+			t.Fatal(err)
+		}
+		if len(ds) != len(details) {
+			t.Fatalf("listed %d details, want %d", len(ds), len(details))
+		}
+	})
+
+	t.Run("get json", func(t *testing.T) {
+		resp, b := get(t, URLPath+"/11004", "")
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+		}
+		var d Detail
+		if err := json.Unmarshal(b, &d); err != nil {
+			t.Fatal(err)
+		}
+		if d.ID != 11004 || d.Name != "Ingress invalid ACL" {
+			t.Fatalf("unexpected detail: %+v", d)
+		}
+	})
+
+	t.Run("get yaml", func(t *testing.T) {
+		resp, b := get(t, URLPath+"/11004", RequestHeaderYAML)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+		}
+		if ct := resp.Header.Get(RequestHeaderContentType); ct != RequestHeaderYAML {
+			t.Fatalf("content type = %q, want %q", ct, RequestHeaderYAML)
+		}
+		var d Detail
+		if err := yaml.Unmarshal(b, &d); err != nil {
+			t.Fatal(err)
+		}
+		if d.ID != 11004 {
+			t.Fatalf("unexpected detail: %+v", d)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		resp, _ := get(t, URLPath+"/1", "")
+		if resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusNotFound)
+		}
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		resp, _ := get(t, URLPath+"/abc", "")
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("invalid content type", func(t *testing.T) {
+		resp, _ := get(t, URLPath, "text/plain")
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+		}
+	})
+}
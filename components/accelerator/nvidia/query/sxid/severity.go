@@ -0,0 +1,66 @@
+package sxid
+
+import "fmt"
+
+// Severity is the severity level of an SXid error,
+// derived from its PotentialFatal and AlwaysFatal flags.
+type Severity int
+
+const (
+	// SeverityInfo is used when there is no known error detail.
+	SeverityInfo Severity = iota
+	SeverityNonFatal
+	SeverityPotentialFatal
+	SeverityFatal
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityNonFatal:
+		return "non-fatal"
+	case SeverityPotentialFatal:
+		return "potential-fatal"
+	case SeverityFatal:
+		return "fatal"
+	default:
+		return "unknown"
+	}
+}
+
+// Returns the severity of its string representation
+// (e.g., "non-fatal", "potential-fatal").
+func ParseSeverity(s string) (Severity, error) {
+	for _, sev := range []Severity{SeverityInfo, SeverityNonFatal, SeverityPotentialFatal, SeverityFatal} {
+		if sev.String() == s {
+			return sev, nil
+		}
+	}
+	return SeverityInfo, fmt.Errorf("unknown sxid severity %q", s)
+}
+
+// Returns the severity of the error.
+// AlwaysFatal takes precedence over PotentialFatal.
+func (d *Detail) Severity() Severity {
+	if d == nil {
+		return SeverityInfo
+	}
+	if d.AlwaysFatal {
+		return SeverityFatal
+	}
+	if d.PotentialFatal {
+		return SeverityPotentialFatal
+	}
+	return SeverityNonFatal
+}
+
+// Returns the severity of the SXid if found.
+// Otherwise, returns false.
+func SeverityOf(id int) (Severity, bool) {
+	d, ok := GetDetail(id)
+	if !ok {
+		return SeverityInfo, false
+	}
+	return d.Severity(), true
+}
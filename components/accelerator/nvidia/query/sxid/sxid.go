@@ -1,6 +1,14 @@
 // Package sxid provides the NVIDIA SXID error details.
 package sxid
 
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
 // Defines the SXID error type.
 // ref. https://docs.nvidia.com/datacenter/tesla/pdf/fabric-manager-user-guide.pdf
 type Detail struct {
@@ -12,15 +20,253 @@ type Detail struct {
 	Impact         string `json:"impact"`
 	Recovery       string `json:"recovery"`
 	OtherImpact    string `json:"other_impact"`
+
+	// RelatedXids are the GPU Xids that the SXid is documented to
+	// propagate as (e.g., Xid 45 or Xid 74), to correlate the NVSwitch
+	// errors with the GPU errors.
+	RelatedXids []int `json:"related_xids,omitempty"`
+
+	// Set true to replace the existing detail of the same SXid
+	// when registered with RegisterDetail.
+	Override bool `json:"-"`
 }
 
 // Returns the error if found.
+// The user-registered details (see RegisterDetail) take precedence
+// over the built-in details.
 // Otherwise, returns false.
 func GetDetail(id int) (*Detail, bool) {
-	e, ok := details[id]
+	e, ok := lookupDetail(id)
+	e = e.clone()
 	return &e, ok
 }
 
+// Returns the details of the SXids keyed by the id, as GetDetail does,
+// to enrich a batch of the SXids (e.g., extracted from the whole dmesg buffer)
+// in a single call. The unknown SXids are skipped.
+func GetDetails(ids []int) map[int]*Detail {
+	found := make(map[int]*Detail, len(ids))
+
+	overridesMu.RLock()
+	defer overridesMu.RUnlock()
+
+	for _, id := range ids {
+		if _, ok := found[id]; ok {
+			continue
+		}
+		d, ok := overrides[id]
+		if !ok {
+			d, ok = details[id]
+		}
+		if !ok {
+			continue
+		}
+		d = d.clone()
+		found[id] = &d
+	}
+	return found
+}
+
+var (
+	// guards the overrides and the name index built from them
+	overridesMu sync.RWMutex
+	// user-registered details, consulted before the built-in details
+	overrides     = make(map[int]Detail)
+	detailsByName map[string][]Detail
+)
+
+// ErrDetailConflict is returned when registering the detail of an SXid
+// that already exists without the override flag.
+var ErrDetailConflict = errors.New("sxid detail already exists")
+
+// Registers the detail of an SXid (e.g., newly documented SXid that is not
+// in the built-in details yet), to be returned by the lookups.
+// Returns ErrDetailConflict if the SXid already exists (built-in or registered)
+// and the override flag is not set.
+func RegisterDetail(d Detail) error {
+	if err := d.validate(d.ID); err != nil {
+		return err
+	}
+
+	overridesMu.Lock()
+	defer overridesMu.Unlock()
+
+	if !d.Override {
+		_, builtin := details[d.ID]
+		_, registered := overrides[d.ID]
+		if builtin || registered {
+			return fmt.Errorf("%w: %d (set the override flag to replace)", ErrDetailConflict, d.ID)
+		}
+	}
+
+	d = d.clone()
+	d.Override = false
+	overrides[d.ID] = d
+
+	// rebuild on the next name lookup
+	detailsByName = nil
+	return nil
+}
+
+// Removes all the registered details, only used for testing.
+func resetOverrides() {
+	overridesMu.Lock()
+	defer overridesMu.Unlock()
+
+	overrides = make(map[int]Detail)
+	detailsByName = nil
+}
+
+func lookupDetail(id int) (Detail, bool) {
+	overridesMu.RLock()
+	d, ok := overrides[id]
+	overridesMu.RUnlock()
+	if ok {
+		return d, true
+	}
+	d, ok = details[id]
+	return d, ok
+}
+
+// Returns the built-in details merged with the registered details.
+// Must be called with the overrides lock held.
+func mergedDetails() map[int]Detail {
+	if len(overrides) == 0 {
+		return details
+	}
+	merged := make(map[int]Detail, len(details)+len(overrides))
+	for id, d := range details {
+		merged[id] = d
+	}
+	for id, d := range overrides {
+		merged[id] = d
+	}
+	return merged
+}
+
+// Returns the GPU Xids that the SXid is documented to propagate as.
+// Returns nil if the SXid is unknown or has no related Xid.
+func XidsForSXid(id int) []int {
+	d, ok := lookupDetail(id)
+	if !ok {
+		return nil
+	}
+	return d.clone().RelatedXids
+}
+
+// Returns the copy of the detail that does not share
+// the related Xids with the package state.
+func (d Detail) clone() Detail {
+	if len(d.RelatedXids) == 0 {
+		d.RelatedXids = nil
+		return d
+	}
+	xids := make([]int, len(d.RelatedXids))
+	copy(xids, d.RelatedXids)
+	d.RelatedXids = xids
+	return d
+}
+
+// Returns all the errors whose name matches the given name (case-insensitive),
+// sorted by the SXid.
+// Multiple SXids may share the same name (e.g., "Single bit ECC errors").
+// Otherwise, returns false.
+func GetDetailsByName(name string) ([]Detail, bool) {
+	overridesMu.Lock()
+	if detailsByName == nil {
+		detailsByName = make(map[string][]Detail)
+		for _, d := range mergedDetails() {
+			k := normalizeName(d.Name)
+			detailsByName[k] = append(detailsByName[k], d)
+		}
+		for k := range detailsByName {
+			sort.Slice(detailsByName[k], func(i, j int) bool {
+				return detailsByName[k][i].ID < detailsByName[k][j].ID
+			})
+		}
+	}
+	matches, ok := detailsByName[normalizeName(name)]
+	overridesMu.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	copied := make([]Detail, len(matches))
+	for i, d := range matches {
+		copied[i] = d.clone()
+	}
+	return copied, true
+}
+
+// Returns all the errors in the catalog (including the registered details), sorted by the SXid.
+// The returned slice is a copy, so mutating it does not affect
+// the package state.
+func AllDetails() []Detail {
+	overridesMu.RLock()
+	merged := mergedDetails()
+	all := make([]Detail, 0, len(merged))
+	for _, d := range merged {
+		all = append(all, d.clone())
+	}
+	overridesMu.RUnlock()
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].ID < all[j].ID
+	})
+	return all
+}
+
+// Validates the consistency of the SXid catalog, returning all the
+// violations joined, to catch data-entry bugs as new SXids are added.
+func ValidateDetails() error {
+	return validateDetails(details)
+}
+
+func validateDetails(m map[int]Detail) error {
+	ids := make([]int, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	var errs []error
+	for _, id := range ids {
+		if err := m[id].validate(id); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Validates the detail stored under the given key.
+// The legal flag combinations are non-fatal (neither flag set),
+// potential fatal (only PotentialFatal set), and always fatal (both set),
+// as an always fatal error is also a potential fatal error.
+func (d Detail) validate(key int) error {
+	var errs []error
+	if d.ID <= 0 {
+		errs = append(errs, fmt.Errorf("sxid %d: invalid id %d", key, d.ID))
+	}
+	if d.ID != key {
+		errs = append(errs, fmt.Errorf("sxid %d: id %d does not match the key", key, d.ID))
+	}
+	if strings.TrimSpace(d.Name) == "" {
+		errs = append(errs, fmt.Errorf("sxid %d: empty name", key))
+	}
+	if d.AlwaysFatal && !d.PotentialFatal {
+		errs = append(errs, fmt.Errorf("sxid %d: always fatal but not potential fatal", key))
+	}
+	for _, xid := range d.RelatedXids {
+		if xid <= 0 {
+			errs = append(errs, fmt.Errorf("sxid %d: invalid related xid %d", key, xid))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
 // These are copied from:
 // "D.4 Non-Fatal NVSwitch SXid Errors"
 // "D.5 Fatal NVSwitch SXid Errors"
@@ -38,6 +284,7 @@ var details = map[int]Detail{
 		Impact:         "Corresponding GPU NVLink traffic will be stalled, and the subsequent GPU access will hang. The GPU driver on the guest VM will abort CUDA jobs with Xid 45.",
 		Recovery:       "Validate GPU/NVSwitch fabric partition routing information using the NVSwitch-audit tool. Restart the guest VM.",
 		OtherImpact:    "If the error is observed on a Trunk port, partitions that are using NVSwitch trunk ports will be affected.",
+		RelatedXids:    []int{45},
 	},
 	11012: {
 		ID:             11012,
@@ -248,6 +495,7 @@ var details = map[int]Detail{
 		Impact:         "Corresponding GPU NVLink traffic will be stalled, and subsequent GPU access will hang. The GPU driver on the guest VM will abort CUDA jobs with Xid 45.",
 		Recovery:       "Restart Guest VM.",
 		OtherImpact:    "If the error is observed on a Trunk port, the partitions that are using NVSwitch trunk ports will be affected.",
+		RelatedXids:    []int{45},
 	},
 	19084: {
 		ID:             19084,
@@ -280,6 +528,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	11009: {
 		ID:             11009,
@@ -290,6 +539,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	11013: {
 		ID:             11013,
@@ -300,6 +550,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	11018: {
 		ID:             11018,
@@ -310,6 +561,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	11019: {
 		ID:             11019,
@@ -320,6 +572,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	11020: {
 		ID:             11020,
@@ -330,6 +583,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	12001: {
 		ID:             12001,
@@ -340,6 +594,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	12002: {
 		ID:             12002,
@@ -350,6 +605,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	12022: {
 		ID:             12022,
@@ -360,6 +616,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	12024: {
 		ID:             12024,
@@ -370,6 +627,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	12025: {
 		ID:             12025,
@@ -380,6 +638,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	12026: {
 		ID:             12026,
@@ -390,6 +649,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	12027: {
 		ID:             12027,
@@ -400,6 +660,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	12030: {
 		ID:             12030,
@@ -410,6 +671,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	12031: {
 		ID:             12031,
@@ -420,6 +682,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	12032: {
 		ID:             12032,
@@ -430,6 +693,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	14017: {
 		ID:             14017,
@@ -440,6 +704,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	15001: {
 		ID:             15001,
@@ -450,6 +715,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	15006: {
 		ID:             15006,
@@ -460,6 +726,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	15009: {
 		ID:             15009,
@@ -470,6 +737,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	15010: {
 		ID:             15010,
@@ -480,6 +748,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	15012: {
 		ID:             15012,
@@ -490,6 +759,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	15013: {
 		ID:             15013,
@@ -500,6 +770,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	19047: {
 		ID:             19047,
@@ -510,6 +781,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	19048: {
 		ID:             19048,
@@ -520,6 +792,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	19054: {
 		ID:             19054,
@@ -530,6 +803,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	19056: {
 		ID:             19056,
@@ -540,6 +814,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	19058: {
 		ID:             19058,
@@ -550,6 +825,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	19060: {
 		ID:             19060,
@@ -560,6 +836,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	19061: {
 		ID:             19061,
@@ -570,6 +847,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	19063: {
 		ID:             19063,
@@ -580,6 +858,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	19064: {
 		ID:             19064,
@@ -590,6 +869,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	19066: {
 		ID:             19066,
@@ -600,6 +880,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	19067: {
 		ID:             19067,
@@ -610,6 +891,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	19069: {
 		ID:             19069,
@@ -620,6 +902,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	19070: {
 		ID:             19070,
@@ -630,6 +913,7 @@ var details = map[int]Detail{
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	20034: {
 		ID:             20034,
@@ -648,6 +932,7 @@ GPU (refer to section D.9). If issue persists, report GPU issues.
 		OtherImpact: defaultPotentialFatalErr.OtherImpact + `
 Other Guest VM Impact: No impact if error is confined to a single GPU.
 `,
+		RelatedXids: []int{74},
 	},
 	22012: { // in both D.4 and D.5, treat it as potential fatal
 		ID:             22012,
@@ -668,6 +953,7 @@ Other Guest VM Impact: No impact if error is confined to a single GPU.
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	24005: {
 		ID:             24005,
@@ -678,6 +964,7 @@ Other Guest VM Impact: No impact if error is confined to a single GPU.
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	24006: {
 		ID:             24006,
@@ -688,6 +975,7 @@ Other Guest VM Impact: No impact if error is confined to a single GPU.
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 	24007: {
 		ID:             24007,
@@ -698,6 +986,7 @@ Other Guest VM Impact: No impact if error is confined to a single GPU.
 		Impact:         defaultPotentialFatalErr.Impact,
 		Recovery:       defaultPotentialFatalErr.Recovery,
 		OtherImpact:    defaultPotentialFatalErr.OtherImpact,
+		RelatedXids:    []int{74},
 	},
 
 	// D.6 Always Fatal NVSwitch SXid Errors
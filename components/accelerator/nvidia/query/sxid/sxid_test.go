@@ -0,0 +1,319 @@
+package sxid
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestGetDetailsByName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		wantIDs []int
+	}{
+		{
+			name:    "exact name",
+			input:   "Single bit ECC errors",
+			wantIDs: []int{11012, 11021, 11022, 11023},
+		},
+		{
+			name:    "case-insensitive with surrounding whitespace",
+			input:   "  single BIT ecc ERRORS\t",
+			wantIDs: []int{11012, 11021, 11022, 11023},
+		},
+		{
+			name:    "unique name",
+			input:   "Ingress invalid ACL",
+			wantIDs: []int{11004},
+		},
+		{
+			name:    "no match",
+			input:   "does not exist",
+			wantIDs: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := GetDetailsByName(tt.input)
+			if ok != (len(tt.wantIDs) > 0) {
+				t.Fatalf("GetDetailsByName(%q) found = %v, want %v", tt.input, ok, len(tt.wantIDs) > 0)
+			}
+			if len(tt.wantIDs) == 0 {
+				return
+			}
+			// more SXids share the same name, so only check the ordering and the expected IDs
+			for i := 1; i < len(got); i++ {
+				if got[i-1].ID >= got[i].ID {
+					t.Fatalf("GetDetailsByName(%q) not sorted by ID: %d >= %d", tt.input, got[i-1].ID, got[i].ID)
+				}
+			}
+			found := make(map[int]bool)
+			for _, d := range got {
+				found[d.ID] = true
+			}
+			for _, id := range tt.wantIDs {
+				if !found[id] {
+					t.Errorf("GetDetailsByName(%q) missing ID %d", tt.input, id)
+				}
+			}
+		})
+	}
+}
+
+func TestSeverityOf(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		id        int
+		want      Severity
+		wantFound bool
+	}{
+		{id: 11012, want: SeverityNonFatal, wantFound: true},
+		{id: 11001, want: SeverityPotentialFatal, wantFound: true},
+		{id: 23012, want: SeverityFatal, wantFound: true},
+		{id: 0, want: SeverityInfo, wantFound: false},
+	}
+	for _, tt := range tests {
+		got, found := SeverityOf(tt.id)
+		if got != tt.want || found != tt.wantFound {
+			t.Errorf("SeverityOf(%d) = %v, %v, want %v, %v", tt.id, got, found, tt.want, tt.wantFound)
+		}
+	}
+
+	d := &Detail{PotentialFatal: true, AlwaysFatal: true}
+	if d.Severity() != SeverityFatal {
+		t.Errorf("AlwaysFatal should take precedence, got %v", d.Severity())
+	}
+	var nilDetail *Detail
+	if nilDetail.Severity() != SeverityInfo {
+		t.Errorf("nil detail severity = %v, want %v", nilDetail.Severity(), SeverityInfo)
+	}
+	if SeverityFatal.String() != "fatal" {
+		t.Errorf("SeverityFatal.String() = %q, want %q", SeverityFatal.String(), "fatal")
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	for _, sev := range []Severity{SeverityInfo, SeverityNonFatal, SeverityPotentialFatal, SeverityFatal} {
+		got, err := ParseSeverity(sev.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != sev {
+			t.Errorf("expected %v, got %v", sev, got)
+		}
+	}
+	if _, err := ParseSeverity("critical"); err == nil {
+		t.Fatal("expected error for unknown severity")
+	}
+}
+
+func TestAllDetails(t *testing.T) {
+	t.Parallel()
+
+	all := AllDetails()
+	if len(all) != len(details) {
+		t.Fatalf("AllDetails() returned %d details, want %d", len(all), len(details))
+	}
+	for i := 1; i < len(all); i++ {
+		if all[i-1].ID >= all[i].ID {
+			t.Fatalf("AllDetails() not sorted by ID: %d >= %d", all[i-1].ID, all[i].ID)
+		}
+	}
+
+	// mutating the returned slice must not leak back to the package state
+	orig := all[0]
+	all[0].Name = "mutated"
+	all[0].AlwaysFatal = !orig.AlwaysFatal
+
+	d, ok := GetDetail(orig.ID)
+	if !ok {
+		t.Fatalf("GetDetail(%d) not found", orig.ID)
+	}
+	if d.Name != orig.Name || d.AlwaysFatal != orig.AlwaysFatal {
+		t.Fatalf("GetDetail(%d) = %+v, mutation leaked back (want %+v)", orig.ID, *d, orig)
+	}
+	if again := AllDetails(); again[0].Name != orig.Name {
+		t.Fatalf("AllDetails()[0].Name = %q, want %q", again[0].Name, orig.Name)
+	}
+}
+
+func TestValidateDetails(t *testing.T) {
+	t.Parallel()
+
+	if err := ValidateDetails(); err != nil {
+		t.Fatalf("ValidateDetails() = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		m       map[int]Detail
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			m: map[int]Detail{
+				11004: {ID: 11004, Name: "non-fatal"},
+				12001: {ID: 12001, Name: "potential fatal", PotentialFatal: true},
+				22013: {ID: 22013, Name: "always fatal", PotentialFatal: true, AlwaysFatal: true},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "id does not match the key",
+			m:       map[int]Detail{11004: {ID: 11005, Name: "mismatch"}},
+			wantErr: true,
+		},
+		{
+			name:    "empty name",
+			m:       map[int]Detail{11004: {ID: 11004, Name: " "}},
+			wantErr: true,
+		},
+		{
+			name:    "always fatal without potential fatal",
+			m:       map[int]Detail{22013: {ID: 22013, Name: "always fatal", AlwaysFatal: true}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid id",
+			m:       map[int]Detail{0: {ID: 0, Name: "zero"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateDetails(tt.m)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateDetails() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestXidsForSXid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		id   int
+		want []int
+	}{
+		{id: 11004, want: []int{45}}, // ingress invalid ACL
+		{id: 12028, want: []int{45}},
+		{id: 11001, want: []int{74}}, // potential fatal
+		{id: 20034, want: []int{74}},
+		{id: 11012, want: nil}, // single bit ECC errors
+		{id: 12020, want: nil}, // always fatal
+		{id: 1, want: nil},     // unknown
+	}
+	for _, tt := range tests {
+		if got := XidsForSXid(tt.id); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("XidsForSXid(%d) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+
+	// mutating the returned slice must not leak back to the package state
+	XidsForSXid(11004)[0] = 0
+	d, _ := GetDetail(11004)
+	d.RelatedXids[0] = 0
+	if got := XidsForSXid(11004); !reflect.DeepEqual(got, []int{45}) {
+		t.Fatalf("XidsForSXid(11004) = %v after mutation, want [45]", got)
+	}
+}
+
+// not parallel, as the registered details are shared in the package
+func TestRegisterDetail(t *testing.T) {
+	t.Cleanup(resetOverrides)
+
+	// new SXid not in the built-in details
+	newID := 99999
+	if _, ok := GetDetail(newID); ok {
+		t.Fatalf("sxid %d unexpectedly found", newID)
+	}
+	if err := RegisterDetail(Detail{ID: newID, Name: "New SXid", RelatedXids: []int{74}}); err != nil {
+		t.Fatalf("RegisterDetail() error = %v", err)
+	}
+	d, ok := GetDetail(newID)
+	if !ok || d.Name != "New SXid" {
+		t.Fatalf("GetDetail(%d) = %+v, %v, want the registered detail", newID, d, ok)
+	}
+	if got := XidsForSXid(newID); !reflect.DeepEqual(got, []int{74}) {
+		t.Fatalf("XidsForSXid(%d) = %v, want [74]", newID, got)
+	}
+	if ds, ok := GetDetailsByName("new sxid"); !ok || len(ds) != 1 || ds[0].ID != newID {
+		t.Fatalf("GetDetailsByName() = %+v, %v, want the registered detail", ds, ok)
+	}
+	if got := len(AllDetails()); got != len(details)+1 {
+		t.Fatalf("AllDetails() returned %d details, want %d", got, len(details)+1)
+	}
+
+	// conflicts with the registered detail
+	err := RegisterDetail(Detail{ID: newID, Name: "New SXid again"})
+	if !errors.Is(err, ErrDetailConflict) {
+		t.Fatalf("RegisterDetail() error = %v, want %v", err, ErrDetailConflict)
+	}
+
+	// conflicts with the built-in detail
+	builtin, _ := GetDetail(11004)
+	err = RegisterDetail(Detail{ID: 11004, Name: "Overridden"})
+	if !errors.Is(err, ErrDetailConflict) {
+		t.Fatalf("RegisterDetail() error = %v, want %v", err, ErrDetailConflict)
+	}
+	if d, _ := GetDetail(11004); d.Name != builtin.Name {
+		t.Fatalf("GetDetail(11004).Name = %q, want the built-in %q", d.Name, builtin.Name)
+	}
+
+	// explicit override takes precedence over the built-in detail
+	if err := RegisterDetail(Detail{ID: 11004, Name: "Overridden", Override: true}); err != nil {
+		t.Fatalf("RegisterDetail() error = %v", err)
+	}
+	d, _ = GetDetail(11004)
+	if d.Name != "Overridden" || d.Override {
+		t.Fatalf("GetDetail(11004) = %+v, want the overridden detail", d)
+	}
+	if _, ok := GetDetailsByName(builtin.Name); ok {
+		t.Fatalf("GetDetailsByName(%q) found the overridden built-in detail", builtin.Name)
+	}
+
+	// invalid detail
+	if err := RegisterDetail(Detail{ID: 99998}); err == nil {
+		t.Fatal("RegisterDetail() expected error for the empty name")
+	}
+
+	resetOverrides()
+	if d, _ := GetDetail(11004); d.Name != builtin.Name {
+		t.Fatalf("GetDetail(11004).Name = %q after reset, want %q", d.Name, builtin.Name)
+	}
+}
+
+func TestGetDetails(t *testing.T) {
+	t.Parallel()
+
+	ids := []int{12028, 99998, 20034, 12028, -1}
+	got := GetDetails(ids)
+	if len(got) != 2 {
+		t.Fatalf("GetDetails(%v) returned %d details, want 2", ids, len(got))
+	}
+	for _, id := range []int{12028, 20034} {
+		d, ok := got[id]
+		if !ok {
+			t.Fatalf("GetDetails(%v) missing sxid %d", ids, id)
+		}
+		expected, _ := GetDetail(id)
+		if !reflect.DeepEqual(d, expected) {
+			t.Fatalf("GetDetails(%v)[%d] = %+v, want %+v", ids, id, d, expected)
+		}
+	}
+	for _, id := range []int{99998, -1} {
+		if _, ok := got[id]; ok {
+			t.Fatalf("GetDetails(%v) unexpectedly found sxid %d", ids, id)
+		}
+	}
+
+	if got := GetDetails(nil); len(got) != 0 {
+		t.Fatalf("GetDetails(nil) = %v, want empty", got)
+	}
+}
@@ -0,0 +1,110 @@
+package sxid
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// PortType is the type of the NVSwitch port (NVLink) that the SXid occurred on.
+// ref. https://docs.nvidia.com/datacenter/tesla/pdf/fabric-manager-user-guide.pdf
+type PortType string
+
+const (
+	// The port connects the NVSwitches (e.g., across the baseboards),
+	// so the error may affect the partitions crossing the trunk.
+	PortTypeTrunk PortType = "trunk"
+	// The port connects a GPU to the NVSwitch,
+	// so the error is limited to the corresponding GPU (or guest VM).
+	PortTypeAccess PortType = "access"
+	// The port type cannot be determined
+	// (e.g., no topology configured, no link number in the log line).
+	PortTypeUnknown PortType = "unknown"
+)
+
+// PortRange is the inclusive range of the NVSwitch port (link) numbers.
+type PortRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Returns true if the port is within the range.
+func (r PortRange) Contains(port int) bool {
+	return r.Start <= port && port <= r.End
+}
+
+// Topology is the platform topology hint to classify the NVSwitch ports.
+// The ports not in the trunk ranges are classified as the access ports.
+type Topology struct {
+	TrunkPorts []PortRange `json:"trunk_ports,omitempty"`
+}
+
+// Validates the port ranges.
+func (t *Topology) Validate() error {
+	if t == nil {
+		return nil
+	}
+	var errs []error
+	for _, r := range t.TrunkPorts {
+		if r.Start < 0 || r.End < r.Start {
+			errs = append(errs, fmt.Errorf("invalid trunk port range [%d, %d]", r.Start, r.End))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Returns the port type of the link number.
+// Returns PortTypeUnknown if the topology is not configured
+// or the link number is unknown.
+func (t *Topology) ClassifyPort(linkID *int) PortType {
+	if t == nil || len(t.TrunkPorts) == 0 || linkID == nil {
+		return PortTypeUnknown
+	}
+	for _, r := range t.TrunkPorts {
+		if r.Contains(*linkID) {
+			return PortTypeTrunk
+		}
+	}
+	return PortTypeAccess
+}
+
+// Returns the impact of the error on the given port type,
+// from the impact and the other impact notes.
+// The paragraphs that only apply to the other port type
+// (e.g., "If the error is observed on a Trunk port, ...") are dropped.
+// Returns all the paragraphs for PortTypeUnknown.
+func (d *Detail) ImpactOn(pt PortType) string {
+	if d == nil {
+		return ""
+	}
+
+	paragraphs := make([]string, 0)
+	for _, text := range []string{d.Impact, d.OtherImpact} {
+		for _, p := range strings.Split(text, "\n\n") {
+			p = strings.TrimSpace(p)
+			if p == "" {
+				continue
+			}
+			if pt != PortTypeUnknown && !appliesToPortType(p, pt) {
+				continue
+			}
+			paragraphs = append(paragraphs, p)
+		}
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// Returns false if the paragraph only mentions the other port type.
+func appliesToPortType(paragraph string, pt PortType) bool {
+	lower := strings.ToLower(paragraph)
+	trunk := strings.Contains(lower, "trunk port")
+	access := strings.Contains(lower, "access port")
+	switch pt {
+	case PortTypeTrunk:
+		return trunk || !access
+	case PortTypeAccess:
+		return access || !trunk
+	default:
+		return true
+	}
+}
@@ -0,0 +1,114 @@
+package sxid
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTopologyClassifyPort(t *testing.T) {
+	t.Parallel()
+
+	topo := &Topology{TrunkPorts: []PortRange{{Start: 0, End: 15}, {Start: 48, End: 63}}}
+
+	tests := []struct {
+		name   string
+		topo   *Topology
+		linkID *int
+		want   PortType
+	}{
+		{name: "trunk port", topo: topo, linkID: intPtr(15), want: PortTypeTrunk},
+		{name: "trunk port in the second range", topo: topo, linkID: intPtr(48), want: PortTypeTrunk},
+		{name: "access port", topo: topo, linkID: intPtr(32), want: PortTypeAccess},
+		{name: "no link", topo: topo, linkID: nil, want: PortTypeUnknown},
+		{name: "no topology", topo: nil, linkID: intPtr(32), want: PortTypeUnknown},
+		{name: "no trunk ports", topo: &Topology{}, linkID: intPtr(32), want: PortTypeUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.topo.ClassifyPort(tt.linkID); got != tt.want {
+				t.Errorf("ClassifyPort() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTopologyValidate(t *testing.T) {
+	t.Parallel()
+
+	var nilTopo *Topology
+	if err := nilTopo.Validate(); err != nil {
+		t.Errorf("Validate() on nil topology = %v, want nil", err)
+	}
+	if err := (&Topology{TrunkPorts: []PortRange{{Start: 0, End: 15}}}).Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+	if err := (&Topology{TrunkPorts: []PortRange{{Start: 16, End: 15}, {Start: -1, End: 3}}}).Validate(); err == nil {
+		t.Error("Validate() = nil, want error for the invalid ranges")
+	}
+}
+
+func TestDetailImpactOn(t *testing.T) {
+	t.Parallel()
+
+	d, ok := GetDetail(11001)
+	if !ok {
+		t.Fatal("sxid 11001 not found")
+	}
+
+	trunk := d.ImpactOn(PortTypeTrunk)
+	if !strings.Contains(trunk, "trunk port") || strings.Contains(trunk, "access port") {
+		t.Errorf("ImpactOn(trunk) = %q, want only the trunk port impact", trunk)
+	}
+	access := d.ImpactOn(PortTypeAccess)
+	if !strings.Contains(access, "access port") || strings.Contains(access, "trunk port") {
+		t.Errorf("ImpactOn(access) = %q, want only the access port impact", access)
+	}
+	unknown := d.ImpactOn(PortTypeUnknown)
+	if !strings.Contains(unknown, "trunk port") || !strings.Contains(unknown, "access port") {
+		t.Errorf("ImpactOn(unknown) = %q, want both impacts", unknown)
+	}
+
+	var nilDetail *Detail
+	if got := nilDetail.ImpactOn(PortTypeTrunk); got != "" {
+		t.Errorf("ImpactOn() on nil detail = %q, want empty", got)
+	}
+}
+
+func TestParseDmesgLogLineWithTopology(t *testing.T) {
+	t.Parallel()
+
+	line := "[111111111.111] nvidia-nvswitch3: SXid (PCI:0000:05:00.0): 12028, Non-fatal, Link 32 egress non-posted PRIV error (First)"
+
+	de, err := ParseDmesgLogLine(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if de.PortType != PortTypeUnknown {
+		t.Errorf("PortType = %q, want %q", de.PortType, PortTypeUnknown)
+	}
+	if !strings.Contains(de.PortImpact, "Trunk port") {
+		t.Errorf("PortImpact = %q, want the trunk port note for the unknown port", de.PortImpact)
+	}
+
+	de, err = ParseDmesgLogLineWithTopology(line, &Topology{TrunkPorts: []PortRange{{Start: 0, End: 15}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if de.PortType != PortTypeAccess {
+		t.Errorf("PortType = %q, want %q", de.PortType, PortTypeAccess)
+	}
+	if strings.Contains(de.PortImpact, "Trunk port") {
+		t.Errorf("PortImpact = %q, want no trunk port note for the access port", de.PortImpact)
+	}
+
+	de, err = ParseDmesgLogLineWithTopology(line, &Topology{TrunkPorts: []PortRange{{Start: 32, End: 47}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if de.PortType != PortTypeTrunk {
+		t.Errorf("PortType = %q, want %q", de.PortType, PortTypeTrunk)
+	}
+	if !strings.Contains(de.PortImpact, "Trunk port") {
+		t.Errorf("PortImpact = %q, want the trunk port note", de.PortImpact)
+	}
+}
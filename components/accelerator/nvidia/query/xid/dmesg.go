@@ -35,6 +35,13 @@ func ExtractNVRMXid(line string) int {
 	return 0
 }
 
+// Extracts the nvidia GPU Xid error code from the dmesg log line.
+// Same as ExtractNVRMXid, named after sxid.ExtractNVSwitchSXid for symmetry.
+// Returns 0 if the error code is not found.
+func ExtractNVIDIAXid(line string) int {
+	return ExtractNVRMXid(line)
+}
+
 type DmesgError struct {
 	Detail      *Detail        `json:"detail,omitempty"`
 	DetailFound bool           `json:"detail_found"`
@@ -48,6 +48,9 @@ func TestExtractNVRMXid(t *testing.T) {
 			if result != tt.expected {
 				t.Errorf("ExtractNVRMXid(%q) = %d, want %d", tt.input, result, tt.expected)
 			}
+			if result := ExtractNVIDIAXid(tt.input); result != tt.expected {
+				t.Errorf("ExtractNVIDIAXid(%q) = %d, want %d", tt.input, result, tt.expected)
+			}
 		})
 	}
 }
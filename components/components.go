@@ -60,19 +60,157 @@ type OutputProvider interface {
 	Output() (any, error)
 }
 
+// Defines an optional component interface that supports filtering the metrics,
+// in addition to the Component.Metrics method.
+type MetricsFilterer interface {
+	MetricsFiltered(ctx context.Context, req MetricsRequest) ([]Metric, error)
+}
+
+// MetricsRequest is the request to filter the component metrics.
+type MetricsRequest struct {
+	// Returns the metrics from "since".
+	Since time.Time
+	// Optional GPU ID to scope the metrics to a single GPU
+	// (matched against the metric secondary name).
+	// Empty to return all GPUs.
+	GPUID string
+}
+
 // Defines an optional component interface that supports Prometheus metrics.
 type PromRegisterer interface {
 	RegisterCollectors(reg *prometheus.Registry, db *sql.DB, tableName string) error
 }
 
+// Defines an optional component interface that describes the component
+// (e.g., for the UI to list what the daemon is monitoring).
+// Use Describe to get the description of any component.
+type Describer interface {
+	Describe() ComponentInfo
+}
+
+// ComponentInfo is the metadata of the component.
+type ComponentInfo struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// Returns the description of the component, unwrapping the watchable component if needed.
+// Defaults to the component name only, if the component does not implement Describer.
+func Describe(c Component) ComponentInfo {
+	var v any = c
+	if uw, ok := c.(interface{ Unwrap() interface{} }); ok {
+		v = uw.Unwrap()
+	}
+	if d, ok := v.(Describer); ok {
+		return d.Describe()
+	}
+	return ComponentInfo{Name: c.Name()}
+}
+
+// Defines an optional component interface that tells whether the component
+// has collected its first data (e.g., the poller has produced at least one result),
+// to tell the startup "no data collected yet" from the healthy empty result.
+// Use Ready to check any component.
+type Readier interface {
+	Ready() bool
+}
+
+// Returns true if the component has collected its first data,
+// unwrapping the watchable component if needed.
+// Defaults to true, if the component does not implement Readier (no warm-up).
+func Ready(c Component) bool {
+	var v any = c
+	if uw, ok := c.(interface{ Unwrap() interface{} }); ok {
+		v = uw.Unwrap()
+	}
+	if r, ok := v.(Readier); ok {
+		return r.Ready()
+	}
+	return true
+}
+
+// Defines an optional component interface that stops the new polls
+// and waits for the in-flight ones to finish, before the component is closed,
+// so that the shutdown (e.g., upgrades) does not leave any half-written state.
+// Use Drain to drain any component.
+type Drainer interface {
+	Drain(ctx context.Context) error
+}
+
+// Drains the component, unwrapping the watchable component if needed.
+// No-op if the component does not implement Drainer.
+func Drain(ctx context.Context, c Component) error {
+	var v any = c
+	if uw, ok := c.(interface{ Unwrap() interface{} }); ok {
+		v = uw.Unwrap()
+	}
+	if d, ok := v.(Drainer); ok {
+		return d.Drain(ctx)
+	}
+	return nil
+}
+
 type State struct {
-	Name      string            `json:"name,omitempty"`
-	Healthy   bool              `json:"healthy,omitempty"`
-	Reason    string            `json:"reason,omitempty"`     // a detailed and processed reason on why the component is not healthy
-	Error     string            `json:"error,omitempty"`      // the unprocessed error returned from the component
-	ExtraInfo map[string]string `json:"extra_info,omitempty"` // any extra information the component may want to expose
+	Name    string `json:"name,omitempty"`
+	Healthy bool   `json:"healthy,omitempty"`
+	// Status is the tri-state health status, to tell the degraded states
+	// (e.g., correctable errors) from the failed ones.
+	// Healthy is kept for compatibility, and set to false only for StatusUnhealthy.
+	// Empty if the component does not set it (see HealthStatus).
+	Status     Status            `json:"status,omitempty"`
+	Reason     string            `json:"reason,omitempty"`      // a detailed and processed reason on why the component is not healthy
+	ReasonCode ReasonCode        `json:"reason_code,omitempty"` // a machine-readable code of the reason, stable across the reason wording changes
+	Error      string            `json:"error,omitempty"`       // the unprocessed error returned from the component
+	ExtraInfo  map[string]string `json:"extra_info,omitempty"`  // any extra information the component may want to expose
 }
 
+// Status defines the tri-state health status of the component state.
+type Status string
+
+const (
+	// The component is working as expected.
+	StatusHealthy Status = "healthy"
+	// The component is working, but with the recoverable errors
+	// (e.g., corrected ECC errors, non-fatal SXid errors).
+	StatusDegraded Status = "degraded"
+	// The component is not working as expected
+	// (e.g., uncorrected ECC errors, fatal SXid errors).
+	StatusUnhealthy Status = "unhealthy"
+)
+
+// Returns the tri-state health status of the state,
+// derived from the healthy bool if the component does not set the status.
+func (s State) HealthStatus() Status {
+	if s.Status != "" {
+		return s.Status
+	}
+	if s.Healthy {
+		return StatusHealthy
+	}
+	return StatusUnhealthy
+}
+
+// ReasonCode defines the structured reason code of the component state.
+type ReasonCode string
+
+const (
+	// The last component query failed.
+	ReasonQueryFailed ReasonCode = "query_failed"
+	// The last component query returned no output.
+	ReasonNoOutput ReasonCode = "no_output"
+	// No data has been collected yet.
+	ReasonNoData ReasonCode = "no_data"
+	// The observed value exceeds the threshold (e.g., non-zero error counts).
+	ReasonThresholdExceeded ReasonCode = "threshold_exceeded"
+	// The component versions are incompatible with each other.
+	ReasonVersionMismatch ReasonCode = "version_mismatch"
+	// The nvidia-smi is not installed on the host.
+	ReasonSMIMissing ReasonCode = "smi_missing"
+	// The data fetch did not complete within the deadline.
+	ReasonTimeout ReasonCode = "timeout"
+)
+
 type Event struct {
 	Time      metav1.Time       `json:"time"`
 	Name      string            `json:"name,omitempty"`
@@ -0,0 +1,104 @@
+package components
+
+import (
+	"reflect"
+	"testing"
+)
+
+type describedComponent struct {
+	mockComponent
+}
+
+func (c *describedComponent) Describe() ComponentInfo {
+	return ComponentInfo{Name: c.name, Description: "described", Tags: []string{"gpu"}}
+}
+
+type unwrappable struct {
+	Component
+}
+
+func (u *unwrappable) Unwrap() interface{} {
+	return u.Component
+}
+
+func TestDescribe(t *testing.T) {
+	t.Parallel()
+
+	described := ComponentInfo{Name: "described", Description: "described", Tags: []string{"gpu"}}
+	tests := []struct {
+		name string
+		c    Component
+		want ComponentInfo
+	}{
+		{
+			name: "default to the name",
+			c:    &mockComponent{name: "plain"},
+			want: ComponentInfo{Name: "plain"},
+		},
+		{
+			name: "describer",
+			c:    &describedComponent{mockComponent{name: "described"}},
+			want: described,
+		},
+		{
+			name: "wrapped describer",
+			c:    &unwrappable{&describedComponent{mockComponent{name: "described"}}},
+			want: described,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Describe(tt.c); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Describe() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStateHealthStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		state State
+		want  Status
+	}{
+		{state: State{Healthy: true}, want: StatusHealthy},
+		{state: State{Healthy: false}, want: StatusUnhealthy},
+		{state: State{Healthy: true, Status: StatusDegraded}, want: StatusDegraded},
+		{state: State{Healthy: false, Status: StatusUnhealthy}, want: StatusUnhealthy},
+	}
+	for _, tt := range tests {
+		if got := tt.state.HealthStatus(); got != tt.want {
+			t.Errorf("HealthStatus() of %+v = %q, want %q", tt.state, got, tt.want)
+		}
+	}
+}
+
+type readyComponent struct {
+	mockComponent
+	ready bool
+}
+
+func (c *readyComponent) Ready() bool { return c.ready }
+
+func TestReady(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		c    Component
+		want bool
+	}{
+		{name: "default to ready", c: &mockComponent{name: "plain"}, want: true},
+		{name: "not ready", c: &readyComponent{mockComponent: mockComponent{name: "warming"}}, want: false},
+		{name: "ready", c: &readyComponent{mockComponent: mockComponent{name: "warm"}, ready: true}, want: true},
+		{name: "wrapped not ready", c: &unwrappable{&readyComponent{mockComponent: mockComponent{name: "warming"}}}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Ready(tt.c); got != tt.want {
+				t.Errorf("Ready() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,24 +3,33 @@ package pod
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/leptonai/gpud/components"
 	"github.com/leptonai/gpud/components/query"
 	"github.com/leptonai/gpud/log"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 const Name = "containerd-pod"
 
+const Description = "Tracks the pod sandboxes and their containers in the containerd runtime."
+
+var tags = []string{"container", "containerd", "pod"}
+
 func New(ctx context.Context, cfg Config) components.Component {
-	cfg.Query.SetDefaultsIfNotSet()
+	cfg.SetDefaultsIfNotSet()
 	setDefaultPoller(cfg)
 
 	cctx, ccancel := context.WithCancel(ctx)
 	getDefaultPoller().Start(cctx, cfg.Query, Name)
 
 	return &component{
+		cfg:     cfg,
 		rootCtx: ctx,
 		cancel:  ccancel,
 		poller:  getDefaultPoller(),
@@ -30,15 +39,39 @@ func New(ctx context.Context, cfg Config) components.Component {
 var _ components.Component = (*component)(nil)
 
 type component struct {
-	rootCtx context.Context
-	cancel  context.CancelFunc
-	poller  query.Poller
+	cfg      Config
+	rootCtx  context.Context
+	cancel   context.CancelFunc
+	poller   query.Poller
+	gatherer prometheus.Gatherer
 }
 
 func (c *component) Name() string { return Name }
 
-func (c *component) States(ctx context.Context) ([]components.State, error) {
+func (c *component) Describe() components.ComponentInfo {
+	return components.ComponentInfo{
+		Name:        Name,
+		Description: Description,
+		Tags:        append([]string(nil), tags...),
+	}
+}
+
+var _ components.Readier = (*component)(nil)
+
+// Returns true if the poller has produced at least one result.
+func (c *component) Ready() bool {
+	if c.poller == nil {
+		return false
+	}
 	last, err := c.poller.Last()
+	return err == nil && last != nil
+}
+
+func (c *component) States(ctx context.Context) ([]components.State, error) {
+	last, err := components.FetchWithTimeout(ctx, c.cfg.Query.StatesTimeout.Duration, c.poller.Last)
+	if errors.Is(err, components.ErrFetchTimeout) {
+		return []components.State{components.TimeoutState(Name, err)}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -47,21 +80,34 @@ func (c *component) States(ctx context.Context) ([]components.State, error) {
 		return nil, nil
 	}
 	if last.Error != nil {
+		if errors.Is(last.Error, ErrContainerdUnreachable) {
+			return []components.State{
+				{
+					Name:       Name,
+					Healthy:    false,
+					Error:      last.Error.Error(),
+					Reason:     "containerd socket unreachable",
+					ReasonCode: components.ReasonQueryFailed,
+				},
+			}, nil
+		}
 		return []components.State{
 			{
-				Name:    Name,
-				Healthy: false,
-				Error:   last.Error.Error(),
-				Reason:  "last query failed",
+				Name:       Name,
+				Healthy:    false,
+				Error:      last.Error.Error(),
+				Reason:     "last query failed",
+				ReasonCode: components.ReasonQueryFailed,
 			},
 		}, nil
 	}
 	if last.Output == nil {
 		return []components.State{
 			{
-				Name:    Name,
-				Healthy: false,
-				Reason:  "no output",
+				Name:       Name,
+				Healthy:    false,
+				Reason:     "no output",
+				ReasonCode: components.ReasonNoOutput,
 			},
 		}, nil
 	}
@@ -74,7 +120,12 @@ func (c *component) States(ctx context.Context) ([]components.State, error) {
 }
 
 func (c *component) Events(ctx context.Context, since time.Time) ([]components.Event, error) {
-	return nil, nil
+	// read all the items to use the ones before "since" as the baseline
+	items, err := c.poller.All(time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	return components.CapEvents(createContainerFailedEvents(items, since), c.cfg.MaxEvents), nil
 }
 
 func (c *component) Metrics(ctx context.Context, since time.Time) ([]components.Metric, error) {
@@ -91,3 +142,36 @@ func (c *component) Close() error {
 
 	return nil
 }
+
+var _ components.Drainer = (*component)(nil)
+
+// Stops the new polls and waits for the in-flight poll to finish.
+func (c *component) Drain(ctx context.Context) error {
+	if c.poller == nil {
+		return nil
+	}
+	return c.poller.Drain(ctx)
+}
+
+var _ components.PromRegisterer = (*component)(nil)
+
+func (c *component) RegisterCollectors(reg *prometheus.Registry, db *sql.DB, tableName string) error {
+	c.gatherer = reg
+	return reg.Register(newCollector(c.lastOutput))
+}
+
+// Returns the latest successful output, nil if not available yet.
+func (c *component) lastOutput() (*Output, error) {
+	last, err := c.poller.Last()
+	if err != nil {
+		return nil, err
+	}
+	if last == nil || last.Error != nil || last.Output == nil {
+		return nil, nil
+	}
+	output, ok := last.Output.(*Output)
+	if !ok {
+		return nil, fmt.Errorf("invalid output type: %T", last.Output)
+	}
+	return output, nil
+}
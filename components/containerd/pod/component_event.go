@@ -0,0 +1,82 @@
+package pod
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/components/query"
+)
+
+const (
+	// Emitted when a container newly transitions to the exited state with a non-zero exit code.
+	EventNameContainerFailed = "container_failed"
+
+	EventKeyContainerFailedUnixSeconds   = "unix_seconds"
+	EventKeyContainerFailedPodID         = "pod_id"
+	EventKeyContainerFailedPodNamespace  = "pod_namespace"
+	EventKeyContainerFailedPodName       = "pod_name"
+	EventKeyContainerFailedContainerID   = "container_id"
+	EventKeyContainerFailedContainerName = "container_name"
+	EventKeyContainerFailedExitCode      = "exit_code"
+	EventKeyContainerFailedReason        = "reason"
+	EventKeyContainerFailedMessage       = "message"
+)
+
+// Diffs the successive poller outputs and returns the events for the containers
+// that newly transitioned to the failed state since the given time.
+// The items must be sorted by time (oldest first).
+// The items before "since" are only used as the baseline.
+// A failed container seen for the first time (e.g., after gpud restart) is reported once.
+func createContainerFailedEvents(items []query.Item, since time.Time) []components.Event {
+	evs := make([]components.Event, 0)
+
+	var prev *Output
+	for _, item := range items {
+		// skip the failed queries, to keep the last known baseline
+		if item.Error != nil || item.Output == nil {
+			continue
+		}
+		output, ok := item.Output.(*Output)
+		if !ok {
+			continue
+		}
+
+		changes := DiffOutputs(prev, output)
+		prev = output
+
+		if !since.IsZero() && item.Time.Time.Before(since) {
+			continue
+		}
+		for _, ch := range changes {
+			if ch.Cur == nil || !ch.Cur.Failed() {
+				continue
+			}
+			if ch.Prev != nil && ch.Prev.Failed() {
+				continue
+			}
+
+			c := ch.Cur
+			evs = append(evs, components.Event{
+				Time:    item.Time,
+				Name:    EventNameContainerFailed,
+				Type:    components.EventTypeWarn,
+				Message: fmt.Sprintf("container %q in pod %s/%s exited with code %d", c.Name, ch.PodNamespace, ch.PodName, c.ExitCode),
+				ExtraInfo: map[string]string{
+					EventKeyContainerFailedUnixSeconds:   strconv.FormatInt(item.Time.Unix(), 10),
+					EventKeyContainerFailedPodID:         ch.PodID,
+					EventKeyContainerFailedPodNamespace:  ch.PodNamespace,
+					EventKeyContainerFailedPodName:       ch.PodName,
+					EventKeyContainerFailedContainerID:   c.ID,
+					EventKeyContainerFailedContainerName: c.Name,
+					EventKeyContainerFailedExitCode:      strconv.FormatInt(int64(c.ExitCode), 10),
+					EventKeyContainerFailedReason:        c.Reason,
+					EventKeyContainerFailedMessage:       c.Message,
+				},
+			})
+		}
+	}
+
+	return evs
+}
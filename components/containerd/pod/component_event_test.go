@@ -0,0 +1,71 @@
+package pod
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/leptonai/gpud/components/query"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+func TestCreateContainerFailedEvents(t *testing.T) {
+	t.Parallel()
+
+	rc, ic := newFakeClients(1, 1)
+	images := newImageTagCache(ic)
+
+	// polls the pod status through the fake runtime, as the component does
+	newItem := func(ts time.Time) query.Item {
+		r, err := getSandboxStatus(context.Background(), rc, images, rc.sandboxes[0], 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return query.Item{
+			Time:   metav1.Time{Time: ts},
+			Output: &Output{Pods: []PodSandbox{ConvertToPodSandbox(r)}},
+		}
+	}
+
+	now := time.Now()
+	items := []query.Item{newItem(now.Add(-4 * time.Minute))}
+
+	// "c0" failed, "c1" succeeded
+	rc.containers["sandbox-0000"][0].State = runtimeapi.ContainerState_CONTAINER_EXITED
+	rc.containers["sandbox-0000"][1].State = runtimeapi.ContainerState_CONTAINER_EXITED
+	rc.statuses = map[string]*runtimeapi.ContainerStatus{
+		"sandbox-0000-c0": {Id: "sandbox-0000-c0", State: runtimeapi.ContainerState_CONTAINER_EXITED, ExitCode: 137, Reason: "OOMKilled"},
+		"sandbox-0000-c1": {Id: "sandbox-0000-c1", State: runtimeapi.ContainerState_CONTAINER_EXITED, Reason: "Completed"},
+	}
+	items = append(items,
+		newItem(now.Add(-3*time.Minute)),
+		query.Item{Time: metav1.Time{Time: now.Add(-2 * time.Minute)}, Error: errors.New("query failed")},
+		newItem(now.Add(-time.Minute)),
+	)
+
+	evs := createContainerFailedEvents(items, time.Time{})
+	if len(evs) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(evs))
+	}
+	if evs[0].Name != EventNameContainerFailed {
+		t.Fatalf("expected event name %q, got %q", EventNameContainerFailed, evs[0].Name)
+	}
+	if evs[0].ExtraInfo[EventKeyContainerFailedExitCode] != "137" {
+		t.Fatalf("expected exit code 137, got %q", evs[0].ExtraInfo[EventKeyContainerFailedExitCode])
+	}
+	if evs[0].ExtraInfo[EventKeyContainerFailedContainerName] != "c0" || evs[0].ExtraInfo[EventKeyContainerFailedPodName] != "pod-0" {
+		t.Fatalf("unexpected event %+v", evs[0])
+	}
+	if evs[0].ExtraInfo[EventKeyContainerFailedReason] != "OOMKilled" {
+		t.Fatalf("expected reason OOMKilled, got %q", evs[0].ExtraInfo[EventKeyContainerFailedReason])
+	}
+
+	// the transition happened before "since"
+	evs = createContainerFailedEvents(items, now.Add(-150*time.Second))
+	if len(evs) != 0 {
+		t.Fatalf("expected no event, got %d", len(evs))
+	}
+}
@@ -1,17 +1,30 @@
 package pod
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/leptonai/gpud/components"
 	components_metrics "github.com/leptonai/gpud/components/metrics"
 	"github.com/leptonai/gpud/components/query"
+	"github.com/leptonai/gpud/log"
 
+	"github.com/dustin/go-humanize"
+	"google.golang.org/grpc/codes"
+	grpc_status "google.golang.org/grpc/status"
 	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+	"sigs.k8s.io/yaml"
 )
 
 type Output struct {
@@ -22,6 +35,10 @@ func (o *Output) JSON() ([]byte, error) {
 	return json.Marshal(o)
 }
 
+func (o *Output) YAML() ([]byte, error) {
+	return yaml.Marshal(o)
+}
+
 func ParseOutputJSON(data []byte) (*Output, error) {
 	o := new(Output)
 	if err := json.Unmarshal(data, o); err != nil {
@@ -30,6 +47,14 @@ func ParseOutputJSON(data []byte) (*Output, error) {
 	return o, nil
 }
 
+func ParseOutputYAML(data []byte) (*Output, error) {
+	o := new(Output)
+	if err := yaml.Unmarshal(data, o); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
 const (
 	StateNamePodSandbox = "pod_sandbox"
 
@@ -41,8 +66,14 @@ const (
 	StateKeyPodSandboxData           = "data"
 	StateKeyPodSandboxEncoding       = "encoding"
 	StateValuePodSandboxEncodingJSON = "json"
+	// The JSON data is gzip-compressed and base64-encoded.
+	StateValuePodSandboxEncodingJSONGzip = "json+gzip"
 )
 
+// StateDataCompressThreshold is the size of the JSON state data in bytes,
+// above which the data is gzip-compressed (see StateValuePodSandboxEncodingJSONGzip).
+const StateDataCompressThreshold = 32 * 1024
+
 func ParseStatePodSandbox(m map[string]string) (PodSandbox, error) {
 	pod := PodSandbox{}
 	pod.ID = m[StateKeyPodSandboxID]
@@ -50,26 +81,125 @@ func ParseStatePodSandbox(m map[string]string) (PodSandbox, error) {
 	pod.Namespace = m[StateKeyPodSandboxNamespace]
 	pod.State = m[StateKeyPodSandboxState]
 
-	data := m[StateKeyPodSandboxData]
-	if err := json.Unmarshal([]byte(data), &pod); err != nil {
+	data, err := decodeStateData(m)
+	if err != nil {
+		return PodSandbox{}, err
+	}
+	if err := json.Unmarshal(data, &pod); err != nil {
 		return PodSandbox{}, err
 	}
 	return pod, nil
 }
 
+// Returns the state data and its encoding,
+// compressing the JSON data if larger than StateDataCompressThreshold.
+func encodeStateData(b []byte) (string, string, error) {
+	if len(b) <= StateDataCompressThreshold {
+		return string(b), StateValuePodSandboxEncodingJSON, nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(b); err != nil {
+		return "", "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), StateValuePodSandboxEncodingJSONGzip, nil
+}
+
+// Returns the JSON state data, decompressed based on the encoding key.
+// The data without the encoding key is treated as plain JSON.
+func decodeStateData(m map[string]string) ([]byte, error) {
+	data := m[StateKeyPodSandboxData]
+	switch m[StateKeyPodSandboxEncoding] {
+	case "", StateValuePodSandboxEncodingJSON:
+		return []byte(data), nil
+
+	case StateValuePodSandboxEncodingJSONGzip:
+		compressed, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return nil, err
+		}
+		zr, err := gzip.NewReader(bytes.NewReader(compressed))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		return io.ReadAll(zr)
+
+	default:
+		return nil, fmt.Errorf("unknown state data encoding: %s", m[StateKeyPodSandboxEncoding])
+	}
+}
+
+// Returns the number of pods with any container
+// that exited with a non-zero exit code.
+func (o *Output) CountPodsWithFailedContainers() int {
+	cnt := 0
+	for _, pod := range o.Pods {
+		for _, c := range pod.Containers {
+			if c.Failed() {
+				cnt++
+				break
+			}
+		}
+	}
+	return cnt
+}
+
+// Returns the number of pods whose status cannot be fetched
+// (see PodSandbox.Error).
+func (o *Output) CountPodsWithErrors() int {
+	cnt := 0
+	for _, pod := range o.Pods {
+		if pod.Error != "" {
+			cnt++
+		}
+	}
+	return cnt
+}
+
 func (o *Output) describeReason() string {
-	return fmt.Sprintf("total %d pod sandboxes", len(o.Pods))
+	reason := o.describePods()
+	if errored := o.CountPodsWithErrors(); errored > 0 {
+		reason += fmt.Sprintf(" (%d pods failed to get the status)", errored)
+	}
+	return reason
+}
+
+func (o *Output) describePods() string {
+	if failed := o.CountPodsWithFailedContainers(); failed > 0 {
+		return fmt.Sprintf("%d of %d pods have failed containers", failed, len(o.Pods))
+	}
+
+	var oldest time.Duration
+	for _, pod := range o.Pods {
+		if uptime := pod.Uptime(); uptime > oldest {
+			oldest = uptime
+		}
+	}
+	if oldest == 0 {
+		return fmt.Sprintf("total %d pod sandboxes", len(o.Pods))
+	}
+	now := time.Now()
+	return fmt.Sprintf("total %d pod sandboxes (oldest pod created %s)", len(o.Pods), humanize.RelTime(now.Add(-oldest), now, "ago", "from now"))
 }
 
 func (o *Output) States() ([]components.State, error) {
 	b, _ := o.JSON()
+	data, encoding, err := encodeStateData(b)
+	if err != nil {
+		return nil, err
+	}
 	return []components.State{{
 		Name:    StateNamePodSandbox,
-		Healthy: true,
+		Healthy: o.CountPodsWithFailedContainers() == 0,
 		Reason:  o.describeReason(),
 		ExtraInfo: map[string]string{
-			StateKeyPodSandboxData:     string(b),
-			StateKeyPodSandboxEncoding: StateValuePodSandboxEncodingJSON,
+			StateKeyPodSandboxData:     data,
+			StateKeyPodSandboxEncoding: encoding,
 		},
 	}}, nil
 }
@@ -79,11 +209,16 @@ func ParseStatesToOutput(states ...components.State) (*Output, error) {
 	for _, state := range states {
 		switch state.Name {
 		case StateNamePodSandbox:
-			pod, err := ParseStatePodSandbox(state.ExtraInfo)
+			// the state data is the whole output (see "States")
+			data, err := decodeStateData(state.ExtraInfo)
 			if err != nil {
 				return nil, err
 			}
-			o.Pods = append(o.Pods, pod)
+			parsed, err := ParseOutputJSON(data)
+			if err != nil {
+				return nil, err
+			}
+			o.Pods = append(o.Pods, parsed.Pods...)
 
 		default:
 			return nil, fmt.Errorf("unknown state name: %s", state.Name)
@@ -110,7 +245,9 @@ func getDefaultPoller() query.Poller {
 
 func CreateGet(cfg Config) query.GetFunc {
 	return func(ctx context.Context) (_ any, e error) {
+		start := time.Now()
 		defer func() {
+			components_metrics.SetGetLatency(Name, time.Since(start))
 			if e != nil {
 				components_metrics.SetGetFailed(Name)
 			} else {
@@ -118,10 +255,21 @@ func CreateGet(cfg Config) query.GetFunc {
 			}
 		}()
 
-		ss, err := ListSandboxStatus(ctx, cfg.Endpoint)
+		// tolerate the containerd that is briefly unavailable (e.g., at node boot)
+		client, imageClient, conn, err := ConnectWithRetry(ctx, cfg.Endpoint, cfg.DialTimeout.Duration, cfg.ConnectRetries, cfg.ConnectRetryBaseDelay.Duration)
 		if err != nil {
 			return nil, err
 		}
+		defer conn.Close()
+
+		ss, _, err := listSandboxStatus(ctx, client, imageClient, ListOptions{Namespace: cfg.Namespace, PodTimeout: cfg.PodStatusTimeout.Duration})
+		if err != nil {
+			if !errors.Is(err, ErrPartialPodStatus) {
+				return nil, err
+			}
+			// keep the output of the other pods (see PodSandbox.Error)
+			log.Logger.Warnw("failed to get the status of some pod sandboxes", "error", err)
+		}
 		pods := make([]PodSandbox, 0)
 		for _, s := range ss {
 			pods = append(pods, ConvertToPodSandbox(s))
@@ -135,99 +283,324 @@ const (
 	DefaultContainerRuntimeEndpoint = "unix:///run/containerd/containerd.sock"
 )
 
-func ListSandboxStatus(ctx context.Context, endpoint string) ([]*runtimeapi.PodSandboxStatusResponse, error) {
-	client, imageClient, conn, err := Connect(ctx, endpoint)
+// Set by the kubelet on every pod sandbox.
+// ref. https://github.com/kubernetes/kubernetes/blob/v1.31.0/pkg/kubelet/types/labels.go
+const LabelKeyPodNamespace = "io.kubernetes.pod.namespace"
+
+// ListOptions is the options for listing the pod sandboxes.
+type ListOptions struct {
+	// Namespace to list the pods from.
+	// If empty, lists the pods from all namespaces.
+	Namespace string `json:"namespace,omitempty"`
+	// PageSize is the maximum number of pod sandboxes to return in one page.
+	// If zero or negative, returns all the pod sandboxes in one page.
+	PageSize int `json:"page_size,omitempty"`
+	// Continue is the continuation token returned by the previous page.
+	// If empty, lists from the first pod sandbox.
+	Continue string `json:"continue,omitempty"`
+	// PodTimeout is the timeout to get the status, the containers, and the images
+	// of each pod sandbox, so that a wedged pod does not block the whole list.
+	// Zero uses DefaultPodStatusTimeout, and negative disables the per-pod timeout.
+	PodTimeout time.Duration `json:"pod_timeout,omitempty"`
+}
+
+// DefaultPodStatusTimeout is the default timeout to get the status of each pod sandbox.
+const DefaultPodStatusTimeout = 5 * time.Second
+
+// ErrPartialPodStatus is returned with the pod sandboxes listed,
+// when the status of some pod sandboxes cannot be fetched (e.g., timed out).
+// The failed pod sandboxes are returned with the error marker
+// in the info (see InfoKeyError and PodSandbox.Error).
+var ErrPartialPodStatus = errors.New("failed to get the status of some pod sandboxes")
+
+// InfoKeyError is the pod sandbox info key of the error marker,
+// set when the pod sandbox status cannot be fetched.
+const InfoKeyError = "gpud_error"
+
+// Lists the pod sandboxes and their containers from the container runtime.
+// If the namespace is empty, lists the pods from all namespaces.
+func ListSandboxStatus(ctx context.Context, endpoint string, namespace string, dialTimeout time.Duration) ([]*runtimeapi.PodSandboxStatusResponse, error) {
+	client, imageClient, conn, err := Connect(ctx, endpoint, dialTimeout)
 	if err != nil {
 		return nil, err
 	}
 	defer conn.Close()
 
-	resp, err := client.ListPodSandbox(ctx, &runtimeapi.ListPodSandboxRequest{Filter: &runtimeapi.PodSandboxFilter{}})
+	rs, _, err := listSandboxStatus(ctx, client, imageClient, ListOptions{Namespace: namespace})
+	return rs, err
+}
+
+// Lists one page of the pod sandboxes and their containers from the container runtime.
+// Returns the continuation token for the next page, which is empty if there are no more pods.
+// The CRI does not support pagination, so the sandboxes are listed once and sorted by their IDs,
+// but the status and container lookups are only made for the sandboxes in the requested page.
+func ListSandboxStatusPage(ctx context.Context, endpoint string, dialTimeout time.Duration, opts ListOptions) ([]*runtimeapi.PodSandboxStatusResponse, string, error) {
+	client, imageClient, conn, err := Connect(ctx, endpoint, dialTimeout)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+	defer conn.Close()
+
+	return listSandboxStatus(ctx, client, imageClient, opts)
+}
+
+func listSandboxStatus(ctx context.Context, client runtimeapi.RuntimeServiceClient, imageClient runtimeapi.ImageServiceClient, opts ListOptions) ([]*runtimeapi.PodSandboxStatusResponse, string, error) {
+	filter := &runtimeapi.PodSandboxFilter{}
+	if opts.Namespace != "" {
+		filter.LabelSelector = map[string]string{LabelKeyPodNamespace: opts.Namespace}
 	}
-	rs := make([]*runtimeapi.PodSandboxStatusResponse, 0, len(resp.Items))
+	resp, err := client.ListPodSandbox(ctx, &runtimeapi.ListPodSandboxRequest{Filter: filter})
+	if err != nil {
+		return nil, "", err
+	}
+
+	sandboxes := make([]*runtimeapi.PodSandbox, 0, len(resp.Items))
 	for _, sandbox := range resp.Items {
-		r, err := client.PodSandboxStatus(
-			ctx,
-			&runtimeapi.PodSandboxStatusRequest{
-				PodSandboxId: sandbox.Id,
-
-				// extra info such as process info (not that useful)
-				// e.g., "overlayfs\",\"runtimeHandler\":\"\",\"runtimeType\":\"io.containerd.runc.v2\",\"runtimeOptions
-				Verbose: false,
-			},
-		)
+		// in case the runtime does not set the namespace label
+		if opts.Namespace != "" && sandbox.Metadata != nil && sandbox.Metadata.Namespace != opts.Namespace {
+			continue
+		}
+		sandboxes = append(sandboxes, sandbox)
+	}
+	page, next := paginateSandboxes(sandboxes, opts.PageSize, opts.Continue)
+
+	// pods often share the same image, so resolve each image only once
+	images := newImageTagCache(imageClient)
+
+	rs := make([]*runtimeapi.PodSandboxStatusResponse, 0, len(page))
+	errs := make([]error, 0)
+	for _, sandbox := range page {
+		r, err := getSandboxStatus(ctx, client, images, sandbox, opts.PodTimeout)
 		if err != nil {
-			return nil, err
+			if ctx.Err() != nil {
+				// the whole list is canceled, not the pod
+				return nil, "", ctx.Err()
+			}
+			errs = append(errs, fmt.Errorf("pod sandbox %q: %w", sandbox.Id, err))
+			r = newErrorSandboxStatus(sandbox, err)
 		}
 		rs = append(rs, r)
-		response, err := client.ListContainers(ctx, &runtimeapi.ListContainersRequest{
-			Filter: &runtimeapi.ContainerFilter{
-				PodSandboxId: sandbox.Id,
-			},
-		})
+	}
+	if len(errs) > 0 {
+		return rs, next, fmt.Errorf("%w (%d of %d): %w", ErrPartialPodStatus, len(errs), len(page), errors.Join(errs...))
+	}
+
+	return rs, next, nil
+}
+
+// Returns the status of the pod sandbox and its containers,
+// bounded by the timeout (zero uses DefaultPodStatusTimeout, negative for no timeout).
+func getSandboxStatus(ctx context.Context, client runtimeapi.RuntimeServiceClient, images *imageTagCache, sandbox *runtimeapi.PodSandbox, timeout time.Duration) (*runtimeapi.PodSandboxStatusResponse, error) {
+	if timeout == 0 {
+		timeout = DefaultPodStatusTimeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	r, err := client.PodSandboxStatus(
+		ctx,
+		&runtimeapi.PodSandboxStatusRequest{
+			PodSandboxId: sandbox.Id,
+
+			// extra info such as process info (not that useful)
+			// e.g., "overlayfs\",\"runtimeHandler\":\"\",\"runtimeType\":\"io.containerd.runc.v2\",\"runtimeOptions
+			Verbose: false,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	response, err := client.ListContainers(ctx, &runtimeapi.ListContainersRequest{
+		Filter: &runtimeapi.ContainerFilter{
+			PodSandboxId: sandbox.Id,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range response.Containers {
+		// the failed image lookups (e.g., timed out) are not retried for the other pods
+		if tag, ok := images.resolve(ctx, c.ImageRef); ok && c.Image != nil {
+			c.Image.UserSpecifiedImage = tag
+		}
+		cs := &runtimeapi.ContainerStatus{
+			Id:          c.Id,
+			Metadata:    c.Metadata,
+			State:       c.State,
+			CreatedAt:   c.CreatedAt,
+			Image:       c.Image,
+			ImageRef:    c.ImageRef,
+			Labels:      c.Labels,
+			Annotations: c.Annotations,
+			ImageId:     c.ImageId,
+		}
+
+		// the listed container has no exit code or reason,
+		// only returned by the container status
+		status, err := client.ContainerStatus(ctx, &runtimeapi.ContainerStatusRequest{ContainerId: c.Id})
 		if err != nil {
+			// removed after the list
+			if grpc_status.Code(err) == codes.NotFound {
+				continue
+			}
 			return nil, err
 		}
-		for _, c := range response.Containers {
-			image := c.Image
-			if imageStatus, err := imageClient.ImageStatus(ctx, &runtimeapi.ImageStatusRequest{
-				Image: &runtimeapi.ImageSpec{
-					Image:       c.ImageRef,
-					Annotations: nil,
-				},
-				Verbose: false,
-			}); err == nil && imageStatus.Image != nil {
-				if len(imageStatus.Image.RepoTags) > 0 {
-					image.UserSpecifiedImage = strings.Join(imageStatus.Image.RepoTags, ",")
-				} else {
-					image.UserSpecifiedImage = strings.Join(imageStatus.Image.RepoDigests, ",")
-				}
-			}
-			r.ContainersStatuses = append(r.ContainersStatuses, &runtimeapi.ContainerStatus{
-				Id:          c.Id,
-				Metadata:    c.Metadata,
-				State:       c.State,
-				CreatedAt:   c.CreatedAt,
-				Image:       c.Image,
-				ImageRef:    c.ImageRef,
-				Labels:      c.Labels,
-				Annotations: c.Annotations,
-				ImageId:     c.ImageId,
-			})
+		if status.Status != nil {
+			cs.StartedAt = status.Status.StartedAt
+			cs.FinishedAt = status.Status.FinishedAt
+			cs.ExitCode = status.Status.ExitCode
+			cs.Reason = status.Status.Reason
+			cs.Message = status.Status.Message
+			cs.LogPath = status.Status.LogPath
+		}
+
+		r.ContainersStatuses = append(r.ContainersStatuses, cs)
+	}
+	return r, nil
+}
+
+// Returns the pod sandbox status from the listed pod sandbox,
+// with the error marker in the info, for the pod whose status cannot be fetched.
+func newErrorSandboxStatus(sandbox *runtimeapi.PodSandbox, err error) *runtimeapi.PodSandboxStatusResponse {
+	return &runtimeapi.PodSandboxStatusResponse{
+		Status: &runtimeapi.PodSandboxStatus{
+			Id:          sandbox.Id,
+			Metadata:    sandbox.Metadata,
+			State:       sandbox.State,
+			CreatedAt:   sandbox.CreatedAt,
+			Labels:      sandbox.Labels,
+			Annotations: sandbox.Annotations,
+		},
+		Info: map[string]string{InfoKeyError: err.Error()},
+	}
+}
+
+// imageTagCache memoizes the image status lookups by the image reference.
+// Not safe for concurrent use.
+type imageTagCache struct {
+	client runtimeapi.ImageServiceClient
+	tags   map[string]imageTag
+}
+
+type imageTag struct {
+	tag string
+	ok  bool
+}
+
+func newImageTagCache(client runtimeapi.ImageServiceClient) *imageTagCache {
+	return &imageTagCache{
+		client: client,
+		tags:   make(map[string]imageTag),
+	}
+}
+
+// Returns the user specified image (repo tags, or repo digests if no tag) for the image reference.
+// Returns false if the image status is not found.
+// Failed lookups are cached as well, so each image reference is looked up at most once.
+func (c *imageTagCache) resolve(ctx context.Context, imageRef string) (string, bool) {
+	if t, ok := c.tags[imageRef]; ok {
+		return t.tag, t.ok
+	}
 
+	t := imageTag{}
+	imageStatus, err := c.client.ImageStatus(ctx, &runtimeapi.ImageStatusRequest{
+		Image: &runtimeapi.ImageSpec{
+			Image:       imageRef,
+			Annotations: nil,
+		},
+		Verbose: false,
+	})
+	if err == nil && imageStatus.Image != nil {
+		t.ok = true
+		if len(imageStatus.Image.RepoTags) > 0 {
+			t.tag = strings.Join(imageStatus.Image.RepoTags, ",")
+		} else {
+			t.tag = strings.Join(imageStatus.Image.RepoDigests, ",")
 		}
 	}
-	return rs, nil
+	c.tags[imageRef] = t
+
+	return t.tag, t.ok
+}
+
+// Returns the page of the sandboxes after the continuation token (sandbox ID),
+// and the continuation token for the next page.
+func paginateSandboxes(sandboxes []*runtimeapi.PodSandbox, pageSize int, continueToken string) ([]*runtimeapi.PodSandbox, string) {
+	sort.SliceStable(sandboxes, func(i, j int) bool {
+		return sandboxes[i].Id < sandboxes[j].Id
+	})
+
+	start := 0
+	if continueToken != "" {
+		start = sort.Search(len(sandboxes), func(i int) bool {
+			return sandboxes[i].Id > continueToken
+		})
+	}
+	if pageSize <= 0 || start+pageSize >= len(sandboxes) {
+		return sandboxes[start:], ""
+	}
+
+	end := start + pageSize
+	return sandboxes[start:end], sandboxes[end-1].Id
 }
 
 // the original "PodSandboxStatusResponse" has a lot of fields, we only need a few of them
 func ConvertToPodSandbox(resp *runtimeapi.PodSandboxStatusResponse) PodSandbox {
 	status := resp.GetStatus()
 	pod := PodSandbox{
-		ID:        status.Id,
-		Name:      status.Metadata.Name,
-		Namespace: status.Metadata.Namespace,
-		State:     status.State.String(),
+		ID:        status.GetId(),
+		Name:      status.GetMetadata().GetName(),
+		Namespace: status.GetMetadata().GetNamespace(),
+		State:     status.GetState().String(),
+		CreatedAt: status.GetCreatedAt(),
 		Info:      resp.GetInfo(),
 	}
+	if msg, ok := pod.Info[InfoKeyError]; ok {
+		pod.Error = msg
+		pod.Info = nil
+		if len(resp.Info) > 1 {
+			pod.Info = make(map[string]string, len(resp.Info)-1)
+			for k, v := range resp.Info {
+				if k != InfoKeyError {
+					pod.Info[k] = v
+				}
+			}
+		}
+	}
 	for _, c := range resp.ContainersStatuses {
 		pod.Containers = append(pod.Containers, convertContainerStatus(c))
 	}
 	return pod
 }
 
+const (
+	// Set by the kubelet to track the number of container restarts.
+	// ref. https://github.com/kubernetes/kubernetes/blob/v1.31.0/pkg/kubelet/kuberuntime/labels.go
+	AnnotationKeyContainerRestartCount = "io.kubernetes.container.restartCount"
+
+	// Optional readiness annotation, if set by the runtime or the workload.
+	// The container is not ready if set to a value other than "true".
+	AnnotationKeyContainerReady = "io.kubernetes.container.ready"
+)
+
 func convertContainerStatus(c *runtimeapi.ContainerStatus) PodSandboxContainerStatus {
 	ret := PodSandboxContainerStatus{
-		ID:        c.Id,
-		Name:      c.Metadata.Name,
-		CreatedAt: c.CreatedAt,
-		State:     c.State.String(),
-		LogPath:   c.LogPath,
-		ExitCode:  c.ExitCode,
-		Reason:    c.Reason,
-		Message:   c.Message,
+		ID:           c.Id,
+		Name:         c.Metadata.Name,
+		CreatedAt:    c.CreatedAt,
+		State:        c.State.String(),
+		LogPath:      c.LogPath,
+		FinishedAt:   c.FinishedAt,
+		ExitCode:     c.ExitCode,
+		Reason:       c.Reason,
+		Message:      c.Message,
+		ImageRef:     c.ImageRef,
+		ImageID:      c.ImageId,
+		RestartCount: parseContainerRestartCount(c.Annotations),
+		Ready:        isContainerReady(c.State, c.Annotations),
 	}
 	if c.Image != nil {
 		ret.Image = c.Image.UserSpecifiedImage
@@ -235,6 +608,30 @@ func convertContainerStatus(c *runtimeapi.ContainerStatus) PodSandboxContainerSt
 	return ret
 }
 
+// Returns 0 if the restart count annotation is not found or invalid.
+func parseContainerRestartCount(annotations map[string]string) int32 {
+	v, ok := annotations[AnnotationKeyContainerRestartCount]
+	if !ok {
+		return 0
+	}
+	cnt, err := strconv.ParseInt(v, 10, 32)
+	if err != nil {
+		return 0
+	}
+	return int32(cnt)
+}
+
+// The container is ready if it is running and not marked as not ready by the annotation.
+func isContainerReady(state runtimeapi.ContainerState, annotations map[string]string) bool {
+	if state != runtimeapi.ContainerState_CONTAINER_RUNNING {
+		return false
+	}
+	if v, ok := annotations[AnnotationKeyContainerReady]; ok {
+		return v == "true"
+	}
+	return true
+}
+
 // PodSandbox represents the pod information fetched from the local container runtime.
 // Simplified version of k8s.io/cri-api/pkg/apis/runtime/v1.PodSandbox.
 // ref. https://pkg.go.dev/k8s.io/cri-api/pkg/apis/runtime/v1#ListPodSandboxResponse
@@ -245,21 +642,54 @@ type PodSandbox struct {
 	State      string                      `json:"state,omitempty"`
 	Info       map[string]string           `json:"info,omitempty"`
 	Containers []PodSandboxContainerStatus `json:"containers,omitempty"`
+
+	// Creation time of the pod sandbox in nanoseconds since the Unix epoch.
+	CreatedAt int64 `json:"created_at,omitempty"`
+
+	// Error is set if the pod sandbox status cannot be fetched (e.g., timed out),
+	// in which case the containers are unknown.
+	Error string `json:"error,omitempty"`
 }
 
 func (s PodSandbox) JSON() ([]byte, error) {
 	return json.Marshal(s)
 }
 
+// Returns the duration since the pod sandbox was created.
+// Returns zero if the creation time is unknown.
+func (s PodSandbox) Uptime() time.Duration {
+	if s.CreatedAt <= 0 {
+		return 0
+	}
+	uptime := time.Since(time.Unix(0, s.CreatedAt))
+	if uptime < 0 {
+		return 0
+	}
+	return uptime
+}
+
 // ref. https://pkg.go.dev/k8s.io/cri-api/pkg/apis/runtime/v1#ContainerStatus
 type PodSandboxContainerStatus struct {
-	ID        string `json:"id,omitempty"`
-	Name      string `json:"name,omitempty"`
-	Image     string `json:"image,omitempty"`
+	ID    string `json:"id,omitempty"`
+	Name  string `json:"name,omitempty"`
+	Image string `json:"image,omitempty"`
+	// Immutable image reference (e.g., digest) of the running image.
+	ImageRef  string `json:"imageRef,omitempty"`
+	ImageID   string `json:"imageId,omitempty"`
 	CreatedAt int64  `json:"created_at,omitempty"`
 	State     string `json:"state,omitempty"`
 	LogPath   string `json:"logPath,omitempty"`
-	ExitCode  int32  `json:"exitCode,omitempty"`
-	Reason    string `json:"reason,omitempty"`
-	Message   string `json:"message,omitempty"`
+	// Finish time of the exited container in nanoseconds since the Unix epoch.
+	FinishedAt int64  `json:"finished_at,omitempty"`
+	ExitCode   int32  `json:"exitCode,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	Message    string `json:"message,omitempty"`
+
+	RestartCount int32 `json:"restartCount,omitempty"`
+	Ready        bool  `json:"ready,omitempty"`
+}
+
+// Returns true if the container exited with a non-zero exit code.
+func (c PodSandboxContainerStatus) Failed() bool {
+	return c.State == runtimeapi.ContainerState_CONTAINER_EXITED.String() && c.ExitCode != 0
 }
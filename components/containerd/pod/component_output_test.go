@@ -0,0 +1,299 @@
+package pod
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leptonai/gpud/components"
+
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+func TestConvertContainerStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		status           *runtimeapi.ContainerStatus
+		wantRestartCount int32
+		wantReady        bool
+	}{
+		{
+			name: "running without annotations",
+			status: &runtimeapi.ContainerStatus{
+				Metadata: &runtimeapi.ContainerMetadata{Name: "c1"},
+				State:    runtimeapi.ContainerState_CONTAINER_RUNNING,
+			},
+			wantRestartCount: 0,
+			wantReady:        true,
+		},
+		{
+			name: "running with restarts",
+			status: &runtimeapi.ContainerStatus{
+				Metadata:    &runtimeapi.ContainerMetadata{Name: "c1"},
+				State:       runtimeapi.ContainerState_CONTAINER_RUNNING,
+				Annotations: map[string]string{AnnotationKeyContainerRestartCount: "5"},
+			},
+			wantRestartCount: 5,
+			wantReady:        true,
+		},
+		{
+			name: "running but not ready",
+			status: &runtimeapi.ContainerStatus{
+				Metadata:    &runtimeapi.ContainerMetadata{Name: "c1"},
+				State:       runtimeapi.ContainerState_CONTAINER_RUNNING,
+				Annotations: map[string]string{AnnotationKeyContainerReady: "false"},
+			},
+			wantRestartCount: 0,
+			wantReady:        false,
+		},
+		{
+			name: "exited with invalid restart count",
+			status: &runtimeapi.ContainerStatus{
+				Metadata:    &runtimeapi.ContainerMetadata{Name: "c1"},
+				State:       runtimeapi.ContainerState_CONTAINER_EXITED,
+				ExitCode:    1,
+				Annotations: map[string]string{AnnotationKeyContainerRestartCount: "abc"},
+			},
+			wantRestartCount: 0,
+			wantReady:        false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := convertContainerStatus(tt.status)
+			if got.RestartCount != tt.wantRestartCount {
+				t.Errorf("RestartCount = %d, want %d", got.RestartCount, tt.wantRestartCount)
+			}
+			if got.Ready != tt.wantReady {
+				t.Errorf("Ready = %v, want %v", got.Ready, tt.wantReady)
+			}
+		})
+	}
+}
+
+func TestParseStatesToOutput(t *testing.T) {
+	t.Parallel()
+
+	o := &Output{
+		Pods: []PodSandbox{
+			{
+				ID:        "pod1",
+				Namespace: "default",
+				Name:      "gpu-job",
+				State:     runtimeapi.PodSandboxState_SANDBOX_READY.String(),
+				Containers: []PodSandboxContainerStatus{
+					{
+						ID:       "c1",
+						Name:     "main",
+						Image:    "nvcr.io/nvidia/cuda:12.4.0-base-ubuntu22.04",
+						ImageRef: "nvcr.io/nvidia/cuda@sha256:0123456789abcdef",
+						ImageID:  "sha256:fedcba9876543210",
+						State:    runtimeapi.ContainerState_CONTAINER_RUNNING.String(),
+						Ready:    true,
+					},
+				},
+			},
+		},
+	}
+
+	states, err := o.States()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseStatesToOutput(states...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(o, parsed) {
+		t.Fatalf("expected %+v, got %+v", o, parsed)
+	}
+
+	b, err := o.Pods[0].JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pod, err := ParseStatePodSandbox(map[string]string{StateKeyPodSandboxData: string(b)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pod.Containers[0].ImageRef != o.Pods[0].Containers[0].ImageRef {
+		t.Fatalf("expected image ref %q, got %q", o.Pods[0].Containers[0].ImageRef, pod.Containers[0].ImageRef)
+	}
+	if pod.Containers[0].ImageID != o.Pods[0].Containers[0].ImageID {
+		t.Fatalf("expected image id %q, got %q", o.Pods[0].Containers[0].ImageID, pod.Containers[0].ImageID)
+	}
+}
+
+func TestPodSandboxUptime(t *testing.T) {
+	t.Parallel()
+
+	if uptime := (PodSandbox{}).Uptime(); uptime != 0 {
+		t.Fatalf("expected zero uptime for unknown creation time, got %v", uptime)
+	}
+
+	pod := PodSandbox{CreatedAt: time.Now().Add(-time.Hour).UnixNano()}
+	if uptime := pod.Uptime(); uptime < time.Hour || uptime > time.Hour+time.Minute {
+		t.Fatalf("expected ~1h uptime, got %v", uptime)
+	}
+
+	o := &Output{Pods: []PodSandbox{pod, {}}}
+	if reason := o.describeReason(); !strings.Contains(reason, "oldest pod created 1 hour ago") {
+		t.Fatalf("unexpected reason %q", reason)
+	}
+}
+
+func TestOutputStatesWithFailedContainers(t *testing.T) {
+	t.Parallel()
+
+	running := PodSandboxContainerStatus{ID: "c1", State: runtimeapi.ContainerState_CONTAINER_RUNNING.String()}
+	succeeded := PodSandboxContainerStatus{ID: "c2", State: runtimeapi.ContainerState_CONTAINER_EXITED.String()}
+	failed := PodSandboxContainerStatus{ID: "c3", State: runtimeapi.ContainerState_CONTAINER_EXITED.String(), ExitCode: 1}
+
+	tests := []struct {
+		name        string
+		output      *Output
+		wantFailed  int
+		wantHealthy bool
+		wantReason  string
+	}{
+		{
+			name: "all healthy",
+			output: &Output{Pods: []PodSandbox{
+				{ID: "p1", Containers: []PodSandboxContainerStatus{running, succeeded}},
+				{ID: "p2", Containers: []PodSandboxContainerStatus{running}},
+			}},
+			wantFailed:  0,
+			wantHealthy: true,
+			wantReason:  "total 2 pod sandboxes",
+		},
+		{
+			name: "mixed",
+			output: &Output{Pods: []PodSandbox{
+				{ID: "p1", Containers: []PodSandboxContainerStatus{running, failed}},
+				{ID: "p2", Containers: []PodSandboxContainerStatus{running}},
+				{ID: "p3", Containers: []PodSandboxContainerStatus{failed, failed}},
+			}},
+			wantFailed:  2,
+			wantHealthy: false,
+			wantReason:  "2 of 3 pods have failed containers",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.output.CountPodsWithFailedContainers(); got != tt.wantFailed {
+				t.Fatalf("expected %d pods with failed containers, got %d", tt.wantFailed, got)
+			}
+			states, err := tt.output.States()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(states) != 1 {
+				t.Fatalf("expected 1 state, got %d", len(states))
+			}
+			if states[0].Healthy != tt.wantHealthy {
+				t.Fatalf("expected healthy %v, got %v", tt.wantHealthy, states[0].Healthy)
+			}
+			if states[0].Reason != tt.wantReason {
+				t.Fatalf("expected reason %q, got %q", tt.wantReason, states[0].Reason)
+			}
+		})
+	}
+}
+
+func TestOutputStatesCompression(t *testing.T) {
+	t.Parallel()
+
+	small := &Output{Pods: []PodSandbox{{ID: "id-0", Name: "pod-0", Namespace: "default"}}}
+	states, err := small.States()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enc := states[0].ExtraInfo[StateKeyPodSandboxEncoding]; enc != StateValuePodSandboxEncodingJSON {
+		t.Fatalf("expected plain json for small payloads, got %q", enc)
+	}
+
+	large := &Output{}
+	for i := 0; i < 1000; i++ {
+		large.Pods = append(large.Pods, PodSandbox{
+			ID:        strings.Repeat("a", 64),
+			Name:      "pod-" + strings.Repeat("b", 32),
+			Namespace: "default",
+		})
+	}
+	states, err = large.States()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enc := states[0].ExtraInfo[StateKeyPodSandboxEncoding]; enc != StateValuePodSandboxEncodingJSONGzip {
+		t.Fatalf("expected gzip for large payloads, got %q", enc)
+	}
+	b, _ := large.JSON()
+	if len(states[0].ExtraInfo[StateKeyPodSandboxData]) >= len(b) {
+		t.Fatalf("expected compressed data to be smaller than %d bytes, got %d", len(b), len(states[0].ExtraInfo[StateKeyPodSandboxData]))
+	}
+
+	parsed, err := ParseStatesToOutput(states...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(large, parsed) {
+		t.Fatal("expected the decompressed output to match")
+	}
+
+	if _, err := ParseStatesToOutput(components.State{
+		Name:      StateNamePodSandbox,
+		ExtraInfo: map[string]string{StateKeyPodSandboxEncoding: "unknown"},
+	}); err == nil {
+		t.Fatal("expected error for unknown encoding")
+	}
+}
+
+func TestOutputYAML(t *testing.T) {
+	t.Parallel()
+
+	o := &Output{
+		Pods: []PodSandbox{
+			{
+				ID:        "sandbox-1",
+				Namespace: "default",
+				Name:      "trainer-0",
+				State:     "SANDBOX_READY",
+				Info:      map[string]string{"key": "value"},
+				CreatedAt: time.Date(2024, 10, 1, 0, 0, 0, 123, time.UTC).UnixNano(),
+				Containers: []PodSandboxContainerStatus{
+					{
+						ID:           "c1",
+						Name:         "main",
+						Image:        "nvcr.io/nvidia/pytorch:24.09-py3",
+						ImageRef:     "sha256:abc",
+						State:        "CONTAINER_EXITED",
+						ExitCode:     137,
+						RestartCount: 2,
+					},
+				},
+			},
+		},
+	}
+
+	b, err := o.YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// field names are consistent with the JSON tags
+	for _, key := range []string{"pods:", "created_at:", "imageRef:", "exitCode:", "restartCount:"} {
+		if !strings.Contains(string(b), key) {
+			t.Fatalf("expected %q in YAML output:\n%s", key, b)
+		}
+	}
+
+	parsed, err := ParseOutputYAML(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(o, parsed) {
+		t.Fatalf("expected %+v, got %+v", o, parsed)
+	}
+}
@@ -0,0 +1,124 @@
+package pod
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/components/query"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type blockingPoller struct {
+	query.Poller
+	blockc chan struct{}
+}
+
+func (p *blockingPoller) Last() (*query.Item, error) {
+	<-p.blockc
+	return nil, nil
+}
+
+func TestComponentStatesTimeout(t *testing.T) {
+	t.Parallel()
+
+	blockc := make(chan struct{})
+	defer close(blockc)
+
+	c := &component{poller: &blockingPoller{blockc: blockc}}
+	c.cfg.Query.StatesTimeout = metav1.Duration{Duration: 100 * time.Millisecond}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, ctx := range []context.Context{ctx, context.Background()} {
+		start := time.Now()
+		states, err := c.States(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Fatalf("expected prompt return, took %v", elapsed)
+		}
+		if len(states) != 1 || states[0].Healthy || states[0].ReasonCode != components.ReasonTimeout {
+			t.Fatalf("unexpected states: %+v", states)
+		}
+	}
+}
+
+type lastPoller struct {
+	query.Poller
+	last *query.Item
+}
+
+func (p *lastPoller) Last() (*query.Item, error) {
+	return p.last, nil
+}
+
+func TestComponentReady(t *testing.T) {
+	t.Parallel()
+
+	p := &lastPoller{}
+	c := &component{poller: p}
+	if c.Ready() {
+		t.Fatal("expected not ready before the first poll")
+	}
+
+	// failed queries also count, as the poller has produced a result
+	p.last = &query.Item{Time: metav1.Now()}
+	if !c.Ready() {
+		t.Fatal("expected ready after the first poll")
+	}
+	if !components.Ready(c) {
+		t.Fatal("expected ready via components.Ready")
+	}
+}
+
+func TestComponentStatesReasonCode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		last           *query.Item
+		wantReason     string
+		wantReasonCode components.ReasonCode
+	}{
+		{
+			name:           "containerd unreachable",
+			last:           &query.Item{Error: fmt.Errorf("%w: dial timeout", ErrContainerdUnreachable)},
+			wantReason:     "containerd socket unreachable",
+			wantReasonCode: components.ReasonQueryFailed,
+		},
+		{
+			name:           "query failed",
+			last:           &query.Item{Error: errors.New("failed")},
+			wantReason:     "last query failed",
+			wantReasonCode: components.ReasonQueryFailed,
+		},
+		{
+			name:           "no output",
+			last:           &query.Item{},
+			wantReason:     "no output",
+			wantReasonCode: components.ReasonNoOutput,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &component{poller: &lastPoller{last: tt.last}}
+			states, err := c.States(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(states) != 1 || states[0].Healthy {
+				t.Fatalf("expected one unhealthy state, got %+v", states)
+			}
+			if states[0].Reason != tt.wantReason || states[0].ReasonCode != tt.wantReasonCode {
+				t.Fatalf("expected reason %q (%q), got %q (%q)", tt.wantReason, tt.wantReasonCode, states[0].Reason, states[0].ReasonCode)
+			}
+		})
+	}
+}
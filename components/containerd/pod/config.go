@@ -3,13 +3,52 @@ package pod
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
 
 	query_config "github.com/leptonai/gpud/components/query/config"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type Config struct {
 	Query    query_config.Config `json:"query"`
 	Endpoint string              `json:"endpoint"`
+
+	// Namespace to list the pods from.
+	// Empty to list the pods from all namespaces.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Timeout to connect to the containerd socket.
+	// Default is DefaultDialTimeout.
+	DialTimeout metav1.Duration `json:"dial_timeout,omitempty"`
+
+	// Number of retries to connect to the containerd socket while it is unreachable
+	// (e.g., containerd is still starting at node boot).
+	// Zero uses DefaultConnectRetries, and negative disables the retries.
+	ConnectRetries int `json:"connect_retries,omitempty"`
+	// Delay before the first connect retry, doubled for every retry.
+	// Default is DefaultConnectRetryBaseDelay.
+	ConnectRetryBaseDelay metav1.Duration `json:"connect_retry_base_delay,omitempty"`
+
+	// Timeout to get the status of each pod sandbox, so that a wedged pod
+	// does not block the whole poll (see ListOptions.PodTimeout).
+	// Zero uses DefaultPodStatusTimeout, and negative disables the per-pod timeout.
+	PodStatusTimeout metav1.Duration `json:"pod_status_timeout,omitempty"`
+
+	// Maximum number of the most recent events returned by a single Events call
+	// (see components.CapEvents).
+	// Zero uses components.DefaultMaxEvents, and negative disables the cap.
+	MaxEvents int `json:"max_events,omitempty"`
+}
+
+func (cfg *Config) SetDefaultsIfNotSet() {
+	cfg.Query.SetDefaultsIfNotSet()
+	if cfg.DialTimeout.Duration == 0 {
+		cfg.DialTimeout.Duration = DefaultDialTimeout
+	}
+	if cfg.ConnectRetryBaseDelay.Duration == 0 {
+		cfg.ConnectRetryBaseDelay.Duration = DefaultConnectRetryBaseDelay
+	}
 }
 
 func ParseConfig(b any, db *sql.DB) (*Config, error) {
@@ -29,5 +68,8 @@ func ParseConfig(b any, db *sql.DB) (*Config, error) {
 }
 
 func (cfg Config) Validate() error {
+	if cfg.ConnectRetryBaseDelay.Duration < 0 {
+		return fmt.Errorf("invalid connect retry base delay: %v", cfg.ConnectRetryBaseDelay.Duration)
+	}
 	return nil
 }
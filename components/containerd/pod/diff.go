@@ -0,0 +1,123 @@
+package pod
+
+// PodChangeType is the type of the change between two outputs.
+type PodChangeType string
+
+const (
+	PodChangeTypePodAdded         PodChangeType = "pod_added"
+	PodChangeTypePodRemoved       PodChangeType = "pod_removed"
+	PodChangeTypeContainerAdded   PodChangeType = "container_added"
+	PodChangeTypeContainerRemoved PodChangeType = "container_removed"
+	// The container state (or the exit code) changed.
+	PodChangeTypeContainerChanged PodChangeType = "container_changed"
+)
+
+// PodChange is a single change between two outputs, with the pod identity.
+type PodChange struct {
+	Type PodChangeType `json:"type"`
+
+	PodID        string `json:"pod_id"`
+	PodNamespace string `json:"pod_namespace,omitempty"`
+	PodName      string `json:"pod_name,omitempty"`
+
+	// The container status before the change, set for the container changes
+	// (nil if the container is added).
+	Prev *PodSandboxContainerStatus `json:"prev,omitempty"`
+	// The container status after the change, set for the container changes
+	// (nil if the container is removed).
+	Cur *PodSandboxContainerStatus `json:"cur,omitempty"`
+}
+
+// Returns the changes from the old output to the new output.
+// The pods are identified by the pod sandbox IDs, and the containers by the container IDs
+// (a restarted container has a new ID, thus is reported as added).
+// A nil output is treated as no pod, so all the pods (and their containers)
+// in the other output are reported as added (or removed).
+// The changes for the pods and containers in the new output come first in the new order,
+// followed by the removals in the old order.
+func DiffOutputs(oldOutput, newOutput *Output) []PodChange {
+	var oldPods, newPods []PodSandbox
+	if oldOutput != nil {
+		oldPods = oldOutput.Pods
+	}
+	if newOutput != nil {
+		newPods = newOutput.Pods
+	}
+
+	oldByID := make(map[string]PodSandbox, len(oldPods))
+	for _, p := range oldPods {
+		oldByID[p.ID] = p
+	}
+	newIDs := make(map[string]struct{}, len(newPods))
+
+	changes := make([]PodChange, 0)
+	for _, cur := range newPods {
+		newIDs[cur.ID] = struct{}{}
+
+		prev, ok := oldByID[cur.ID]
+		if !ok {
+			changes = append(changes, newPodChange(PodChangeTypePodAdded, cur, nil, nil))
+		}
+		changes = append(changes, diffContainers(prev, cur)...)
+	}
+
+	for _, prev := range oldPods {
+		if _, ok := newIDs[prev.ID]; ok {
+			continue
+		}
+		changes = append(changes, newPodChange(PodChangeTypePodRemoved, prev, nil, nil))
+		changes = append(changes, diffContainers(prev, PodSandbox{})...)
+	}
+
+	return changes
+}
+
+// Returns the container changes from the previous to the current pod.
+// The pod identity is taken from the current pod if set, otherwise from the previous pod.
+func diffContainers(prev, cur PodSandbox) []PodChange {
+	pod := cur
+	if pod.ID == "" {
+		pod = prev
+	}
+
+	prevByID := make(map[string]PodSandboxContainerStatus, len(prev.Containers))
+	for _, c := range prev.Containers {
+		prevByID[c.ID] = c
+	}
+	curIDs := make(map[string]struct{}, len(cur.Containers))
+
+	changes := make([]PodChange, 0)
+	for i := range cur.Containers {
+		c := cur.Containers[i]
+		curIDs[c.ID] = struct{}{}
+
+		p, ok := prevByID[c.ID]
+		switch {
+		case !ok:
+			changes = append(changes, newPodChange(PodChangeTypeContainerAdded, pod, nil, &c))
+		case p.State != c.State || p.ExitCode != c.ExitCode:
+			changes = append(changes, newPodChange(PodChangeTypeContainerChanged, pod, &p, &c))
+		}
+	}
+
+	for i := range prev.Containers {
+		p := prev.Containers[i]
+		if _, ok := curIDs[p.ID]; ok {
+			continue
+		}
+		changes = append(changes, newPodChange(PodChangeTypeContainerRemoved, pod, &p, nil))
+	}
+
+	return changes
+}
+
+func newPodChange(typ PodChangeType, pod PodSandbox, prev, cur *PodSandboxContainerStatus) PodChange {
+	return PodChange{
+		Type:         typ,
+		PodID:        pod.ID,
+		PodNamespace: pod.Namespace,
+		PodName:      pod.Name,
+		Prev:         prev,
+		Cur:          cur,
+	}
+}
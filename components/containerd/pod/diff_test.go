@@ -0,0 +1,130 @@
+package pod
+
+import (
+	"testing"
+
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+func TestDiffOutputs(t *testing.T) {
+	t.Parallel()
+
+	running := runtimeapi.ContainerState_CONTAINER_RUNNING.String()
+	exited := runtimeapi.ContainerState_CONTAINER_EXITED.String()
+
+	c1 := PodSandboxContainerStatus{ID: "c1", Name: "main", State: running}
+	c1Failed := PodSandboxContainerStatus{ID: "c1", Name: "main", State: exited, ExitCode: 137}
+	c2 := PodSandboxContainerStatus{ID: "c2", Name: "sidecar", State: running}
+	c3 := PodSandboxContainerStatus{ID: "c3", Name: "main", State: running}
+
+	p1 := PodSandbox{ID: "p1", Namespace: "default", Name: "gpu-job", Containers: []PodSandboxContainerStatus{c1, c2}}
+	p2 := PodSandbox{ID: "p2", Namespace: "kube-system", Name: "dns", Containers: []PodSandboxContainerStatus{c3}}
+
+	type change struct {
+		typ         PodChangeType
+		podID       string
+		containerID string
+	}
+	summarize := func(changes []PodChange) []change {
+		rs := make([]change, 0, len(changes))
+		for _, ch := range changes {
+			id := ""
+			if ch.Cur != nil {
+				id = ch.Cur.ID
+			} else if ch.Prev != nil {
+				id = ch.Prev.ID
+			}
+			rs = append(rs, change{typ: ch.Type, podID: ch.PodID, containerID: id})
+		}
+		return rs
+	}
+
+	tests := []struct {
+		name     string
+		old, new *Output
+		expected []change
+	}{
+		{
+			name:     "both nil",
+			expected: []change{},
+		},
+		{
+			name:     "no change",
+			old:      &Output{Pods: []PodSandbox{p1}},
+			new:      &Output{Pods: []PodSandbox{p1}},
+			expected: []change{},
+		},
+		{
+			name: "pod added from nil",
+			new:  &Output{Pods: []PodSandbox{p1}},
+			expected: []change{
+				{typ: PodChangeTypePodAdded, podID: "p1"},
+				{typ: PodChangeTypeContainerAdded, podID: "p1", containerID: "c1"},
+				{typ: PodChangeTypeContainerAdded, podID: "p1", containerID: "c2"},
+			},
+		},
+		{
+			name: "pod added and removed",
+			old:  &Output{Pods: []PodSandbox{p1}},
+			new:  &Output{Pods: []PodSandbox{p2}},
+			expected: []change{
+				{typ: PodChangeTypePodAdded, podID: "p2"},
+				{typ: PodChangeTypeContainerAdded, podID: "p2", containerID: "c3"},
+				{typ: PodChangeTypePodRemoved, podID: "p1"},
+				{typ: PodChangeTypeContainerRemoved, podID: "p1", containerID: "c1"},
+				{typ: PodChangeTypeContainerRemoved, podID: "p1", containerID: "c2"},
+			},
+		},
+		{
+			name: "container state changed and removed",
+			old:  &Output{Pods: []PodSandbox{p1, p2}},
+			new: &Output{Pods: []PodSandbox{
+				{ID: "p1", Namespace: "default", Name: "gpu-job", Containers: []PodSandboxContainerStatus{c1Failed}},
+				p2,
+			}},
+			expected: []change{
+				{typ: PodChangeTypeContainerChanged, podID: "p1", containerID: "c1"},
+				{typ: PodChangeTypeContainerRemoved, podID: "p1", containerID: "c2"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := summarize(DiffOutputs(tt.old, tt.new))
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected %d changes, got %d: %+v", len(tt.expected), len(got), got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("change %d: expected %+v, got %+v", i, tt.expected[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDiffOutputsContainerChanged(t *testing.T) {
+	t.Parallel()
+
+	running := PodSandboxContainerStatus{ID: "c1", Name: "main", State: runtimeapi.ContainerState_CONTAINER_RUNNING.String()}
+	failed := PodSandboxContainerStatus{ID: "c1", Name: "main", State: runtimeapi.ContainerState_CONTAINER_EXITED.String(), ExitCode: 1}
+
+	changes := DiffOutputs(
+		&Output{Pods: []PodSandbox{{ID: "p1", Namespace: "default", Name: "gpu-job", Containers: []PodSandboxContainerStatus{running}}}},
+		&Output{Pods: []PodSandbox{{ID: "p1", Namespace: "default", Name: "gpu-job", Containers: []PodSandboxContainerStatus{failed}}}},
+	)
+	if len(changes) != 1 {
+		t.Fatalf("expected 1 change, got %d", len(changes))
+	}
+
+	ch := changes[0]
+	if ch.PodNamespace != "default" || ch.PodName != "gpu-job" {
+		t.Fatalf("expected pod default/gpu-job, got %s/%s", ch.PodNamespace, ch.PodName)
+	}
+	if ch.Prev == nil || ch.Prev.Failed() {
+		t.Fatalf("expected previous running container, got %+v", ch.Prev)
+	}
+	if ch.Cur == nil || !ch.Cur.Failed() {
+		t.Fatalf("expected current failed container, got %+v", ch.Cur)
+	}
+}
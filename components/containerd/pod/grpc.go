@@ -2,6 +2,7 @@ package pod
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"net/url"
@@ -29,8 +30,26 @@ const (
 	maxBackoffDelay      = 3 * time.Second
 	baseBackoffDelay     = 100 * time.Millisecond
 	minConnectionTimeout = 10 * time.Second
+
+	// DefaultDialTimeout is the default timeout to connect to the containerd socket.
+	DefaultDialTimeout = 10 * time.Second
+
+	// DefaultConnectRetries is the default number of retries to connect to
+	// the containerd socket (e.g., containerd is still starting at node boot).
+	DefaultConnectRetries = 3
+	// DefaultConnectRetryBaseDelay is the default delay before the first retry,
+	// doubled for every retry.
+	DefaultConnectRetryBaseDelay = 500 * time.Millisecond
 )
 
+// ErrContainerdUnreachable is returned when the containerd socket cannot be connected
+// within the dial timeout (e.g., containerd is not running or unresponsive).
+var ErrContainerdUnreachable = errors.New("containerd socket unreachable")
+
+// ErrContainerdNeverAvailable is returned when the containerd socket did not
+// become available within the retries (see ConnectWithRetry).
+var ErrContainerdNeverAvailable = errors.New("containerd never became available within retries")
+
 // ref. https://github.com/kubernetes/kubernetes/blob/v1.29.2/pkg/kubelet/cri/remote/remote_runtime.go
 func defaultDialOptions() []grpc.DialOption {
 	cps := grpc.ConnectParams{Backoff: backoff.DefaultConfig}
@@ -67,7 +86,10 @@ func parseUnixEndpoint(endpoint string) (string, error) {
 // ref. https://github.com/kubernetes-sigs/cri-tools/blob/master/cmd/main.go
 // ref. https://github.com/kubernetes/kubernetes/blob/v1.29.2/pkg/kubelet/cri/remote/remote_runtime.go
 // ref. https://github.com/kubernetes/kubernetes/blob/v1.32.0-alpha.0/staging/src/k8s.io/cri-client/pkg/remote_runtime.go
-func Connect(ctx context.Context, endpoint string) (runtimeapi.RuntimeServiceClient, runtimeapi.ImageServiceClient, *grpc.ClientConn, error) {
+//
+// Returns ErrContainerdUnreachable if the connection is not established within the dial timeout.
+// Zero dial timeout uses DefaultDialTimeout.
+func Connect(ctx context.Context, endpoint string, dialTimeout time.Duration) (runtimeapi.RuntimeServiceClient, runtimeapi.ImageServiceClient, *grpc.ClientConn, error) {
 	// "k8s.io/cri-client/pkg/util.GetAddressAndDialer" doesn't work...
 	// "code = Unavailable desc = name resolver error: produced zero addresses"
 	addr, err := parseUnixEndpoint(endpoint)
@@ -75,10 +97,20 @@ func Connect(ctx context.Context, endpoint string) (runtimeapi.RuntimeServiceCli
 		return nil, nil, nil, err
 	}
 
-	// "WithBlock" ctx cancel is no-op
-	conn, err := grpc.DialContext(ctx, addr, defaultDialOptions()...) //nolint:staticcheck
+	if dialTimeout == 0 {
+		dialTimeout = DefaultDialTimeout
+	}
+	dialCtx, dialCancel := context.WithTimeout(ctx, dialTimeout)
+	defer dialCancel()
+
+	// "WithBlock" blocks until the connection is established or the dial context is done
+	conn, err := grpc.DialContext(dialCtx, addr, defaultDialOptions()...) //nolint:staticcheck
 	if err != nil {
-		return nil, nil, nil, err
+		// parent context canceled, not a dial timeout
+		if ctx.Err() != nil {
+			return nil, nil, nil, err
+		}
+		return nil, nil, nil, fmt.Errorf("%w (endpoint %q, timeout %v): %v", ErrContainerdUnreachable, endpoint, dialTimeout, err)
 	}
 	log.Logger.Debugw("successfully dialed -- checking version", "endpoint", endpoint)
 
@@ -101,3 +133,46 @@ func Connect(ctx context.Context, endpoint string) (runtimeapi.RuntimeServiceCli
 	imageClient := runtimeapi.NewImageServiceClient(conn)
 	return runtimeClient, imageClient, conn, nil
 }
+
+// ConnectWithRetry connects as Connect does, and retries with the exponential backoff
+// (starting from the base delay) while the containerd socket is unreachable,
+// to tolerate the containerd that is briefly unavailable (e.g., at node boot).
+// Other errors (e.g., invalid endpoint) are returned without retries.
+//
+// Returns ErrContainerdNeverAvailable (wrapping the last error) if the socket is still
+// unreachable after the retries. Returns the context error if the context is done first.
+// Zero retries uses DefaultConnectRetries, and negative disables the retries.
+// Zero base delay uses DefaultConnectRetryBaseDelay.
+func ConnectWithRetry(ctx context.Context, endpoint string, dialTimeout time.Duration, retries int, baseDelay time.Duration) (runtimeapi.RuntimeServiceClient, runtimeapi.ImageServiceClient, *grpc.ClientConn, error) {
+	if retries == 0 {
+		retries = DefaultConnectRetries
+	}
+	if retries < 0 {
+		retries = 0
+	}
+	if baseDelay <= 0 {
+		baseDelay = DefaultConnectRetryBaseDelay
+	}
+
+	delay := baseDelay
+	for attempt := 0; ; attempt++ {
+		client, imageClient, conn, err := Connect(ctx, endpoint, dialTimeout)
+		if err == nil {
+			return client, imageClient, conn, nil
+		}
+		if !errors.Is(err, ErrContainerdUnreachable) {
+			return nil, nil, nil, err
+		}
+		if attempt >= retries {
+			return nil, nil, nil, fmt.Errorf("%w (%d attempts): %w", ErrContainerdNeverAvailable, attempt+1, err)
+		}
+
+		log.Logger.Debugw("containerd unreachable, retrying", "endpoint", endpoint, "attempt", attempt+1, "retries", retries, "delay", delay, "error", err)
+		select {
+		case <-ctx.Done():
+			return nil, nil, nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
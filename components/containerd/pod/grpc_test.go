@@ -0,0 +1,117 @@
+package pod
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+func TestConnectDialTimeout(t *testing.T) {
+	t.Parallel()
+
+	endpoint := "unix://" + filepath.Join(t.TempDir(), "nonexistent.sock")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, _, conn, err := Connect(ctx, endpoint, 500*time.Millisecond)
+	if err == nil {
+		conn.Close()
+		t.Fatal("expected error")
+	}
+	if !errors.Is(err, ErrContainerdUnreachable) {
+		t.Fatalf("expected %v, got %v", ErrContainerdUnreachable, err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("expected dial timeout to fire, took %v", elapsed)
+	}
+}
+
+type fakeRuntimeServer struct {
+	runtimeapi.UnimplementedRuntimeServiceServer
+}
+
+func (*fakeRuntimeServer) Version(context.Context, *runtimeapi.VersionRequest) (*runtimeapi.VersionResponse, error) {
+	return &runtimeapi.VersionResponse{RuntimeName: "fake"}, nil
+}
+
+func (*fakeRuntimeServer) Status(context.Context, *runtimeapi.StatusRequest) (*runtimeapi.StatusResponse, error) {
+	return &runtimeapi.StatusResponse{}, nil
+}
+
+func TestConnectWithRetrySocketAppearsLater(t *testing.T) {
+	t.Parallel()
+
+	sockPath := filepath.Join(t.TempDir(), "containerd.sock")
+	endpoint := "unix://" + sockPath
+
+	srv := grpc.NewServer()
+	runtimeapi.RegisterRuntimeServiceServer(srv, &fakeRuntimeServer{})
+	defer srv.Stop()
+
+	// the socket appears only after the first few attempts have failed
+	go func() {
+		time.Sleep(time.Second)
+		lis, err := net.Listen("unix", sockPath)
+		if err != nil {
+			t.Errorf("failed to listen: %v", err)
+			return
+		}
+		_ = srv.Serve(lis)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, _, conn, err := ConnectWithRetry(ctx, endpoint, 200*time.Millisecond, 10, 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	conn.Close()
+}
+
+func TestConnectWithRetryNeverAvailable(t *testing.T) {
+	t.Parallel()
+
+	endpoint := "unix://" + filepath.Join(t.TempDir(), "nonexistent.sock")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, _, conn, err := ConnectWithRetry(ctx, endpoint, 100*time.Millisecond, 2, 10*time.Millisecond)
+	if err == nil {
+		conn.Close()
+		t.Fatal("expected error")
+	}
+	if !errors.Is(err, ErrContainerdNeverAvailable) {
+		t.Fatalf("expected %v, got %v", ErrContainerdNeverAvailable, err)
+	}
+	if !errors.Is(err, ErrContainerdUnreachable) {
+		t.Fatalf("expected %v to be wrapped, got %v", ErrContainerdUnreachable, err)
+	}
+}
+
+func TestConnectWithRetryContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	endpoint := "unix://" + filepath.Join(t.TempDir(), "nonexistent.sock")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	_, _, conn, err := ConnectWithRetry(ctx, endpoint, 100*time.Millisecond, 100, time.Second)
+	if err == nil {
+		conn.Close()
+		t.Fatal("expected error")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+}
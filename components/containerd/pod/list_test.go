@@ -0,0 +1,438 @@
+package pod
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpc_status "google.golang.org/grpc/status"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+type fakeRuntimeClient struct {
+	runtimeapi.RuntimeServiceClient
+
+	sandboxes  []*runtimeapi.PodSandbox
+	containers map[string][]*runtimeapi.Container
+	// the container statuses by the container ID,
+	// only returned by ContainerStatus (e.g., exit code)
+	statuses map[string]*runtimeapi.ContainerStatus
+	// the container IDs removed after the list
+	removed map[string]struct{}
+	// the sandbox IDs whose status blocks until the context is done
+	blocking map[string]struct{}
+
+	mu             sync.Mutex
+	statusCalls    int
+	containerCalls int
+}
+
+func (f *fakeRuntimeClient) ListPodSandbox(ctx context.Context, in *runtimeapi.ListPodSandboxRequest, opts ...grpc.CallOption) (*runtimeapi.ListPodSandboxResponse, error) {
+	return &runtimeapi.ListPodSandboxResponse{Items: append([]*runtimeapi.PodSandbox(nil), f.sandboxes...)}, nil
+}
+
+func (f *fakeRuntimeClient) PodSandboxStatus(ctx context.Context, in *runtimeapi.PodSandboxStatusRequest, opts ...grpc.CallOption) (*runtimeapi.PodSandboxStatusResponse, error) {
+	f.mu.Lock()
+	f.statusCalls++
+	f.mu.Unlock()
+
+	if _, ok := f.blocking[in.PodSandboxId]; ok {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	for _, s := range f.sandboxes {
+		if s.Id == in.PodSandboxId {
+			return &runtimeapi.PodSandboxStatusResponse{
+				Status: &runtimeapi.PodSandboxStatus{
+					Id:       s.Id,
+					Metadata: s.Metadata,
+					State:    s.State,
+				},
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("sandbox %q not found", in.PodSandboxId)
+}
+
+func (f *fakeRuntimeClient) ListContainers(ctx context.Context, in *runtimeapi.ListContainersRequest, opts ...grpc.CallOption) (*runtimeapi.ListContainersResponse, error) {
+	f.mu.Lock()
+	f.containerCalls++
+	f.mu.Unlock()
+
+	return &runtimeapi.ListContainersResponse{Containers: f.containers[in.Filter.PodSandboxId]}, nil
+}
+
+func (f *fakeRuntimeClient) ContainerStatus(ctx context.Context, in *runtimeapi.ContainerStatusRequest, opts ...grpc.CallOption) (*runtimeapi.ContainerStatusResponse, error) {
+	if _, ok := f.removed[in.ContainerId]; ok {
+		return nil, grpc_status.Errorf(codes.NotFound, "container %q not found", in.ContainerId)
+	}
+	if cs, ok := f.statuses[in.ContainerId]; ok {
+		return &runtimeapi.ContainerStatusResponse{Status: cs}, nil
+	}
+	for _, cs := range f.containers {
+		for _, c := range cs {
+			if c.Id == in.ContainerId {
+				return &runtimeapi.ContainerStatusResponse{Status: &runtimeapi.ContainerStatus{Id: c.Id, Metadata: c.Metadata, State: c.State}}, nil
+			}
+		}
+	}
+	return nil, grpc_status.Errorf(codes.NotFound, "container %q not found", in.ContainerId)
+}
+
+type fakeImageClient struct {
+	runtimeapi.ImageServiceClient
+
+	tags map[string][]string
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (f *fakeImageClient) ImageStatus(ctx context.Context, in *runtimeapi.ImageStatusRequest, opts ...grpc.CallOption) (*runtimeapi.ImageStatusResponse, error) {
+	f.mu.Lock()
+	if f.calls == nil {
+		f.calls = make(map[string]int)
+	}
+	f.calls[in.Image.Image]++
+	f.mu.Unlock()
+
+	tags, ok := f.tags[in.Image.Image]
+	if !ok {
+		return &runtimeapi.ImageStatusResponse{}, nil
+	}
+	return &runtimeapi.ImageStatusResponse{Image: &runtimeapi.Image{Id: in.Image.Image, RepoTags: tags}}, nil
+}
+
+func (f *fakeImageClient) totalCalls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	total := 0
+	for _, n := range f.calls {
+		total += n
+	}
+	return total
+}
+
+// Returns the fake clients with the sandboxes, each with two containers
+// sharing one of the images.
+func newFakeClients(sandboxes int, images int) (*fakeRuntimeClient, *fakeImageClient) {
+	rc := &fakeRuntimeClient{containers: make(map[string][]*runtimeapi.Container)}
+	ic := &fakeImageClient{tags: make(map[string][]string)}
+	for i := 0; i < images; i++ {
+		ref := fmt.Sprintf("sha256:%04d", i)
+		ic.tags[ref] = []string{fmt.Sprintf("docker.io/library/image-%d:latest", i)}
+	}
+	for i := 0; i < sandboxes; i++ {
+		id := fmt.Sprintf("sandbox-%04d", i)
+		ns := "default"
+		if i%2 == 1 {
+			ns = "kube-system"
+		}
+		rc.sandboxes = append(rc.sandboxes, &runtimeapi.PodSandbox{
+			Id:       id,
+			Metadata: &runtimeapi.PodSandboxMetadata{Name: fmt.Sprintf("pod-%d", i), Namespace: ns},
+			State:    runtimeapi.PodSandboxState_SANDBOX_READY,
+		})
+		for j := 0; j < 2; j++ {
+			ref := fmt.Sprintf("sha256:%04d", (i+j)%images)
+			rc.containers[id] = append(rc.containers[id], &runtimeapi.Container{
+				Id:           fmt.Sprintf("%s-c%d", id, j),
+				PodSandboxId: id,
+				Metadata:     &runtimeapi.ContainerMetadata{Name: fmt.Sprintf("c%d", j)},
+				Image:        &runtimeapi.ImageSpec{Image: ref},
+				ImageRef:     ref,
+				State:        runtimeapi.ContainerState_CONTAINER_RUNNING,
+			})
+		}
+	}
+	// the runtime does not guarantee any order
+	for i, j := 0, len(rc.sandboxes)-1; i < j; i, j = i+1, j-1 {
+		rc.sandboxes[i], rc.sandboxes[j] = rc.sandboxes[j], rc.sandboxes[i]
+	}
+	return rc, ic
+}
+
+func TestListSandboxStatusPagination(t *testing.T) {
+	t.Parallel()
+
+	const (
+		sandboxes = 1000
+		images    = 5
+		pageSize  = 64
+	)
+	rc, ic := newFakeClients(sandboxes, images)
+
+	seen := make(map[string]struct{})
+	prev := ""
+	pages := 0
+	opts := ListOptions{PageSize: pageSize}
+	for {
+		rs, next, err := listSandboxStatus(context.Background(), rc, ic, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages++
+		if len(rs) > pageSize {
+			t.Fatalf("page %d has %d sandboxes, expected at most %d", pages, len(rs), pageSize)
+		}
+		for _, r := range rs {
+			id := r.Status.Id
+			if id <= prev {
+				t.Fatalf("sandbox %q out of order after %q", id, prev)
+			}
+			prev = id
+			if _, ok := seen[id]; ok {
+				t.Fatalf("sandbox %q returned twice", id)
+			}
+			seen[id] = struct{}{}
+
+			if len(r.ContainersStatuses) != 2 {
+				t.Fatalf("sandbox %q has %d containers, expected 2", id, len(r.ContainersStatuses))
+			}
+			for _, c := range r.ContainersStatuses {
+				if c.Image.UserSpecifiedImage != ic.tags[c.ImageRef][0] {
+					t.Fatalf("container %q has image %q, expected %q", c.Id, c.Image.UserSpecifiedImage, ic.tags[c.ImageRef][0])
+				}
+			}
+		}
+		if next == "" {
+			break
+		}
+		opts.Continue = next
+	}
+
+	if len(seen) != sandboxes {
+		t.Fatalf("expected %d sandboxes, got %d", sandboxes, len(seen))
+	}
+	if expected := (sandboxes + pageSize - 1) / pageSize; pages != expected {
+		t.Fatalf("expected %d pages, got %d", expected, pages)
+	}
+	if rc.statusCalls != sandboxes {
+		t.Fatalf("expected %d sandbox status calls, got %d", sandboxes, rc.statusCalls)
+	}
+
+	// each page looks up each image at most once
+	if calls := ic.totalCalls(); calls > pages*images {
+		t.Fatalf("expected at most %d image status calls, got %d", pages*images, calls)
+	}
+}
+
+func TestListSandboxStatusNamespace(t *testing.T) {
+	t.Parallel()
+
+	rc, ic := newFakeClients(100, 3)
+
+	rs, next, err := listSandboxStatus(context.Background(), rc, ic, ListOptions{Namespace: "kube-system"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != "" {
+		t.Fatalf("expected no continuation token, got %q", next)
+	}
+	if len(rs) != 50 {
+		t.Fatalf("expected 50 sandboxes, got %d", len(rs))
+	}
+	for _, r := range rs {
+		if r.Status.Metadata.Namespace != "kube-system" {
+			t.Fatalf("unexpected namespace %q", r.Status.Metadata.Namespace)
+		}
+	}
+
+	// all pods in one page share the image lookups
+	if calls := ic.totalCalls(); calls != 3 {
+		t.Fatalf("expected 3 image status calls, got %d", calls)
+	}
+}
+
+func TestPaginateSandboxes(t *testing.T) {
+	t.Parallel()
+
+	sandboxes := []*runtimeapi.PodSandbox{{Id: "c"}, {Id: "a"}, {Id: "b"}}
+
+	page, next := paginateSandboxes(sandboxes, 2, "")
+	if len(page) != 2 || page[0].Id != "a" || page[1].Id != "b" || next != "b" {
+		t.Fatalf("unexpected first page %v, next %q", page, next)
+	}
+	page, next = paginateSandboxes(sandboxes, 2, next)
+	if len(page) != 1 || page[0].Id != "c" || next != "" {
+		t.Fatalf("unexpected second page %v, next %q", page, next)
+	}
+	page, next = paginateSandboxes(sandboxes, 2, "c")
+	if len(page) != 0 || next != "" {
+		t.Fatalf("unexpected page after the last sandbox %v, next %q", page, next)
+	}
+	page, next = paginateSandboxes(sandboxes, 0, "")
+	if len(page) != 3 || next != "" {
+		t.Fatalf("unexpected unpaginated page %v, next %q", page, next)
+	}
+}
+
+func TestImageTagCache(t *testing.T) {
+	t.Parallel()
+
+	ic := &fakeImageClient{tags: map[string][]string{"sha256:a": {"docker.io/library/a:1", "docker.io/library/a:latest"}}}
+	cache := newImageTagCache(ic)
+
+	for i := 0; i < 10; i++ {
+		tag, ok := cache.resolve(context.Background(), "sha256:a")
+		if !ok || tag != "docker.io/library/a:1,docker.io/library/a:latest" {
+			t.Fatalf("unexpected tag %q (ok %v)", tag, ok)
+		}
+		if _, ok := cache.resolve(context.Background(), "sha256:missing"); ok {
+			t.Fatal("expected missing image to not resolve")
+		}
+	}
+	if ic.calls["sha256:a"] != 1 || ic.calls["sha256:missing"] != 1 {
+		t.Fatalf("expected one image status call per image, got %v", ic.calls)
+	}
+}
+
+func TestListSandboxStatusImageStatusCalls(t *testing.T) {
+	t.Parallel()
+
+	const (
+		sandboxes = 200
+		images    = 4
+	)
+	rc, ic := newFakeClients(sandboxes, images)
+
+	rs, _, err := listSandboxStatus(context.Background(), rc, ic, ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rs) != sandboxes {
+		t.Fatalf("expected %d sandboxes, got %d", sandboxes, len(rs))
+	}
+	if rc.statusCalls != sandboxes || rc.containerCalls != sandboxes {
+		t.Fatalf("expected %d status and container calls, got %d and %d", sandboxes, rc.statusCalls, rc.containerCalls)
+	}
+	if calls := ic.totalCalls(); calls != images {
+		t.Fatalf("expected %d image status calls for %d containers, got %d", images, sandboxes*2, calls)
+	}
+	for ref, n := range ic.calls {
+		if n != 1 {
+			t.Fatalf("expected one image status call for %q, got %d", ref, n)
+		}
+	}
+}
+
+func TestListSandboxStatusPodTimeout(t *testing.T) {
+	t.Parallel()
+
+	rc, ic := newFakeClients(3, 2)
+	rc.blocking = map[string]struct{}{"sandbox-0001": {}}
+
+	start := time.Now()
+	rs, _, err := listSandboxStatus(context.Background(), rc, ic, ListOptions{PodTimeout: 100 * time.Millisecond})
+	if !errors.Is(err, ErrPartialPodStatus) {
+		t.Fatalf("expected %v, got %v", ErrPartialPodStatus, err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "sandbox-0001") {
+		t.Fatalf("expected the aggregate error with the timed out pod, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("expected the blocked pod to time out, took %v", elapsed)
+	}
+
+	if len(rs) != 3 {
+		t.Fatalf("expected 3 sandboxes, got %d", len(rs))
+	}
+	for _, r := range rs {
+		pod := ConvertToPodSandbox(r)
+		if pod.ID == "sandbox-0001" {
+			if pod.Error == "" || pod.Name != "pod-1" || pod.Namespace != "kube-system" {
+				t.Fatalf("expected the timed out pod with the error marker, got %+v", pod)
+			}
+			if len(pod.Containers) != 0 || len(pod.Info) != 0 {
+				t.Fatalf("expected no containers or info for the timed out pod, got %+v", pod)
+			}
+			continue
+		}
+		if pod.Error != "" || len(pod.Containers) != 2 {
+			t.Fatalf("expected the pod with 2 containers and no error, got %+v", pod)
+		}
+	}
+
+	o := &Output{Pods: []PodSandbox{ConvertToPodSandbox(rs[0]), ConvertToPodSandbox(rs[1])}}
+	if o.CountPodsWithErrors() != 1 || !strings.Contains(o.describeReason(), "1 pods failed to get the status") {
+		t.Fatalf("unexpected reason %q", o.describeReason())
+	}
+
+	// the canceled list fails as a whole
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := listSandboxStatus(ctx, rc, ic, ListOptions{PodTimeout: 100 * time.Millisecond}); !errors.Is(err, context.Canceled) || errors.Is(err, ErrPartialPodStatus) {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestListSandboxStatusFailedContainer(t *testing.T) {
+	t.Parallel()
+
+	rc, ic := newFakeClients(2, 2)
+	// the listed container is exited, but only the status has the exit code
+	rc.containers["sandbox-0001"][0].State = runtimeapi.ContainerState_CONTAINER_EXITED
+	rc.statuses = map[string]*runtimeapi.ContainerStatus{
+		"sandbox-0001-c0": {
+			Id:         "sandbox-0001-c0",
+			State:      runtimeapi.ContainerState_CONTAINER_EXITED,
+			FinishedAt: 1700000000000000000,
+			ExitCode:   137,
+			Reason:     "OOMKilled",
+			Message:    "out of memory",
+		},
+	}
+	// removed after the list
+	rc.containers["sandbox-0000"] = append(rc.containers["sandbox-0000"], &runtimeapi.Container{
+		Id:       "sandbox-0000-removed",
+		Metadata: &runtimeapi.ContainerMetadata{Name: "removed"},
+	})
+	rc.removed = map[string]struct{}{"sandbox-0000-removed": {}}
+
+	rs, _, err := listSandboxStatus(context.Background(), rc, ic, ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := &Output{}
+	for _, r := range rs {
+		o.Pods = append(o.Pods, ConvertToPodSandbox(r))
+	}
+
+	var found bool
+	for _, pod := range o.Pods {
+		if pod.ID == "sandbox-0000" && len(pod.Containers) != 2 {
+			t.Fatalf("expected the removed container to be skipped, got %+v", pod.Containers)
+		}
+		for _, c := range pod.Containers {
+			if c.ID != "sandbox-0001-c0" {
+				continue
+			}
+			found = true
+			if !c.Failed() || c.ExitCode != 137 || c.Reason != "OOMKilled" || c.Message != "out of memory" || c.FinishedAt != 1700000000000000000 {
+				t.Fatalf("expected the failed container status, got %+v", c)
+			}
+		}
+	}
+	if !found {
+		t.Fatal("expected the exited container")
+	}
+
+	states, err := o.States()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(states) != 1 || states[0].Healthy {
+		t.Fatalf("expected the unhealthy state, got %+v", states)
+	}
+	if states[0].Reason != "1 of 2 pods have failed containers" {
+		t.Fatalf("unexpected reason %q", states[0].Reason)
+	}
+}
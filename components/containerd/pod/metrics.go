@@ -0,0 +1,67 @@
+package pod
+
+import (
+	"github.com/leptonai/gpud/log"
+
+	"github.com/prometheus/client_golang/prometheus"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+var (
+	descPodsTotal = prometheus.NewDesc(
+		"gpud_containerd_pods_total",
+		"tracks the current total number of pod sandboxes",
+		nil,
+		nil,
+	)
+	descContainersByState = prometheus.NewDesc(
+		"gpud_containerd_containers_by_state",
+		"tracks the current number of containers by state",
+		[]string{"state"},
+		nil,
+	)
+)
+
+var _ prometheus.Collector = (*collector)(nil)
+
+// collector reports the pod counts from the latest poller output on each scrape.
+type collector struct {
+	// returns the latest output, nil if not available yet
+	lastOutput func() (*Output, error)
+}
+
+func newCollector(lastOutput func() (*Output, error)) *collector {
+	return &collector{lastOutput: lastOutput}
+}
+
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descPodsTotal
+	ch <- descContainersByState
+}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	o, err := c.lastOutput()
+	if err != nil {
+		log.Logger.Debugw("failed to get the latest output", "component", Name, "error", err)
+		return
+	}
+	if o == nil {
+		return
+	}
+
+	// report all the known states for the stable series
+	byState := make(map[string]int)
+	for _, s := range runtimeapi.ContainerState_name {
+		byState[s] = 0
+	}
+	for _, pod := range o.Pods {
+		for _, ctr := range pod.Containers {
+			byState[ctr.State]++
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(descPodsTotal, prometheus.GaugeValue, float64(len(o.Pods)))
+	for state, cnt := range byState {
+		ch <- prometheus.MustNewConstMetric(descContainersByState, prometheus.GaugeValue, float64(cnt), state)
+	}
+}
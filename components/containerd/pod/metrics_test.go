@@ -0,0 +1,66 @@
+package pod
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+func TestCollector(t *testing.T) {
+	t.Parallel()
+
+	running := runtimeapi.ContainerState_CONTAINER_RUNNING.String()
+	exited := runtimeapi.ContainerState_CONTAINER_EXITED.String()
+	o := &Output{
+		Pods: []PodSandbox{
+			{ID: "a", Containers: []PodSandboxContainerStatus{{State: running}, {State: exited}}},
+			{ID: "b", Containers: []PodSandboxContainerStatus{{State: running}}},
+		},
+	}
+
+	reg := prometheus.NewRegistry()
+	if err := reg.Register(newCollector(func() (*Output, error) { return o, nil })); err != nil {
+		t.Fatal(err)
+	}
+
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var podsTotal float64
+	byState := make(map[string]float64)
+	for _, mf := range mfs {
+		switch mf.GetName() {
+		case "gpud_containerd_pods_total":
+			podsTotal = mf.GetMetric()[0].GetGauge().GetValue()
+		case "gpud_containerd_containers_by_state":
+			for _, m := range mf.GetMetric() {
+				byState[m.GetLabel()[0].GetValue()] = m.GetGauge().GetValue()
+			}
+		}
+	}
+	if podsTotal != 2 {
+		t.Fatalf("expected 2 pods, got %v", podsTotal)
+	}
+	if byState[running] != 2 || byState[exited] != 1 {
+		t.Fatalf("unexpected containers by state %v", byState)
+	}
+	if v, ok := byState[runtimeapi.ContainerState_CONTAINER_CREATED.String()]; !ok || v != 0 {
+		t.Fatalf("expected zero created containers, got %v (found %v)", v, ok)
+	}
+
+	// no output yet
+	reg = prometheus.NewRegistry()
+	if err := reg.Register(newCollector(func() (*Output, error) { return nil, nil })); err != nil {
+		t.Fatal(err)
+	}
+	mfs, err = reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mfs) != 0 {
+		t.Fatalf("expected no metrics without output, got %d", len(mfs))
+	}
+}
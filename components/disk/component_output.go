@@ -277,7 +277,9 @@ func getDefaultPoller() query.Poller {
 
 func CreateGet(cfg Config) query.GetFunc {
 	return func(ctx context.Context) (_ any, e error) {
+		start := time.Now()
 		defer func() {
+			components_metrics.SetGetLatency(Name, time.Since(start))
 			if e != nil {
 				components_metrics.SetGetFailed(Name)
 			} else {
@@ -15,10 +15,10 @@ import (
 const Name = "dmesg"
 
 func New(ctx context.Context, cfg Config) (components.Component, error) {
-	if err := cfg.Log.Validate(); err != nil {
+	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
-	cfg.Log.SetDefaultsIfNotSet()
+	cfg.SetDefaultsIfNotSet()
 
 	if err := createDefaultLogPoller(ctx, cfg); err != nil {
 		return nil, err
@@ -84,7 +84,11 @@ func (c *Component) Events(ctx context.Context, since time.Time) ([]components.E
 		return nil, err
 	}
 	ev := &Event{Matched: items}
-	return ev.Events(), nil
+	evs := ev.Events()
+	if len(evs) == 0 {
+		return nil, nil
+	}
+	return coalesceOOMEvents(items, evs, c.cfg.OOMCoalesceWindow.Duration), nil
 }
 
 func (c *Component) Metrics(ctx context.Context, since time.Time) ([]components.Metric, error) {
@@ -37,6 +37,13 @@ const (
 	EventKeyDmesgMatchedLine        = "line"
 	EventKeyDmesgMatchedFilter      = "filter"
 	EventKeyDmesgMatchedError       = "error"
+
+	// Optional, only set if the matched filter has them.
+	EventKeyDmesgMatchedSeverity        = "severity"
+	EventKeyDmesgMatchedSuggestedAction = "suggested_action"
+
+	// Only set for the GPU fallen off the bus events (see EventNvidiaGPUFallenOffBus).
+	EventKeyDmesgMatchedPCIAddress = "pci_address"
 )
 
 func ParseEventDmesgMatched(m map[string]string) (query_log.Item, error) {
@@ -96,7 +103,7 @@ func (ev *Event) Events() []components.Event {
 		if ev.Error != nil {
 			es = ev.Error.Error()
 		}
-		evs = append(evs, components.Event{
+		e := components.Event{
 			Time: ev.Time,
 			Name: EventNameDmesgMatched,
 			ExtraInfo: map[string]string{
@@ -105,7 +112,23 @@ func (ev *Event) Events() []components.Event {
 				EventKeyDmesgMatchedFilter:      string(b),
 				EventKeyDmesgMatchedError:       es,
 			},
-		})
+		}
+		if ev.Matched != nil {
+			// the severity levels match the event types
+			e.Type = ev.Matched.Severity
+			if ev.Matched.Severity != "" {
+				e.ExtraInfo[EventKeyDmesgMatchedSeverity] = ev.Matched.Severity
+			}
+			if ev.Matched.SuggestedAction != "" {
+				e.ExtraInfo[EventKeyDmesgMatchedSuggestedAction] = ev.Matched.SuggestedAction
+			}
+			if ev.Matched.Name == EventNvidiaGPUFallenOffBus {
+				if pci, ok := ParseGPUFallenOffBusPCI(ev.Line); ok {
+					e.ExtraInfo[EventKeyDmesgMatchedPCIAddress] = pci
+				}
+			}
+		}
+		evs = append(evs, e)
 	}
 	if len(evs) == 0 {
 		return nil
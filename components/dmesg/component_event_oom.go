@@ -0,0 +1,121 @@
+package dmesg
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/leptonai/gpud/components"
+	query_log "github.com/leptonai/gpud/components/query/log"
+)
+
+// DefaultOOMCoalesceWindow is the default window to coalesce
+// the repeated OOM events of the same process into a single event.
+const DefaultOOMCoalesceWindow = 5 * time.Second
+
+const (
+	// Only set for the OOM events.
+	EventKeyDmesgMatchedOOMProcessID   = "oom_process_id"
+	EventKeyDmesgMatchedOOMProcessName = "oom_process_name"
+
+	// Only set for the coalesced OOM events.
+	EventKeyDmesgMatchedCount            = "count"
+	EventKeyDmesgMatchedFirstUnixSeconds = "first_unix_seconds"
+	EventKeyDmesgMatchedLastUnixSeconds  = "last_unix_seconds"
+)
+
+var (
+	// e.g.,
+	// Out of memory: Killed process 123 (python)
+	// Memory cgroup out of memory: Killed process 123, UID 48, (httpd).
+	regexOOMKilledProcess = regexp.MustCompile(`Killed process (\d+),? (?:UID \d+,? )?\(([^)]+)\)`)
+
+	// e.g.,
+	// postgres invoked oom-killer: gfp_mask=0x201d2, order=0, oomkilladj=0
+	regexOOMInvokedProcess = regexp.MustCompile(`(?i)(\S+) (?:invoked|triggered) oom-killer\b`)
+)
+
+// Returns the killed process ID and name from the OOM line,
+// or the process that invoked the OOM killer (with an empty ID).
+// Returns false if the line has no process information.
+func parseOOMProcess(line string) (pid string, name string, ok bool) {
+	if m := regexOOMKilledProcess.FindStringSubmatch(line); len(m) == 3 {
+		return m[1], m[2], true
+	}
+	if m := regexOOMInvokedProcess.FindStringSubmatch(line); len(m) == 2 {
+		return "", m[1], true
+	}
+	return "", "", false
+}
+
+func isOOMFilter(name string) bool {
+	switch name {
+	case EventOOMKill, EventOOMKiller, EventOOMCgroup:
+		return true
+	default:
+		return false
+	}
+}
+
+type oomKey struct {
+	filter  string
+	process string
+}
+
+type oomGroup struct {
+	idx   int // index of the coalesced event
+	first time.Time
+	last  time.Time
+	count int
+}
+
+// Coalesces the OOM events of the same filter and the same process
+// within the window (from the first occurrence) into a single event,
+// with the count and the first/last timestamps.
+// The items and the events must be in the same order (see Event.Events).
+// Non-OOM events are returned as is.
+func coalesceOOMEvents(items []query_log.Item, evs []components.Event, window time.Duration) []components.Event {
+	if len(items) != len(evs) {
+		return evs
+	}
+
+	coalesced := make([]components.Event, 0, len(evs))
+	groups := make(map[oomKey]*oomGroup)
+	for i, item := range items {
+		ev := evs[i]
+		if item.Matched == nil || !isOOMFilter(item.Matched.Name) {
+			coalesced = append(coalesced, ev)
+			continue
+		}
+		pid, name, ok := parseOOMProcess(item.Line)
+		if !ok {
+			coalesced = append(coalesced, ev)
+			continue
+		}
+
+		ts := item.Time.Time
+		key := oomKey{filter: item.Matched.Name, process: name}
+		if g, found := groups[key]; found && window > 0 && ts.Sub(g.first) <= window {
+			g.count++
+			if ts.After(g.last) {
+				g.last = ts
+			}
+			prev := coalesced[g.idx]
+			prev.ExtraInfo[EventKeyDmesgMatchedCount] = strconv.Itoa(g.count)
+			prev.ExtraInfo[EventKeyDmesgMatchedLastUnixSeconds] = fmt.Sprintf("%d", g.last.Unix())
+			continue
+		}
+
+		ev.ExtraInfo[EventKeyDmesgMatchedOOMProcessName] = name
+		if pid != "" {
+			ev.ExtraInfo[EventKeyDmesgMatchedOOMProcessID] = pid
+		}
+		ev.ExtraInfo[EventKeyDmesgMatchedCount] = "1"
+		ev.ExtraInfo[EventKeyDmesgMatchedFirstUnixSeconds] = fmt.Sprintf("%d", ts.Unix())
+		ev.ExtraInfo[EventKeyDmesgMatchedLastUnixSeconds] = fmt.Sprintf("%d", ts.Unix())
+		groups[key] = &oomGroup{idx: len(coalesced), first: ts, last: ts, count: 1}
+		coalesced = append(coalesced, ev)
+	}
+	return coalesced
+}
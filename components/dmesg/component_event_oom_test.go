@@ -0,0 +1,86 @@
+package dmesg
+
+import (
+	"testing"
+	"time"
+
+	query_log "github.com/leptonai/gpud/components/query/log"
+	query_log_filter "github.com/leptonai/gpud/components/query/log/filter"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseOOMProcess(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		line     string
+		wantPID  string
+		wantName string
+		wantOK   bool
+	}{
+		{line: "Out of memory: Killed process 123 (python)", wantPID: "123", wantName: "python", wantOK: true},
+		{line: "Memory cgroup out of memory: Killed process 123, UID 48, (httpd).", wantPID: "123", wantName: "httpd", wantOK: true},
+		{line: "[Sun Dec  8 09:23:39 2024] postgres invoked oom-killer: gfp_mask=0x201d2, order=0", wantName: "postgres", wantOK: true},
+		{line: "Out of memory: Kill process", wantOK: false},
+	}
+	for _, tt := range tests {
+		pid, name, ok := parseOOMProcess(tt.line)
+		if pid != tt.wantPID || name != tt.wantName || ok != tt.wantOK {
+			t.Errorf("%q: expected (%q, %q, %v), got (%q, %q, %v)", tt.line, tt.wantPID, tt.wantName, tt.wantOK, pid, name, ok)
+		}
+	}
+}
+
+func TestCoalesceOOMEvents(t *testing.T) {
+	t.Parallel()
+
+	filters := make(map[string]*query_log_filter.Filter)
+	for _, f := range defaultFilters {
+		filters[f.Name] = f
+	}
+
+	base := time.Unix(1000, 0)
+	items := []query_log.Item{
+		{Time: metav1.NewTime(base), Line: "Out of memory: Killed process 1 (python)", Matched: filters[EventOOMKill]},
+		{Time: metav1.NewTime(base.Add(time.Second)), Line: "Out of memory: Killed process 2 (python)", Matched: filters[EventOOMKill]},
+		{Time: metav1.NewTime(base.Add(2 * time.Second)), Line: "Out of memory: Killed process 3 (nginx)", Matched: filters[EventOOMKill]},
+		{Time: metav1.NewTime(base.Add(2 * time.Second)), Line: "pcieport 0000:00:01.0: AER: Corrected error received: 0000:01:00.0", Matched: filters[EventPCIeAERCorrected]},
+		{Time: metav1.NewTime(base.Add(3 * time.Second)), Line: "Out of memory: Killed process 4 (python)", Matched: filters[EventOOMKill]},
+		// outside of the window from the first python kill
+		{Time: metav1.NewTime(base.Add(10 * time.Second)), Line: "Out of memory: Killed process 5 (python)", Matched: filters[EventOOMKill]},
+	}
+	ev := &Event{Matched: items}
+
+	evs := coalesceOOMEvents(items, ev.Events(), 5*time.Second)
+	if len(evs) != 4 {
+		t.Fatalf("expected 4 events, got %d", len(evs))
+	}
+
+	python := evs[0].ExtraInfo
+	if python[EventKeyDmesgMatchedOOMProcessName] != "python" || python[EventKeyDmesgMatchedCount] != "3" {
+		t.Fatalf("unexpected coalesced event %+v", python)
+	}
+	if python[EventKeyDmesgMatchedFirstUnixSeconds] != "1000" || python[EventKeyDmesgMatchedLastUnixSeconds] != "1003" {
+		t.Fatalf("unexpected first/last timestamps %+v", python)
+	}
+	if python[EventKeyDmesgMatchedOOMProcessID] != "1" {
+		t.Fatalf("expected the first pid, got %q", python[EventKeyDmesgMatchedOOMProcessID])
+	}
+
+	if evs[1].ExtraInfo[EventKeyDmesgMatchedOOMProcessName] != "nginx" || evs[1].ExtraInfo[EventKeyDmesgMatchedCount] != "1" {
+		t.Fatalf("unexpected nginx event %+v", evs[1].ExtraInfo)
+	}
+	if _, ok := evs[2].ExtraInfo[EventKeyDmesgMatchedCount]; ok {
+		t.Fatalf("expected non-OOM event to be unchanged, got %+v", evs[2].ExtraInfo)
+	}
+	if evs[3].ExtraInfo[EventKeyDmesgMatchedOOMProcessID] != "5" || evs[3].ExtraInfo[EventKeyDmesgMatchedCount] != "1" {
+		t.Fatalf("expected a new event outside of the window, got %+v", evs[3].ExtraInfo)
+	}
+
+	// zero window disables the coalescing
+	evs = coalesceOOMEvents(items, ev.Events(), 0)
+	if len(evs) != len(items) {
+		t.Fatalf("expected %d events, got %d", len(items), len(evs))
+	}
+}
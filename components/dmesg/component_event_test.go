@@ -0,0 +1,56 @@
+package dmesg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leptonai/gpud/components"
+	query_log "github.com/leptonai/gpud/components/query/log"
+	query_log_filter "github.com/leptonai/gpud/components/query/log/filter"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEventsSeverity(t *testing.T) {
+	t.Parallel()
+
+	var oomCgroup *query_log_filter.Filter
+	for _, f := range defaultFilters {
+		if f.Name == EventOOMCgroup {
+			oomCgroup = f
+		}
+	}
+	if oomCgroup == nil {
+		t.Fatal("oom cgroup filter not found")
+	}
+
+	now := metav1.NewTime(time.Now())
+	ev := &Event{
+		Matched: []query_log.Item{
+			{Time: now, Line: "Memory cgroup out of memory: Killed process 123, UID 48, (httpd).", Matched: oomCgroup},
+			{Time: now, Line: "custom", Matched: &query_log_filter.Filter{Name: "custom"}},
+		},
+	}
+
+	evs := ev.Events()
+	if len(evs) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(evs))
+	}
+	if evs[0].Type != components.EventTypeWarn {
+		t.Errorf("expected type %q, got %q", components.EventTypeWarn, evs[0].Type)
+	}
+	if evs[0].ExtraInfo[EventKeyDmesgMatchedSeverity] != query_log_filter.SeverityWarn {
+		t.Errorf("unexpected severity %q", evs[0].ExtraInfo[EventKeyDmesgMatchedSeverity])
+	}
+	if evs[0].ExtraInfo[EventKeyDmesgMatchedSuggestedAction] == "" {
+		t.Error("expected suggested action")
+	}
+
+	// filters without severity still work
+	if evs[1].Type != "" {
+		t.Errorf("expected empty type, got %q", evs[1].Type)
+	}
+	if _, ok := evs[1].ExtraInfo[EventKeyDmesgMatchedSeverity]; ok {
+		t.Error("unexpected severity")
+	}
+}
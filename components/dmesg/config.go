@@ -3,15 +3,28 @@ package dmesg
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"os"
 	"os/exec"
 
 	query_config "github.com/leptonai/gpud/components/query/config"
 	query_log_config "github.com/leptonai/gpud/components/query/log/config"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type Config struct {
 	Log query_log_config.Config `json:"log"`
+
+	// DmesgPath is the dmesg command path for the watcher (see NewWatcher),
+	// for the distros that install dmesg outside of the PATH.
+	// Default is DefaultDmesgPath.
+	DmesgPath string `json:"dmesg_path,omitempty"`
+
+	// OOMCoalesceWindow is the window to coalesce the repeated OOM events
+	// of the same process into a single event with the count.
+	// Default is DefaultOOMCoalesceWindow.
+	OOMCoalesceWindow metav1.Duration `json:"oom_coalesce_window,omitempty"`
 }
 
 func ParseConfig(b any, db *sql.DB) (*Config, error) {
@@ -34,9 +47,19 @@ func ParseConfig(b any, db *sql.DB) (*Config, error) {
 }
 
 func (cfg Config) Validate() error {
+	if cfg.OOMCoalesceWindow.Duration < 0 {
+		return fmt.Errorf("oom_coalesce_window must not be negative (got %v)", cfg.OOMCoalesceWindow.Duration)
+	}
 	return cfg.Log.Validate()
 }
 
+func (cfg *Config) SetDefaultsIfNotSet() {
+	cfg.Log.SetDefaultsIfNotSet()
+	if cfg.OOMCoalesceWindow.Duration == 0 {
+		cfg.OOMCoalesceWindow = metav1.Duration{Duration: DefaultOOMCoalesceWindow}
+	}
+}
+
 func DmesgExists() bool {
 	p, err := exec.LookPath("dmesg")
 	if err != nil {
@@ -79,6 +102,8 @@ func DefaultConfig() Config {
 				LinesToTail: 10000,
 			},
 		},
+
+		OOMCoalesceWindow: metav1.Duration{Duration: DefaultOOMCoalesceWindow},
 	}
 	cfg.Log.SelectFilters = append(cfg.Log.SelectFilters, defaultFilters...)
 
@@ -1,6 +1,7 @@
 package dmesg
 
 import (
+	nvidia_error "github.com/leptonai/gpud/components/accelerator/nvidia/error"
 	"github.com/leptonai/gpud/components/memory"
 	query_log_filter "github.com/leptonai/gpud/components/query/log/filter"
 
@@ -17,31 +18,89 @@ const (
 	// postgres invoked oom-killer: gfp_mask=0x201d2, order=0, oomkilladj=0
 	EventOOMKiller      = "oom_killer"
 	EventOOMKillerRegex = `(?i)\b(invoked|triggered) oom-killer\b`
+	// The OOM killer dump ends with the constraint line (since Linux 4.19),
+	// after the call trace, the memory info, and the victim (tasks) table.
+	// e.g.,
+	// oom-kill:constraint=CONSTRAINT_NONE,nodemask=(null),cpuset=/,mems_allowed=0,global_oom,task_memcg=/,task=python,pid=123,uid=0
+	EventOOMKillerEndRegex = `oom-kill:constraint=`
 
 	// e.g.,
 	// Memory cgroup out of memory: Killed process 123, UID 48, (httpd).
 	EventOOMCgroup      = "oom_cgroup"
 	EventOOMCgroupRegex = `Memory cgroup out of memory`
+
+	// e.g.,
+	// pcieport 0000:00:01.0: AER: Corrected error received: 0000:01:00.0
+	// pcieport 0000:00:1c.5: AER: Multiple Corrected error received: 0000:00:1c.5
+	//
+	// ref. https://docs.kernel.org/PCI/pcieaer-howto.html
+	EventPCIeAERCorrected      = "pcie_aer_corrected"
+	EventPCIeAERCorrectedRegex = `\bAER: (?:Multiple )?Corrected error received\b`
+
+	// e.g.,
+	// pcieport 0000:00:03.1: AER: Uncorrected (Non-Fatal) error received: 0000:02:00.0
+	// pcieport 0000:40:01.1: AER: Multiple Uncorrected (Fatal) error received: 0000:41:00.0
+	//
+	// ref. https://docs.kernel.org/PCI/pcieaer-howto.html
+	EventPCIeAERUncorrected      = "pcie_aer_uncorrected"
+	EventPCIeAERUncorrectedRegex = `\bAER: (?:Multiple )?Uncorrected \((?:Non-Fatal|Fatal)\) error received\b`
 )
 
 var defaultFilters = []*query_log_filter.Filter{
 	{
 		Name:            EventOOMKill,
 		Regex:           ptr.To(EventOOMKillRegex),
+		Severity:        query_log_filter.SeverityError,
+		SuggestedAction: "Check the host memory usage and reduce the workloads or add more memory.",
 		OwnerReferences: []string{memory.Name},
 	},
 	{
-		Name:            EventOOMKiller,
-		Regex:           ptr.To(EventOOMKillerRegex),
+		Name:  EventOOMKiller,
+		Regex: ptr.To(EventOOMKillerRegex),
+		// captures the whole dump, including the victim (tasks) table
+		MultiLine: &query_log_filter.MultiLine{
+			EndRegex: ptr.To(EventOOMKillerEndRegex),
+		},
+		Severity:        query_log_filter.SeverityWarn,
+		SuggestedAction: "Check the host memory usage of the processes.",
 		OwnerReferences: []string{memory.Name},
 	},
 	{
 		Name:            EventOOMCgroup,
 		Regex:           ptr.To(EventOOMCgroupRegex),
+		Severity:        query_log_filter.SeverityWarn,
+		SuggestedAction: "Increase the memory limit of the container or reduce its memory usage.",
 		OwnerReferences: []string{memory.Name},
 	},
+	{
+		Name:            EventPCIeAERCorrected,
+		Regex:           ptr.To(EventPCIeAERCorrectedRegex),
+		Severity:        query_log_filter.SeverityInfo,
+		SuggestedAction: "Monitor the error rate; frequent corrected errors may indicate a degrading PCIe link.",
+		OwnerReferences: []string{Name},
+	},
+	{
+		Name:            EventPCIeAERUncorrected,
+		Regex:           ptr.To(EventPCIeAERUncorrectedRegex),
+		Severity:        query_log_filter.SeverityError,
+		SuggestedAction: "Check the PCIe device and its link; reseat or replace the device if the error persists.",
+		OwnerReferences: []string{Name},
+	},
+	{
+		Name:            EventNvidiaGPUFallenOffBus,
+		Regex:           ptr.To(EventNvidiaGPUFallenOffBusRegex),
+		Severity:        query_log_filter.SeverityError,
+		SuggestedAction: "Reboot the node; if the GPU is still missing, inspect the GPU hardware (power, PCIe link, thermal).",
+		OwnerReferences: []string{nvidia_error.Name},
+	},
 }
 
 func DefaultLogFilters() []*query_log_filter.Filter {
 	return defaultFilters
 }
+
+// Returns the default filters owned by the component (e.g., memory.Name for the OOM filters),
+// so that the component can fetch the filters it routes at startup.
+func FiltersForOwner(owner string) []*query_log_filter.Filter {
+	return query_log_filter.FiltersForOwner(defaultFilters, owner)
+}
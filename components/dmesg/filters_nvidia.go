@@ -1,6 +1,8 @@
 package dmesg
 
 import (
+	"regexp"
+
 	nvidia_error "github.com/leptonai/gpud/components/accelerator/nvidia/error"
 	nvidia_query "github.com/leptonai/gpud/components/accelerator/nvidia/query"
 	nvidia_query_sxid "github.com/leptonai/gpud/components/accelerator/nvidia/query/sxid"
@@ -39,8 +41,30 @@ const (
 	// "D.4 Non-Fatal NVSwitch SXid Errors"
 	// https://docs.nvidia.com/datacenter/tesla/pdf/fabric-manager-user-guide.pdf
 	EventNvidiaNVSwitchSXid = "nvidia_nvswitch_sxid"
+
+	// e.g.,
+	// [ 3462.891343] NVRM: GPU 0000:3b:00.0: GPU has fallen off the bus.
+	// NVRM: GPU at PCI:0000:3b:00: GPU has fallen off the bus.
+	//
+	// The GPU is no longer accessible from the host (e.g., power or PCIe link failure),
+	// and requires the node reboot at the minimum, and often the hardware inspection.
+	EventNvidiaGPUFallenOffBus = "nvidia_gpu_fallen_off_bus"
+	// The first submatch is the PCI address of the GPU (e.g., "0000:3b:00.0").
+	EventNvidiaGPUFallenOffBusRegex = `NVRM: GPU (?:at )?(?:PCI:)?([0-9a-fA-F]{4,8}:[0-9a-fA-F]{2}:[0-9a-fA-F]{2}(?:\.[0-7])?):? GPU has fallen off the bus`
 )
 
+var regexNvidiaGPUFallenOffBus = regexp.MustCompile(EventNvidiaGPUFallenOffBusRegex)
+
+// Returns the PCI address of the GPU fallen off the bus, from the dmesg line.
+// Returns false if the line does not match.
+func ParseGPUFallenOffBusPCI(line string) (string, bool) {
+	m := regexNvidiaGPUFallenOffBus.FindStringSubmatch(line)
+	if len(m) != 2 {
+		return "", false
+	}
+	return m[1], true
+}
+
 func DefaultDmesgFiltersForNvidia() []*query_log_filter.Filter {
 	return []*query_log_filter.Filter{
 		{
@@ -1,8 +1,14 @@
 package dmesg
 
 import (
+	"reflect"
 	"regexp"
 	"testing"
+
+	nvidia_error "github.com/leptonai/gpud/components/accelerator/nvidia/error"
+	"github.com/leptonai/gpud/components/memory"
+	query_log "github.com/leptonai/gpud/components/query/log"
+	query_log_filter "github.com/leptonai/gpud/components/query/log/filter"
 )
 
 func TestOOMRegexes(t *testing.T) {
@@ -51,6 +57,30 @@ func TestOOMRegexes(t *testing.T) {
 				"Out of memory error in container",
 			},
 		},
+		{
+			name:  "PCIeAERCorrected",
+			regex: EventPCIeAERCorrectedRegex,
+			matches: []string{
+				"pcieport 0000:00:01.0: AER: Corrected error received: 0000:01:00.0",
+				"[Mon Jul  8 10:01:02 2024] pcieport 0000:00:1c.5: AER: Multiple Corrected error received: 0000:00:1c.5",
+			},
+			notMatch: []string{
+				"pcieport 0000:00:03.1: AER: Uncorrected (Non-Fatal) error received: 0000:02:00.0",
+				"pcieport 0000:00:01.0: AER: enabled with IRQ 122",
+			},
+		},
+		{
+			name:  "PCIeAERUncorrected",
+			regex: EventPCIeAERUncorrectedRegex,
+			matches: []string{
+				"pcieport 0000:00:03.1: AER: Uncorrected (Non-Fatal) error received: 0000:02:00.0",
+				"pcieport 0000:40:01.1: AER: Multiple Uncorrected (Fatal) error received: 0000:41:00.0",
+			},
+			notMatch: []string{
+				"pcieport 0000:00:01.0: AER: Corrected error received: 0000:01:00.0",
+				"pcieport 0000:00:01.0: AER: enabled with IRQ 122",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -71,3 +101,76 @@ func TestOOMRegexes(t *testing.T) {
 		})
 	}
 }
+
+func TestFiltersForOwner(t *testing.T) {
+	t.Parallel()
+
+	owned := FiltersForOwner(memory.Name)
+	names := make([]string, 0, len(owned))
+	for _, f := range owned {
+		names = append(names, f.Name)
+	}
+	expected := []string{EventOOMKill, EventOOMKiller, EventOOMCgroup}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expected filters %v for %q, got %v", expected, memory.Name, names)
+	}
+
+	for _, f := range FiltersForOwner(Name) {
+		if f.Name == EventOOMKill || f.Name == EventOOMKiller || f.Name == EventOOMCgroup {
+			t.Fatalf("unexpected OOM filter %q for %q", f.Name, Name)
+		}
+	}
+}
+
+func TestGPUFallenOffBus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		line string
+		pci  string
+	}{
+		{line: "[ 3462.891343] NVRM: GPU 0000:3b:00.0: GPU has fallen off the bus.", pci: "0000:3b:00.0"},
+		{line: "NVRM: GPU at PCI:0000:86:00: GPU has fallen off the bus.", pci: "0000:86:00"},
+		{line: "kern  :err   : 2025-01-21T04:41:44,285060+00:00 NVRM: GPU 0000:DB:00.0: GPU has fallen off the bus.", pci: "0000:DB:00.0"},
+	}
+	for _, tt := range tests {
+		pci, ok := ParseGPUFallenOffBusPCI(tt.line)
+		if !ok || pci != tt.pci {
+			t.Errorf("ParseGPUFallenOffBusPCI(%q) = %q, %v, want %q", tt.line, pci, ok, tt.pci)
+		}
+	}
+	for _, line := range []string{
+		// handled by the Xid filter
+		"NVRM: Xid (PCI:0000:01:00): 79, pid='<unknown>', name=<unknown>, GPU has fallen off the bus.",
+		"NVRM: GPU at PCI:0000:3b:00: GPU-6f3d3d5b-9c1e-4a3c-9d2a-3e5f1c2b4a6d",
+	} {
+		if _, ok := ParseGPUFallenOffBusPCI(line); ok {
+			t.Errorf("ParseGPUFallenOffBusPCI(%q) unexpectedly matched", line)
+		}
+	}
+
+	var filter *query_log_filter.Filter
+	for _, f := range DefaultLogFilters() {
+		if f.Name == EventNvidiaGPUFallenOffBus {
+			filter = f
+		}
+	}
+	if filter == nil {
+		t.Fatalf("expected the %q filter in the default filters", EventNvidiaGPUFallenOffBus)
+	}
+	if filter.Severity != query_log_filter.SeverityError || !filter.OwnedBy(nvidia_error.Name) {
+		t.Fatalf("unexpected filter %+v", filter)
+	}
+	if ok, err := filter.MatchString(tests[0].line); err != nil || !ok {
+		t.Fatalf("expected the filter to match %q (error %v)", tests[0].line, err)
+	}
+
+	ev := &Event{Matched: []query_log.Item{{Line: tests[0].line, Matched: filter}}}
+	evs := ev.Events()
+	if len(evs) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(evs))
+	}
+	if evs[0].Type != query_log_filter.SeverityError || evs[0].ExtraInfo[EventKeyDmesgMatchedPCIAddress] != "0000:3b:00.0" {
+		t.Fatalf("unexpected event %+v", evs[0])
+	}
+}
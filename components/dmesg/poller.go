@@ -38,11 +38,15 @@ func GetDefaultLogPoller() query_log.Poller {
 var regexForDmesgTime = regexp.MustCompile(`^\[([^\]]+)\]`)
 
 // does not return error for now
-// assume "dmesg --ctime" is used
+// assume "dmesg --ctime" is used, and falls back to ParseDmesgTimestamp
 // TODO: once stable return error
 func ExtractTimeFromLogLine(line []byte) (time.Time, error) {
 	matches := regexForDmesgTime.FindStringSubmatch(string(line))
 	if len(matches) == 0 {
+		// fallback to the syslog form (e.g., "Jul 09 18:14:07")
+		if t, ok := ParseDmesgTimestamp(string(line)); ok {
+			return t, nil
+		}
 		log.Logger.Debugw("no timestamp matches found", "line", string(line))
 		return time.Time{}, nil
 	}
@@ -50,6 +54,10 @@ func ExtractTimeFromLogLine(line []byte) (time.Time, error) {
 	s := matches[1]
 	timestamp, err := time.Parse("Mon Jan 2 15:04:05 2006", s)
 	if err != nil {
+		// fallback to the monotonic form (e.g., "[   12.345678]")
+		if t, ok := ParseDmesgTimestamp(string(line)); ok {
+			return t, nil
+		}
 		log.Logger.Debugw("failed to parse timestamp", "line", string(line), "error", err)
 		return time.Time{}, nil
 	}
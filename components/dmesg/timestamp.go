@@ -0,0 +1,91 @@
+package dmesg
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/host"
+)
+
+var (
+	// e.g.,
+	// [   12.345678] nvidia-nvswitch0: ...
+	regexDmesgMonotonicTime = regexp.MustCompile(`^\[\s*(\d+)\.(\d+)\]`)
+
+	// e.g.,
+	// Jul 09 18:14:07 host kernel: ...
+	// Jul  9 18:14:07 host kernel: ...
+	regexDmesgSyslogTime = regexp.MustCompile(`^([A-Z][a-z]{2}\s+\d{1,2} \d{2}:\d{2}:\d{2})\b`)
+)
+
+const dmesgSyslogTimeLayout = "Jan _2 15:04:05"
+
+var (
+	bootTimeOnce sync.Once
+	bootTime     time.Time
+	bootTimeErr  error
+)
+
+// Returns the host boot time, only read once.
+func getBootTime() (time.Time, error) {
+	bootTimeOnce.Do(func() {
+		var secs uint64
+		secs, bootTimeErr = host.BootTime()
+		if bootTimeErr == nil {
+			bootTime = time.Unix(int64(secs), 0)
+		}
+	})
+	return bootTime, bootTimeErr
+}
+
+// Parses the timestamp of the kernel log line in either the
+// monotonic seconds since boot form (e.g., "[   12.345678]"),
+// resolved against the host boot time, or the syslog form
+// (e.g., "Jul 09 18:14:07") in the local time zone.
+// Returns false if neither matches.
+func ParseDmesgTimestamp(line string) (time.Time, bool) {
+	return parseDmesgTimestamp(line, getBootTime, time.Now())
+}
+
+func parseDmesgTimestamp(line string, bootTimeFunc func() (time.Time, error), now time.Time) (time.Time, bool) {
+	if m := regexDmesgMonotonicTime.FindStringSubmatch(line); len(m) == 3 {
+		secs, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		// e.g., "345678" is 345678 microseconds (right-pad to nanoseconds)
+		frac := m[2]
+		if len(frac) > 9 {
+			frac = frac[:9]
+		}
+		nanos, err := strconv.ParseInt(frac+strings.Repeat("0", 9-len(frac)), 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		boot, err := bootTimeFunc()
+		if err != nil {
+			return time.Time{}, false
+		}
+		return boot.Add(time.Duration(secs)*time.Second + time.Duration(nanos)), true
+	}
+
+	if m := regexDmesgSyslogTime.FindStringSubmatch(line); len(m) == 2 {
+		t, err := time.ParseInLocation(dmesgSyslogTimeLayout, m[1], now.Location())
+		if err != nil {
+			return time.Time{}, false
+		}
+
+		// syslog timestamps have no year
+		// assume the current year, unless it is in the future (e.g., logs from Dec read in Jan)
+		t = t.AddDate(now.Year(), 0, 0)
+		if t.After(now.Add(24 * time.Hour)) {
+			t = t.AddDate(-1, 0, 0)
+		}
+		return t, true
+	}
+
+	return time.Time{}, false
+}
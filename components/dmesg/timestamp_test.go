@@ -0,0 +1,73 @@
+package dmesg
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseDmesgTimestamp(t *testing.T) {
+	t.Parallel()
+
+	boot := time.Date(2024, time.July, 9, 10, 0, 0, 0, time.UTC)
+	bootTimeFunc := func() (time.Time, error) { return boot, nil }
+	now := time.Date(2024, time.July, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		line   string
+		want   time.Time
+		wantOK bool
+	}{
+		{
+			name:   "monotonic",
+			line:   "[   12.345678] nvidia-nvswitch0: SXid (PCI:0000:00:00.0): 20034, Fatal, Link 30 LTSSM Fault Up",
+			want:   boot.Add(12*time.Second + 345678*time.Microsecond),
+			wantOK: true,
+		},
+		{
+			name:   "monotonic without padding",
+			line:   "[131453.740743] NVRM: Xid (PCI:0000:01:00): 79, GPU has fallen off the bus.",
+			want:   boot.Add(131453*time.Second + 740743*time.Microsecond),
+			wantOK: true,
+		},
+		{
+			name:   "syslog",
+			line:   "Jul 09 18:14:07 host kernel: Out of memory: Killed process 123",
+			want:   time.Date(2024, time.July, 9, 18, 14, 7, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:   "syslog with space padded day",
+			line:   "Jul  9 18:14:07 host kernel: Out of memory: Killed process 123",
+			want:   time.Date(2024, time.July, 9, 18, 14, 7, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:   "syslog from last year",
+			line:   "Dec 31 23:59:59 host kernel: Out of memory: Killed process 123",
+			want:   time.Date(2023, time.December, 31, 23, 59, 59, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:   "no timestamp",
+			line:   "Out of memory: Killed process 123",
+			wantOK: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseDmesgTimestamp(tt.line, bootTimeFunc, now)
+			if ok != tt.wantOK {
+				t.Fatalf("expected ok %v, got %v", tt.wantOK, ok)
+			}
+			if !got.Equal(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	if _, ok := parseDmesgTimestamp("[   12.345678] test", func() (time.Time, error) { return time.Time{}, errors.New("no boot time") }, now); ok {
+		t.Fatal("expected not ok without boot time")
+	}
+}
@@ -0,0 +1,266 @@
+package dmesg
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/leptonai/gpud/components"
+	query_log "github.com/leptonai/gpud/components/query/log"
+	query_log_filter "github.com/leptonai/gpud/components/query/log/filter"
+	"github.com/leptonai/gpud/log"
+	"github.com/leptonai/gpud/pkg/process"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultDmesgPath is the default dmesg command path,
+// resolved via the PATH environment variable.
+const DefaultDmesgPath = "dmesg"
+
+// DefaultWatchRestartInterval is the interval to restart
+// the "dmesg --follow" command when it exits with an error.
+const DefaultWatchRestartInterval = 5 * time.Second
+
+// Watcher continuously tails the "dmesg --follow" output
+// and emits the lines matching the filters as events.
+type Watcher interface {
+	// Returns the channel of the matched events,
+	// closed when the watcher is closed.
+	Events() <-chan components.Event
+	// Stops the dmesg command (and its process group)
+	// and waits for the watcher to exit.
+	Close()
+}
+
+// NewWatcher starts watching the dmesg outputs with the command
+// path from the config (see Config.DmesgPath), and the select filters
+// from the config (default to DefaultLogFilters if empty).
+// The timestamps are parsed via ParseDmesgTimestamp.
+func NewWatcher(ctx context.Context, cfg Config) (Watcher, error) {
+	dmesgPath := cfg.DmesgPath
+	if dmesgPath == "" {
+		dmesgPath = DefaultDmesgPath
+	}
+
+	filters := cfg.Log.SelectFilters
+	if len(filters) == 0 {
+		filters = DefaultLogFilters()
+	}
+	// copy the filters, as they are shared with the log poller
+	// and compiled lazily on the first match
+	compiled := make([]*query_log_filter.Filter, 0, len(filters))
+	for _, f := range filters {
+		cp := *f
+		if f.MultiLine != nil {
+			ml := *f.MultiLine
+			cp.MultiLine = &ml
+		}
+		if err := cp.Compile(); err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, &cp)
+	}
+
+	// the pipe persists across the command restarts
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+
+	proc, err := process.New(
+		[][]string{{dmesgPath, "--follow"}},
+		process.WithOutputFile(pw),
+		process.WithRestartConfig(process.RestartConfig{
+			OnError:  true,
+			Interval: DefaultWatchRestartInterval,
+		}),
+	)
+	if err != nil {
+		_ = pr.Close()
+		_ = pw.Close()
+		return nil, err
+	}
+
+	cctx, ccancel := context.WithCancel(ctx)
+	if err := proc.Start(cctx); err != nil {
+		ccancel()
+		_ = pr.Close()
+		_ = pw.Close()
+		return nil, err
+	}
+
+	w := &watcher{
+		ctx:     cctx,
+		cancel:  ccancel,
+		proc:    proc,
+		pr:      pr,
+		pw:      pw,
+		filters: compiled,
+		eventc:  make(chan components.Event, 200),
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.readLoop()
+	}()
+
+	return w, nil
+}
+
+var _ Watcher = (*watcher)(nil)
+
+type watcher struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	proc   process.Process
+
+	pr *os.File
+	pw *os.File
+
+	filters []*query_log_filter.Filter
+	eventc  chan components.Event
+
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+func (w *watcher) Events() <-chan components.Event {
+	return w.eventc
+}
+
+func (w *watcher) Close() {
+	w.closeOnce.Do(func() {
+		w.cancel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := w.proc.Stop(ctx); err != nil && !errors.Is(err, process.ErrProcessKilled) {
+			log.Logger.Warnw("failed to stop dmesg watch command", "error", err)
+		}
+
+		// unblocks the reader with EOF
+		_ = w.pw.Close()
+		w.wg.Wait()
+		_ = w.pr.Close()
+	})
+}
+
+// The interval to check the incomplete multi-line block for the idle timeout
+// (see query_log_filter.MultiLine.IdleTimeout).
+const multiLineIdleCheckInterval = time.Second
+
+func (w *watcher) readLoop() {
+	defer close(w.eventc)
+
+	// the restarted command replays the kernel ring buffer,
+	// so skip the lines older than the last seen one
+	var lastSeen time.Time
+
+	// assembles the multi-line blocks (e.g., OOM killer dumps)
+	var assembler query_log_filter.BlockAssembler[time.Time]
+
+	// reads the lines in the background, so that the incomplete multi-line block
+	// (e.g., no end line on the older kernels) is emitted while waiting for the next line
+	linec := make(chan string)
+	go func() {
+		defer close(linec)
+
+		scanner := bufio.NewScanner(w.pr)
+		for scanner.Scan() {
+			select {
+			case <-w.ctx.Done():
+				return
+			case linec <- scanner.Text():
+			}
+		}
+		if err := scanner.Err(); err != nil && !errors.Is(err, os.ErrClosed) {
+			log.Logger.Warnw("failed to read dmesg watch output", "error", err)
+		}
+	}()
+
+	ticker := time.NewTicker(multiLineIdleCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+
+		case now := <-ticker.C:
+			if done := assembler.FlushIdle(now); done != nil && !w.emit(done.First, done.Text(), done.Filter) {
+				return
+			}
+
+		case line, ok := <-linec:
+			if !ok {
+				// the output is closed, emit the incomplete multi-line block
+				if done := assembler.Flush(); done != nil {
+					w.emit(done.First, done.Text(), done.Filter)
+				}
+				return
+			}
+
+			ts, ok := ParseDmesgTimestamp(line)
+			if ok {
+				if ts.Before(lastSeen) {
+					continue
+				}
+				lastSeen = ts
+			} else {
+				ts = time.Now().UTC()
+			}
+
+			matched := w.match(line)
+			done, started := assembler.Feed(line, ts, matched)
+			if done != nil && !w.emit(done.First, done.Text(), done.Filter) {
+				return
+			}
+			if matched == nil || started {
+				continue
+			}
+			if !w.emit(ts, line, matched) {
+				return
+			}
+		}
+	}
+}
+
+// Emits the events of the matched line (or multi-line block).
+// Returns false if the watcher is closed.
+func (w *watcher) emit(ts time.Time, line string, matched *query_log_filter.Filter) bool {
+	ev := &Event{Matched: []query_log.Item{{
+		Time:    metav1.NewTime(ts),
+		Line:    line,
+		Matched: matched,
+	}}}
+	for _, e := range ev.Events() {
+		select {
+		case <-w.ctx.Done():
+			return false
+		case w.eventc <- e:
+		default:
+			log.Logger.Debugw("event channel is full -- dropped dmesg event", "line", line)
+		}
+	}
+	return true
+}
+
+// Returns the first filter matching the line, or nil if none matches.
+func (w *watcher) match(line string) *query_log_filter.Filter {
+	for _, f := range w.filters {
+		ok, err := f.MatchString(line)
+		if err != nil {
+			log.Logger.Warnw("failed to match dmesg line", "error", err)
+			continue
+		}
+		if ok {
+			return f
+		}
+	}
+	return nil
+}
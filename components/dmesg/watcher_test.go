@@ -0,0 +1,182 @@
+package dmesg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatcher(t *testing.T) {
+	t.Parallel()
+
+	// fake dmesg command that prints the lines and keeps following
+	dmesgPath := filepath.Join(t.TempDir(), "dmesg")
+	script := `#!/bin/sh
+echo "[   10.000000] eth0: link up"
+echo "[   11.000000] Out of memory: Killed process 123 (python)"
+echo "[   12.000000] pcieport 0000:00:01.0: AER: Corrected error received: 0000:01:00.0"
+exec sleep 1000
+`
+	if err := os.WriteFile(dmesgPath, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	w, err := NewWatcher(ctx, Config{DmesgPath: dmesgPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{EventOOMKill, EventPCIeAERCorrected}
+	for _, name := range expected {
+		select {
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for %q", name)
+		case ev := <-w.Events():
+			if ev.Name != EventNameDmesgMatched {
+				t.Fatalf("unexpected event name %q", ev.Name)
+			}
+			item, err := ParseEventDmesgMatched(ev.ExtraInfo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if item.Matched == nil || item.Matched.Name != name {
+				t.Fatalf("expected filter %q, got %+v", name, item.Matched)
+			}
+			if ev.Time.IsZero() {
+				t.Fatal("expected timestamp to be parsed")
+			}
+		}
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		w.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-ctx.Done():
+		t.Fatal("timed out closing the watcher")
+	}
+
+	// the events channel is closed
+	for range w.Events() {
+	}
+}
+
+func TestNewWatcherCommandNotFound(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewWatcher(context.Background(), Config{DmesgPath: filepath.Join(t.TempDir(), "not-exist")})
+	if err == nil {
+		t.Fatal("expected error for missing dmesg command")
+	}
+}
+
+func TestWatcherOOMKillerDump(t *testing.T) {
+	t.Parallel()
+
+	dmesgPath := filepath.Join(t.TempDir(), "dmesg")
+	script := `#!/bin/sh
+echo "[   10.000000] python invoked oom-killer: gfp_mask=0x140cca(GFP_HIGHUSER_MOVABLE|__GFP_COMP), order=0, oom_score_adj=0"
+echo "[   10.000001] CPU: 3 PID: 123 Comm: python Not tainted 5.15.0-1053-nvidia #54-Ubuntu"
+echo "[   10.000002] Call Trace:"
+echo "[   10.000003]  dump_stack_lvl+0x48/0x70"
+echo "[   10.000004] Tasks state (memory values in pages):"
+echo "[   10.000005] [  pid  ]   uid  tgid total_vm      rss pgtables_bytes swapents oom_score_adj name"
+echo "[   10.000006] [    123]     0   123  8388608  8123456 66060288        0             0 python"
+echo "[   10.000007] oom-kill:constraint=CONSTRAINT_NONE,nodemask=(null),cpuset=/,mems_allowed=0,global_oom,task_memcg=/,task=python,pid=123,uid=0"
+echo "[   10.000008] Out of memory: Killed process 123 (python) total-vm:33554432kB, anon-rss:32493824kB"
+exec sleep 1000
+`
+	if err := os.WriteFile(dmesgPath, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	w, err := NewWatcher(ctx, Config{DmesgPath: dmesgPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	for _, name := range []string{EventOOMKiller, EventOOMKill} {
+		select {
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for %q", name)
+		case ev := <-w.Events():
+			item, err := ParseEventDmesgMatched(ev.ExtraInfo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if item.Matched == nil || item.Matched.Name != name {
+				t.Fatalf("expected filter %q, got %+v", name, item.Matched)
+			}
+			if name != EventOOMKiller {
+				continue
+			}
+
+			lines := strings.Split(item.Line, "\n")
+			if len(lines) != 8 {
+				t.Fatalf("expected 8 lines in the OOM killer dump, got %d:\n%s", len(lines), item.Line)
+			}
+			if !strings.Contains(item.Line, "8123456 66060288        0             0 python") {
+				t.Fatalf("expected the victim table in the OOM killer dump:\n%s", item.Line)
+			}
+			if !strings.HasPrefix(lines[len(lines)-1], "[   10.000007] oom-kill:constraint=") {
+				t.Fatalf("expected the dump to end at the constraint line, got %q", lines[len(lines)-1])
+			}
+		}
+	}
+}
+
+func TestWatcherOOMKillerDumpNoEnd(t *testing.T) {
+	t.Parallel()
+
+	// the kernels before 4.19 never print the "oom-kill:constraint=" line
+	dmesgPath := filepath.Join(t.TempDir(), "dmesg")
+	script := `#!/bin/sh
+echo "[   10.000000] python invoked oom-killer: gfp_mask=0x140cca(GFP_HIGHUSER_MOVABLE|__GFP_COMP), order=0, oom_score_adj=0"
+echo "[   10.000001] CPU: 3 PID: 123 Comm: python Not tainted 4.15.0-213-generic #224-Ubuntu"
+echo "[   10.000002] Call Trace:"
+echo "[   10.000003]  dump_stack+0x6d/0x8b"
+exec sleep 1000
+`
+	if err := os.WriteFile(dmesgPath, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	w, err := NewWatcher(ctx, Config{DmesgPath: dmesgPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	// emitted after the idle timeout, while the followed stream is still open
+	select {
+	case <-ctx.Done():
+		t.Fatalf("timed out waiting for %q", EventOOMKiller)
+	case ev := <-w.Events():
+		item, err := ParseEventDmesgMatched(ev.ExtraInfo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if item.Matched == nil || item.Matched.Name != EventOOMKiller {
+			t.Fatalf("expected filter %q, got %+v", EventOOMKiller, item.Matched)
+		}
+		if lines := strings.Split(item.Line, "\n"); len(lines) != 4 {
+			t.Fatalf("expected 4 lines in the OOM killer dump, got %d:\n%s", len(lines), item.Line)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/leptonai/gpud/components"
 	components_metrics "github.com/leptonai/gpud/components/metrics"
@@ -105,7 +106,9 @@ func getDefaultPoller() query.Poller {
 
 func CreateGet(cfg Config) query.GetFunc {
 	return func(ctx context.Context) (_ any, e error) {
+		start := time.Now()
 		defer func() {
+			components_metrics.SetGetLatency(Name, time.Since(start))
 			if e != nil {
 				components_metrics.SetGetFailed(Name)
 			} else {
@@ -0,0 +1,57 @@
+package components
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultMaxEvents is the default maximum number of events
+	// returned by a single Events call.
+	DefaultMaxEvents = 1000
+
+	// Sentinel event prepended when the events are capped,
+	// to mark that the older events have been dropped.
+	EventNameEventsTruncated = "events_truncated"
+	// Number of the older events dropped.
+	EventKeyEventsTruncatedDropped = "dropped"
+)
+
+// Caps the events to the most recent "max" events (by time),
+// to bound the allocations and the response size for a "since"
+// far in the past. When capped, the sentinel event
+// (see EventNameEventsTruncated) is prepended, at the time
+// of the latest dropped event.
+// Callers that need all the events should paginate by querying
+// narrower windows (advancing "since" in smaller steps).
+// Zero max uses DefaultMaxEvents, and negative disables the cap.
+func CapEvents(evs []Event, max int) []Event {
+	if max == 0 {
+		max = DefaultMaxEvents
+	}
+	if max < 0 || len(evs) <= max {
+		return evs
+	}
+
+	sorted := make([]Event, len(evs))
+	copy(sorted, evs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time.Before(&sorted[j].Time)
+	})
+
+	dropped := len(sorted) - max
+	capped := make([]Event, 0, max+1)
+	capped = append(capped, Event{
+		Time:    metav1.Time{Time: sorted[dropped-1].Time.Time},
+		Name:    EventNameEventsTruncated,
+		Type:    EventTypeWarn,
+		Message: fmt.Sprintf("%d older events dropped (max %d events), query a narrower window to get them", dropped, max),
+		ExtraInfo: map[string]string{
+			EventKeyEventsTruncatedDropped: strconv.Itoa(dropped),
+		},
+	})
+	return append(capped, sorted[dropped:]...)
+}
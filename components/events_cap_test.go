@@ -0,0 +1,50 @@
+package components
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCapEvents(t *testing.T) {
+	t.Parallel()
+
+	base := time.Unix(1000, 0).UTC()
+	evs := make([]Event, 0, 10)
+	// out of order, to check the most recent ones are kept
+	for _, i := range []int{3, 0, 1, 2, 9, 4, 5, 6, 8, 7} {
+		evs = append(evs, Event{Time: metav1.Time{Time: base.Add(time.Duration(i) * time.Second)}, Name: "test"})
+	}
+
+	if got := CapEvents(evs, 10); len(got) != 10 {
+		t.Fatalf("expected no cap, got %d events", len(got))
+	}
+	if got := CapEvents(evs, -1); len(got) != 10 {
+		t.Fatalf("expected no cap when disabled, got %d events", len(got))
+	}
+	if got := CapEvents(evs, 0); len(got) != 10 {
+		t.Fatalf("expected the default cap not to apply, got %d events", len(got))
+	}
+
+	got := CapEvents(evs, 3)
+	if len(got) != 4 {
+		t.Fatalf("expected 3 events and the sentinel, got %d", len(got))
+	}
+	if got[0].Name != EventNameEventsTruncated || got[0].ExtraInfo[EventKeyEventsTruncatedDropped] != "7" {
+		t.Fatalf("unexpected sentinel event %+v", got[0])
+	}
+	if !got[0].Time.Equal(&metav1.Time{Time: base.Add(6 * time.Second)}) {
+		t.Fatalf("expected the sentinel at the latest dropped event, got %v", got[0].Time)
+	}
+	for i, want := range []int{7, 8, 9} {
+		if !got[i+1].Time.Time.Equal(base.Add(time.Duration(want) * time.Second)) {
+			t.Fatalf("event %d: expected time %d, got %v", i, want, got[i+1].Time)
+		}
+	}
+
+	many := make([]Event, DefaultMaxEvents+5)
+	if got := CapEvents(many, 0); len(got) != DefaultMaxEvents+1 {
+		t.Fatalf("expected the default cap, got %d events", len(got))
+	}
+}
@@ -0,0 +1,44 @@
+package components
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Healthz collects the states from all the given components,
+// and returns the overall health of the node.
+// Returns false if any state is unhealthy or any component fails
+// to return its states, along with the list of unhealthy states.
+// A failing component does not stop the rollup: the states from
+// the remaining components are still collected, and the errors
+// are joined into the returned error.
+func Healthz(ctx context.Context, comps ...Component) (bool, []State, error) {
+	healthy := true
+	unhealthy := make([]State, 0)
+	var errs []error
+	for _, c := range comps {
+		if c == nil {
+			continue
+		}
+
+		states, err := c.States(ctx)
+		if err != nil {
+			healthy = false
+			errs = append(errs, fmt.Errorf("component %s: %w", c.Name(), err))
+			continue
+		}
+
+		for _, s := range states {
+			if s.Healthy {
+				continue
+			}
+			if s.Name == "" {
+				s.Name = c.Name()
+			}
+			healthy = false
+			unhealthy = append(unhealthy, s)
+		}
+	}
+	return healthy, unhealthy, errors.Join(errs...)
+}
@@ -0,0 +1,68 @@
+package components
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type mockComponent struct {
+	name   string
+	states []State
+	err    error
+}
+
+func (m *mockComponent) Name() string { return m.name }
+
+func (m *mockComponent) States(ctx context.Context) ([]State, error) {
+	return m.states, m.err
+}
+
+func (m *mockComponent) Events(ctx context.Context, since time.Time) ([]Event, error) {
+	return nil, nil
+}
+
+func (m *mockComponent) Metrics(ctx context.Context, since time.Time) ([]Metric, error) {
+	return nil, nil
+}
+
+func (m *mockComponent) Close() error { return nil }
+
+func TestHealthz(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	healthy, unhealthy, err := Healthz(ctx)
+	if !healthy || len(unhealthy) != 0 || err != nil {
+		t.Fatalf("expected healthy with no components, got %v %v %v", healthy, unhealthy, err)
+	}
+
+	a := &mockComponent{name: "a", states: []State{{Name: "a", Healthy: true}}}
+	b := &mockComponent{name: "b", states: []State{{Healthy: true}, {Healthy: false, Reason: "bad"}}}
+	healthy, unhealthy, err = Healthz(ctx, a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if healthy {
+		t.Fatal("expected unhealthy")
+	}
+	if len(unhealthy) != 1 || unhealthy[0].Name != "b" || unhealthy[0].Reason != "bad" {
+		t.Fatalf("unexpected unhealthy states %+v", unhealthy)
+	}
+
+	errFailed := errors.New("failed")
+	c := &mockComponent{name: "c", err: errFailed}
+	d := &mockComponent{name: "d", states: []State{{Name: "d", Healthy: false}}}
+	healthy, unhealthy, err = Healthz(ctx, a, c, d)
+	if healthy {
+		t.Fatal("expected unhealthy")
+	}
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("expected joined error to wrap %v, got %v", errFailed, err)
+	}
+	if len(unhealthy) != 1 || unhealthy[0].Name != "d" {
+		t.Fatalf("expected partial results from the remaining components, got %+v", unhealthy)
+	}
+}
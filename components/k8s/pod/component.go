@@ -1,8 +1,10 @@
-// Package pod tracks the current pods from the kubelet read-only port.
+// Package pod tracks the current pods from the kubelet read-only port
+// (or the authenticated port if configured).
 package pod
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -13,8 +15,13 @@ import (
 
 const Name = "k8s-pod"
 
+const Description = "Tracks the Kubernetes pods on the node from the kubelet."
+
+var tags = []string{"container", "kubernetes", "pod"}
+
 func New(ctx context.Context, cfg Config) components.Component {
 	cfg.Query.SetDefaultsIfNotSet()
+	cfg.SetDefaultsIfNotSet()
 	setDefaultPoller(cfg)
 
 	cctx, ccancel := context.WithCancel(ctx)
@@ -24,6 +31,7 @@ func New(ctx context.Context, cfg Config) components.Component {
 	})
 
 	return &component{
+		cfg:     cfg,
 		rootCtx: ctx,
 		cancel:  ccancel,
 		poller:  GetDefaultPoller(),
@@ -33,6 +41,7 @@ func New(ctx context.Context, cfg Config) components.Component {
 var _ components.Component = (*component)(nil)
 
 type component struct {
+	cfg     Config
 	rootCtx context.Context
 	cancel  context.CancelFunc
 	poller  query.Poller
@@ -40,8 +49,30 @@ type component struct {
 
 func (c *component) Name() string { return Name }
 
-func (c *component) States(ctx context.Context) ([]components.State, error) {
+func (c *component) Describe() components.ComponentInfo {
+	return components.ComponentInfo{
+		Name:        Name,
+		Description: Description,
+		Tags:        append([]string(nil), tags...),
+	}
+}
+
+var _ components.Readier = (*component)(nil)
+
+// Returns true if the poller has produced at least one result.
+func (c *component) Ready() bool {
+	if c.poller == nil {
+		return false
+	}
 	last, err := c.poller.Last()
+	return err == nil && last != nil
+}
+
+func (c *component) States(ctx context.Context) ([]components.State, error) {
+	last, err := components.FetchWithTimeout(ctx, c.cfg.Query.StatesTimeout.Duration, c.poller.Last)
+	if errors.Is(err, components.ErrFetchTimeout) {
+		return []components.State{components.TimeoutState(Name, err)}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -52,19 +83,21 @@ func (c *component) States(ctx context.Context) ([]components.State, error) {
 	if last.Error != nil {
 		return []components.State{
 			{
-				Name:    Name,
-				Healthy: false,
-				Error:   last.Error.Error(),
-				Reason:  "last query failed",
+				Name:       Name,
+				Healthy:    false,
+				Error:      last.Error.Error(),
+				Reason:     "last query failed",
+				ReasonCode: components.ReasonQueryFailed,
 			},
 		}, nil
 	}
 	if last.Output == nil {
 		return []components.State{
 			{
-				Name:    Name,
-				Healthy: false,
-				Reason:  "no output",
+				Name:       Name,
+				Healthy:    false,
+				Reason:     "no output",
+				ReasonCode: components.ReasonNoOutput,
 			},
 		}, nil
 	}
@@ -77,7 +110,12 @@ func (c *component) States(ctx context.Context) ([]components.State, error) {
 }
 
 func (c *component) Events(ctx context.Context, since time.Time) ([]components.Event, error) {
-	return nil, nil
+	// read all the items to use the ones before "since" as the baseline
+	items, err := c.poller.All(time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	return components.CapEvents(createPodEvents(items, since), c.cfg.MaxEvents), nil
 }
 
 func (c *component) Metrics(ctx context.Context, since time.Time) ([]components.Metric, error) {
@@ -94,3 +132,13 @@ func (c *component) Close() error {
 
 	return nil
 }
+
+var _ components.Drainer = (*component)(nil)
+
+// Stops the new polls and waits for the in-flight poll to finish.
+func (c *component) Drain(ctx context.Context) error {
+	if c.poller == nil {
+		return nil
+	}
+	return c.poller.Drain(ctx)
+}
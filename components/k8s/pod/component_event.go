@@ -0,0 +1,120 @@
+package pod
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/components/query"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// Emitted when a pod newly transitions to the failed phase.
+	EventNamePodFailed = "pod_failed"
+	// Emitted when a container restart count increases.
+	EventNameContainerRestarted = "container_restarted"
+
+	EventKeyUnixSeconds   = "unix_seconds"
+	EventKeyPodID         = "pod_id"
+	EventKeyPodNamespace  = "pod_namespace"
+	EventKeyPodName       = "pod_name"
+	EventKeyContainerName = "container_name"
+	EventKeyRestartCount  = "restart_count"
+	EventKeyReason        = "reason"
+	EventKeyMessage       = "message"
+)
+
+// Diffs the successive poller outputs and returns the events for the pods
+// that newly transitioned to the failed phase, and the containers whose
+// restart count increased, since the given time.
+// The items must be sorted by time (oldest first).
+// The items before "since" are only used as the baseline.
+// A failed pod seen for the first time (e.g., after gpud restart) is reported once,
+// whereas the restarts are only reported against a known baseline.
+func createPodEvents(items []query.Item, since time.Time) []components.Event {
+	evs := make([]components.Event, 0)
+
+	var (
+		prevFailed   = make(map[string]struct{})
+		prevRestarts map[string]int32
+	)
+	for _, item := range items {
+		// skip the failed queries, to keep the last known baseline
+		if item.Error != nil || item.Output == nil {
+			continue
+		}
+		output, ok := item.Output.(*Output)
+		if !ok {
+			continue
+		}
+		emit := since.IsZero() || !item.Time.Time.Before(since)
+
+		curFailed := make(map[string]struct{})
+		curRestarts := make(map[string]int32)
+		for _, pod := range output.Pods {
+			if pod.Phase == string(corev1.PodFailed) {
+				curFailed[pod.ID] = struct{}{}
+
+				if _, ok := prevFailed[pod.ID]; !ok && emit {
+					evs = append(evs, components.Event{
+						Time:    item.Time,
+						Name:    EventNamePodFailed,
+						Type:    components.EventTypeWarn,
+						Message: fmt.Sprintf("pod %s/%s failed", pod.Namespace, pod.Name),
+						ExtraInfo: map[string]string{
+							EventKeyUnixSeconds:  strconv.FormatInt(item.Time.Unix(), 10),
+							EventKeyPodID:        pod.ID,
+							EventKeyPodNamespace: pod.Namespace,
+							EventKeyPodName:      pod.Name,
+							EventKeyReason:       pod.Reason,
+							EventKeyMessage:      pod.Message,
+						},
+					})
+				}
+			}
+
+			for _, cs := range [][]ContainerStatus{pod.InitContainerStatuses, pod.ContainerStatuses} {
+				for _, c := range cs {
+					key := pod.ID + "/" + c.Name
+					curRestarts[key] = c.RestartCount
+
+					if prevRestarts == nil || !emit {
+						continue
+					}
+					prev, ok := prevRestarts[key]
+					if !ok || c.RestartCount <= prev {
+						continue
+					}
+
+					reason, message := "", ""
+					if c.State.Waiting != nil {
+						reason, message = c.State.Waiting.Reason, c.State.Waiting.Message
+					}
+					evs = append(evs, components.Event{
+						Time:    item.Time,
+						Name:    EventNameContainerRestarted,
+						Type:    components.EventTypeWarn,
+						Message: fmt.Sprintf("container %q in pod %s/%s restarted (restart count %d)", c.Name, pod.Namespace, pod.Name, c.RestartCount),
+						ExtraInfo: map[string]string{
+							EventKeyUnixSeconds:   strconv.FormatInt(item.Time.Unix(), 10),
+							EventKeyPodID:         pod.ID,
+							EventKeyPodNamespace:  pod.Namespace,
+							EventKeyPodName:       pod.Name,
+							EventKeyContainerName: c.Name,
+							EventKeyRestartCount:  strconv.FormatInt(int64(c.RestartCount), 10),
+							EventKeyReason:        reason,
+							EventKeyMessage:       message,
+						},
+					})
+				}
+			}
+		}
+		prevFailed = curFailed
+		prevRestarts = curRestarts
+	}
+
+	return evs
+}
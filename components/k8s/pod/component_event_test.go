@@ -0,0 +1,70 @@
+package pod
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/leptonai/gpud/components/query"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCreatePodEvents(t *testing.T) {
+	t.Parallel()
+
+	newItem := func(ts time.Time, phase corev1.PodPhase, restarts int32) query.Item {
+		return query.Item{
+			Time: metav1.Time{Time: ts},
+			Output: &Output{Pods: []PodStatus{{
+				ID:        "p1",
+				Namespace: "default",
+				Name:      "gpu-job",
+				Phase:     string(phase),
+				ContainerStatuses: []ContainerStatus{{
+					Name:         "main",
+					RestartCount: restarts,
+					State: corev1.ContainerState{
+						Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+					},
+				}},
+			}}},
+		}
+	}
+
+	now := time.Now()
+	items := []query.Item{
+		newItem(now.Add(-5*time.Minute), corev1.PodRunning, 1),
+		newItem(now.Add(-4*time.Minute), corev1.PodRunning, 2),
+		{Time: metav1.Time{Time: now.Add(-3 * time.Minute)}, Error: errors.New("query failed")},
+		newItem(now.Add(-2*time.Minute), corev1.PodFailed, 2),
+		newItem(now.Add(-time.Minute), corev1.PodFailed, 2),
+	}
+
+	evs := createPodEvents(items, time.Time{})
+	if len(evs) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(evs))
+	}
+	if evs[0].Name != EventNameContainerRestarted {
+		t.Fatalf("expected event name %q, got %q", EventNameContainerRestarted, evs[0].Name)
+	}
+	if evs[0].ExtraInfo[EventKeyContainerName] != "main" || evs[0].ExtraInfo[EventKeyRestartCount] != "2" {
+		t.Fatalf("unexpected extra info %+v", evs[0].ExtraInfo)
+	}
+	if evs[0].ExtraInfo[EventKeyReason] != "CrashLoopBackOff" {
+		t.Fatalf("expected reason CrashLoopBackOff, got %q", evs[0].ExtraInfo[EventKeyReason])
+	}
+	if evs[1].Name != EventNamePodFailed {
+		t.Fatalf("expected event name %q, got %q", EventNamePodFailed, evs[1].Name)
+	}
+	if evs[1].ExtraInfo[EventKeyPodNamespace] != "default" || evs[1].ExtraInfo[EventKeyPodName] != "gpu-job" {
+		t.Fatalf("unexpected extra info %+v", evs[1].ExtraInfo)
+	}
+
+	// only the pod failure happened after "since"
+	evs = createPodEvents(items, now.Add(-150*time.Second))
+	if len(evs) != 1 || evs[0].Name != EventNamePodFailed {
+		t.Fatalf("expected 1 pod failed event, got %+v", evs)
+	}
+}
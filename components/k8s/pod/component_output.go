@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"sync"
 	"time"
 
@@ -16,6 +17,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
 )
 
 type Output struct {
@@ -27,6 +29,10 @@ func (o *Output) JSON() ([]byte, error) {
 	return json.Marshal(o)
 }
 
+func (o *Output) YAML() ([]byte, error) {
+	return yaml.Marshal(o)
+}
+
 func ParseOutputJSON(data []byte) (*Output, error) {
 	o := new(Output)
 	if err := json.Unmarshal(data, o); err != nil {
@@ -35,6 +41,14 @@ func ParseOutputJSON(data []byte) (*Output, error) {
 	return o, nil
 }
 
+func ParseOutputYAML(data []byte) (*Output, error) {
+	o := new(Output)
+	if err := yaml.Unmarshal(data, o); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
 const (
 	StateNamePod = "pod"
 
@@ -65,8 +79,17 @@ func ParseStatesToOutput(states ...components.State) (*Output, error) {
 	return nil, fmt.Errorf("no pod state found")
 }
 
+// Returns the total number of GPUs requested by all the pods.
+func (o *Output) TotalGPURequests() int64 {
+	total := int64(0)
+	for _, p := range o.Pods {
+		total += p.GPURequest
+	}
+	return total
+}
+
 func (o *Output) describeReason() string {
-	return fmt.Sprintf("total %d pods (node %s)", len(o.Pods), o.NodeName)
+	return fmt.Sprintf("total %d pods, %d GPUs requested (node %s)", len(o.Pods), o.TotalGPURequests(), o.NodeName)
 }
 
 func (o *Output) States() ([]components.State, error) {
@@ -106,8 +129,13 @@ func DefaultPollerReady() <-chan any {
 }
 
 func CreateGet(cfg Config) query.GetFunc {
+	// the kubelet endpoint does not change while running,
+	// so only resolve (and discover if not configured) until it succeeds
+	resolver := &kubeletEndpointResolver{cfg: cfg}
 	return func(ctx context.Context) (_ any, e error) {
+		start := time.Now()
 		defer func() {
+			components_metrics.SetGetLatency(Name, time.Since(start))
 			if e != nil {
 				components_metrics.SetGetFailed(Name)
 			} else {
@@ -115,7 +143,7 @@ func CreateGet(cfg Config) query.GetFunc {
 			}
 		}()
 
-		pods, err := ListFromKubeletReadOnlyPort(ctx, cfg.Port)
+		pods, err := listPods(ctx, resolver.resolve(ctx, os.Getenv(EnvNodeName), inClusterAPIServerURL()))
 		if err != nil {
 			return nil, err
 		}
@@ -171,7 +199,10 @@ func CheckKubeletReadOnlyPort(ctx context.Context, port int) error {
 }
 
 func ListFromKubeletReadOnlyPort(ctx context.Context, port int) (*corev1.PodList, error) {
-	url := fmt.Sprintf("http://localhost:%d/pods", port)
+	return listFromKubeletReadOnly(ctx, fmt.Sprintf("http://localhost:%d/pods", port))
+}
+
+func listFromKubeletReadOnly(ctx context.Context, url string) (*corev1.PodList, error) {
 	req, rerr := http.NewRequest(http.MethodGet, url, nil)
 	if rerr != nil {
 		return nil, rerr
@@ -251,6 +282,8 @@ func convertToPodStatus(pod corev1.Pod) PodStatus {
 		})
 	}
 
+	gpuRequest, gpuLimit := gpuResources(pod)
+
 	return PodStatus{
 		ID:                    string(pod.UID),
 		Namespace:             pod.Namespace,
@@ -262,9 +295,40 @@ func convertToPodStatus(pod corev1.Pod) PodStatus {
 		StartTime:             pod.Status.StartTime,
 		InitContainerStatuses: iss,
 		ContainerStatuses:     css,
+		GPURequest:            gpuRequest,
+		GPULimit:              gpuLimit,
 	}
 }
 
+// ResourceNameNVIDIAGPU is the extended resource name of the NVIDIA GPUs
+// advertised by the NVIDIA device plugin.
+const ResourceNameNVIDIAGPU corev1.ResourceName = "nvidia.com/gpu"
+
+// Returns the total number of GPUs requested and limited by the pod containers.
+// Init containers run before the regular containers one at a time,
+// so the effective value is the larger of the max init container value
+// and the sum of the regular containers.
+// ref. https://kubernetes.io/docs/concepts/workloads/pods/init-containers/#resource-sharing-within-containers
+func gpuResources(pod corev1.Pod) (request int64, limit int64) {
+	for _, c := range pod.Spec.Containers {
+		if q, ok := c.Resources.Requests[ResourceNameNVIDIAGPU]; ok {
+			request += q.Value()
+		}
+		if q, ok := c.Resources.Limits[ResourceNameNVIDIAGPU]; ok {
+			limit += q.Value()
+		}
+	}
+	for _, c := range pod.Spec.InitContainers {
+		if q, ok := c.Resources.Requests[ResourceNameNVIDIAGPU]; ok && q.Value() > request {
+			request = q.Value()
+		}
+		if q, ok := c.Resources.Limits[ResourceNameNVIDIAGPU]; ok && q.Value() > limit {
+			limit = q.Value()
+		}
+	}
+	return request, limit
+}
+
 // PodStatus represents the simpler pod status from kubelet API.
 // ref. https://pkg.go.dev/k8s.io/api/core/v1#PodStatus
 type PodStatus struct {
@@ -278,6 +342,11 @@ type PodStatus struct {
 	StartTime             *metav1.Time      `json:"startTime,omitempty"`
 	InitContainerStatuses []ContainerStatus `json:"initContainerStatuses,omitempty"`
 	ContainerStatuses     []ContainerStatus `json:"containerStatuses,omitempty"`
+
+	// GPURequest is the number of "nvidia.com/gpu" requested by the pod.
+	GPURequest int64 `json:"gpuRequest,omitempty"`
+	// GPULimit is the number of "nvidia.com/gpu" limited for the pod.
+	GPULimit int64 `json:"gpuLimit,omitempty"`
 }
 
 func (s PodStatus) JSON() ([]byte, error) {
@@ -0,0 +1,70 @@
+package pod
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestOutputYAML(t *testing.T) {
+	t.Parallel()
+
+	startTime := metav1.NewTime(time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC))
+	o := &Output{
+		NodeName: "gpu-node-1",
+		Pods: []PodStatus{
+			{
+				ID:        "uid-1",
+				Namespace: "default",
+				Name:      "trainer-0",
+				Phase:     "Running",
+				StartTime: &startTime,
+				Conditions: []PodCondition{
+					{Type: "Ready", Status: "True", LastTransitionTime: startTime},
+				},
+				ContainerStatuses: []ContainerStatus{
+					{
+						Name:         "main",
+						State:        corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: startTime}},
+						Ready:        true,
+						RestartCount: 1,
+						Image:        "nvcr.io/nvidia/pytorch:24.09-py3",
+					},
+				},
+				GPURequest: 8,
+				GPULimit:   8,
+			},
+		},
+	}
+
+	b, err := o.YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// field names are consistent with the JSON tags
+	for _, key := range []string{"node_name:", "startTime:", "containerStatuses:", "gpuRequest:"} {
+		if !strings.Contains(string(b), key) {
+			t.Fatalf("expected %q in YAML output:\n%s", key, b)
+		}
+	}
+
+	parsed, err := ParseOutputYAML(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// compare in JSON, as the parsed times are in the local time zone
+	expected, err := o.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := parsed.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(expected) != string(got) {
+		t.Fatalf("expected %s, got %s", expected, got)
+	}
+}
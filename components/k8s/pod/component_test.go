@@ -7,8 +7,14 @@ import (
 	"os"
 	"strconv"
 	"testing"
+	"time"
+
+	"github.com/leptonai/gpud/components"
+	"github.com/leptonai/gpud/components/query"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestListFromKubeletReadOnlyPort(t *testing.T) {
@@ -111,3 +117,122 @@ func Test_parsePodsFromKubeletReadOnlyPort(t *testing.T) {
 		t.Errorf("expected pod phase 'Running', got: %s", pods.Items[1].Status.Phase)
 	}
 }
+
+func TestConvertToPodsStatusGPUResources(t *testing.T) {
+	t.Parallel()
+
+	gpuPod := corev1.Pod{
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{
+				Name: "init",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{ResourceNameNVIDIAGPU: resource.MustParse("1")},
+				},
+			}},
+			Containers: []corev1.Container{
+				{
+					Name: "main",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{ResourceNameNVIDIAGPU: resource.MustParse("2")},
+						Limits:   corev1.ResourceList{ResourceNameNVIDIAGPU: resource.MustParse("2")},
+					},
+				},
+				{
+					Name: "sidecar",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{ResourceNameNVIDIAGPU: resource.MustParse("1")},
+						Limits:   corev1.ResourceList{ResourceNameNVIDIAGPU: resource.MustParse("1")},
+					},
+				},
+			},
+		},
+	}
+	cpuPod := corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name: "main",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+				},
+			}},
+		},
+	}
+
+	pss := ConvertToPodsStatus(gpuPod, cpuPod)
+	if pss[0].GPURequest != 3 || pss[0].GPULimit != 3 {
+		t.Fatalf("expected 3 GPUs requested and limited, got %d and %d", pss[0].GPURequest, pss[0].GPULimit)
+	}
+	if pss[1].GPURequest != 0 || pss[1].GPULimit != 0 {
+		t.Fatalf("expected no GPUs, got %d and %d", pss[1].GPURequest, pss[1].GPULimit)
+	}
+
+	o := &Output{Pods: pss}
+	if o.TotalGPURequests() != 3 {
+		t.Fatalf("expected 3 GPUs requested, got %d", o.TotalGPURequests())
+	}
+}
+
+type blockingPoller struct {
+	query.Poller
+	blockc chan struct{}
+}
+
+func (p *blockingPoller) Last() (*query.Item, error) {
+	<-p.blockc
+	return nil, nil
+}
+
+func TestComponentStatesTimeout(t *testing.T) {
+	t.Parallel()
+
+	blockc := make(chan struct{})
+	defer close(blockc)
+
+	c := &component{poller: &blockingPoller{blockc: blockc}}
+	c.cfg.Query.StatesTimeout = metav1.Duration{Duration: 100 * time.Millisecond}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, ctx := range []context.Context{ctx, context.Background()} {
+		start := time.Now()
+		states, err := c.States(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Fatalf("expected prompt return, took %v", elapsed)
+		}
+		if len(states) != 1 || states[0].Healthy || states[0].ReasonCode != components.ReasonTimeout {
+			t.Fatalf("unexpected states: %+v", states)
+		}
+	}
+}
+
+type lastPoller struct {
+	query.Poller
+	last *query.Item
+}
+
+func (p *lastPoller) Last() (*query.Item, error) {
+	return p.last, nil
+}
+
+func TestComponentReady(t *testing.T) {
+	t.Parallel()
+
+	p := &lastPoller{}
+	c := &component{poller: p}
+	if c.Ready() {
+		t.Fatal("expected not ready before the first poll")
+	}
+
+	// failed queries also count, as the poller has produced a result
+	p.last = &query.Item{Time: metav1.Now()}
+	if !c.Ready() {
+		t.Fatal("expected ready after the first poll")
+	}
+	if !components.Ready(c) {
+		t.Fatal("expected ready via components.Ready")
+	}
+}
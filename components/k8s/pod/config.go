@@ -3,14 +3,42 @@ package pod
 import (
 	"database/sql"
 	"encoding/json"
-	"errors"
+	"fmt"
 
 	query_config "github.com/leptonai/gpud/components/query/config"
 )
 
 type Config struct {
 	Query query_config.Config `json:"query"`
-	Port  int                 `json:"port"`
+
+	// Host is the kubelet host.
+	// If both the host and the port are empty, discovers the kubelet endpoint
+	// from the node object in the kubernetes API server
+	// (with the node name from the EnvNodeName env), and falls back to
+	// DefaultKubeletHost with the default ports.
+	Host string `json:"host,omitempty"`
+	// Port is the kubelet read-only port.
+	// Default is DefaultKubeletReadOnlyPort.
+	Port int `json:"port"`
+
+	// Set true to query the authenticated kubelet port over HTTPS
+	// with the service account bearer token and CA.
+	// Falls back to the read-only port when no token is found.
+	Secure bool `json:"secure,omitempty"`
+	// SecurePort is the kubelet authenticated port.
+	// Default is DefaultKubeletSecurePort.
+	SecurePort int `json:"secure_port,omitempty"`
+	// TokenFile is the path to the bearer token.
+	// Default is DefaultServiceAccountTokenFile.
+	TokenFile string `json:"token_file,omitempty"`
+	// CAFile is the path to the CA certificate to verify the kubelet.
+	// Default is DefaultServiceAccountCAFile.
+	CAFile string `json:"ca_file,omitempty"`
+
+	// Maximum number of the most recent events returned by a single Events call
+	// (see components.CapEvents).
+	// Zero uses components.DefaultMaxEvents, and negative disables the cap.
+	MaxEvents int `json:"max_events,omitempty"`
 }
 
 func ParseConfig(b any, db *sql.DB) (*Config, error) {
@@ -29,9 +57,27 @@ func ParseConfig(b any, db *sql.DB) (*Config, error) {
 	return cfg, nil
 }
 
+func (cfg *Config) SetDefaultsIfNotSet() {
+	if !cfg.Secure {
+		return
+	}
+	if cfg.SecurePort == 0 {
+		cfg.SecurePort = DefaultKubeletSecurePort
+	}
+	if cfg.TokenFile == "" {
+		cfg.TokenFile = DefaultServiceAccountTokenFile
+	}
+	if cfg.CAFile == "" {
+		cfg.CAFile = DefaultServiceAccountCAFile
+	}
+}
+
 func (cfg Config) Validate() error {
-	if cfg.Port == 0 {
-		return errors.New("kubelet port is required")
+	if cfg.Port < 0 {
+		return fmt.Errorf("invalid kubelet port: %d", cfg.Port)
+	}
+	if cfg.SecurePort < 0 {
+		return fmt.Errorf("invalid kubelet secure port: %d", cfg.SecurePort)
 	}
 	return nil
 }
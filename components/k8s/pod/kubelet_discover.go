@@ -0,0 +1,190 @@
+package pod
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+
+	"github.com/leptonai/gpud/log"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// EnvNodeName is the env to find the name of the node that gpud runs on
+	// (e.g., set from "spec.nodeName" with the downward API).
+	EnvNodeName = "NODE_NAME"
+
+	// DefaultKubeletHost is the kubelet host used when not configured
+	// and not discovered.
+	DefaultKubeletHost = "localhost"
+)
+
+var (
+	ErrNodeNameNotFound = errors.New("node name not found")
+	ErrNotInCluster     = errors.New("kubernetes API server not found (not running in a cluster)")
+)
+
+// Returns the kubernetes API server URL from the in-cluster service envs,
+// or an empty string if not running in a cluster.
+func inClusterAPIServerURL() string {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return ""
+	}
+	return "https://" + net.JoinHostPort(host, port)
+}
+
+// Resolves the kubelet endpoint when the endpoint config (host and port) is empty.
+// Explicit config takes precedence and is returned as is, with the defaults for the empty fields.
+// Otherwise, discovers the kubelet host and authenticated port from the node object
+// in the kubernetes API server ("status.daemonEndpoints.kubeletEndpoint"),
+// and falls back to the localhost default ports if the discovery fails.
+//
+// Returns false if the discovery failed while running in a cluster
+// (e.g., API server not reachable yet), in which case the caller should
+// use the returned fallback config only for now and retry the resolution later.
+func resolveKubeletEndpoint(ctx context.Context, cfg Config, nodeName string, apiServerURL string) (Config, bool) {
+	resolved := true
+	if cfg.Host == "" && cfg.Port == 0 {
+		host, port, err := discoverKubeletEndpoint(ctx, cfg, nodeName, apiServerURL)
+		if err != nil {
+			log.Logger.Debugw("failed to discover kubelet endpoint -- falling back to defaults", "node", nodeName, "error", err)
+
+			// no cluster detected, thus no need to retry
+			resolved = errors.Is(err, ErrNodeNameNotFound) || errors.Is(err, ErrNotInCluster)
+		} else {
+			log.Logger.Debugw("discovered kubelet endpoint", "node", nodeName, "host", host, "port", port)
+			cfg.Host = host
+			if port > 0 && (cfg.SecurePort == 0 || cfg.SecurePort == DefaultKubeletSecurePort) {
+				cfg.SecurePort = port
+			}
+		}
+	}
+
+	if cfg.Host == "" {
+		cfg.Host = DefaultKubeletHost
+	}
+	if cfg.Port == 0 {
+		cfg.Port = DefaultKubeletReadOnlyPort
+	}
+	if cfg.Secure && cfg.SecurePort == 0 {
+		cfg.SecurePort = DefaultKubeletSecurePort
+	}
+	return cfg, resolved
+}
+
+// Caches the resolved kubelet endpoint, and retries the resolution
+// on the next call until it succeeds or no cluster is detected.
+type kubeletEndpointResolver struct {
+	mu       sync.Mutex
+	cfg      Config
+	resolved *Config
+}
+
+func (r *kubeletEndpointResolver) resolve(ctx context.Context, nodeName string, apiServerURL string) Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.resolved != nil {
+		return *r.resolved
+	}
+	cfg, resolved := resolveKubeletEndpoint(ctx, r.cfg, nodeName, apiServerURL)
+	if resolved {
+		r.resolved = &cfg
+	}
+	return cfg
+}
+
+func discoverKubeletEndpoint(ctx context.Context, cfg Config, nodeName string, apiServerURL string) (string, int, error) {
+	if nodeName == "" {
+		return "", 0, fmt.Errorf("%w (env %q not set)", ErrNodeNameNotFound, EnvNodeName)
+	}
+	if apiServerURL == "" {
+		return "", 0, ErrNotInCluster
+	}
+
+	tokenFile := cfg.TokenFile
+	if tokenFile == "" {
+		tokenFile = DefaultServiceAccountTokenFile
+	}
+	token, err := readToken(tokenFile)
+	if err != nil {
+		return "", 0, err
+	}
+	caFile := cfg.CAFile
+	if caFile == "" {
+		caFile = DefaultServiceAccountCAFile
+	}
+	caPEM, err := os.ReadFile(caFile)
+	if err != nil {
+		return "", 0, err
+	}
+
+	node, err := getNode(ctx, apiServerURL, nodeName, token, caPEM)
+	if err != nil {
+		return "", 0, err
+	}
+	host, port := kubeletEndpointFromNode(node)
+	if host == "" {
+		return "", 0, fmt.Errorf("no address found for node %q", nodeName)
+	}
+	return host, port, nil
+}
+
+// Gets the node object from the kubernetes API server over HTTPS,
+// using the bearer token and the CA certificate (PEM-encoded).
+func getNode(ctx context.Context, apiServerURL string, nodeName string, token string, caPEM []byte) (*corev1.Node, error) {
+	cli, err := secureHTTPClient(caPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, apiServerURL+"/api/v1/nodes/"+url.PathEscape(nodeName), nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := cli.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("getting node %q failed %d", nodeName, resp.StatusCode)
+	}
+
+	node := new(corev1.Node)
+	if err := json.NewDecoder(resp.Body).Decode(node); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// Returns the kubelet host and authenticated port of the node.
+// Prefers the internal IP over the hostname, as the kubelet serves on the node IPs.
+func kubeletEndpointFromNode(node *corev1.Node) (string, int) {
+	host := ""
+	for _, typ := range []corev1.NodeAddressType{corev1.NodeInternalIP, corev1.NodeHostName, corev1.NodeExternalIP} {
+		for _, addr := range node.Status.Addresses {
+			if addr.Type == typ && addr.Address != "" {
+				host = addr.Address
+				break
+			}
+		}
+		if host != "" {
+			break
+		}
+	}
+	return host, int(node.Status.DaemonEndpoints.KubeletEndpoint.Port)
+}
@@ -0,0 +1,222 @@
+package pod
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Returns the fake kubernetes API server serving the node object,
+// and the config with the token and CA files to access it.
+func newFakeAPIServer(t *testing.T, node *corev1.Node) (*httptest.Server, Config) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/api/v1/nodes/"+node.Name {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(node)
+	}))
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+	cfg := Config{
+		TokenFile: filepath.Join(dir, "token"),
+		CAFile:    filepath.Join(dir, "ca.crt"),
+	}
+	if err := os.WriteFile(cfg.TokenFile, []byte("test-token\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(cfg.CAFile, caPEM, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return srv, cfg
+}
+
+func testNode() *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: corev1.NodeStatus{
+			Addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeHostName, Address: "node-1.internal"},
+				{Type: corev1.NodeInternalIP, Address: "10.0.0.5"},
+			},
+			DaemonEndpoints: corev1.NodeDaemonEndpoints{
+				KubeletEndpoint: corev1.DaemonEndpoint{Port: 12250},
+			},
+		},
+	}
+}
+
+func TestResolveKubeletEndpointDiscovered(t *testing.T) {
+	t.Parallel()
+
+	srv, cfg := newFakeAPIServer(t, testNode())
+	cfg.Secure = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	resolved, ok := resolveKubeletEndpoint(ctx, cfg, "node-1", srv.URL)
+	if !ok {
+		t.Fatal("expected the endpoint resolved")
+	}
+	if resolved.Host != "10.0.0.5" {
+		t.Fatalf("expected host 10.0.0.5, got %q", resolved.Host)
+	}
+	if resolved.SecurePort != 12250 {
+		t.Fatalf("expected secure port 12250, got %d", resolved.SecurePort)
+	}
+	if resolved.Port != DefaultKubeletReadOnlyPort {
+		t.Fatalf("expected read-only port %d, got %d", DefaultKubeletReadOnlyPort, resolved.Port)
+	}
+}
+
+func TestResolveKubeletEndpointExplicit(t *testing.T) {
+	t.Parallel()
+
+	srv, cfg := newFakeAPIServer(t, testNode())
+	cfg.Host = "192.168.0.1"
+	cfg.Port = 10000
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	resolved, ok := resolveKubeletEndpoint(ctx, cfg, "node-1", srv.URL)
+	if !ok {
+		t.Fatal("expected the endpoint resolved")
+	}
+	if resolved.Host != "192.168.0.1" || resolved.Port != 10000 {
+		t.Fatalf("expected explicit endpoint 192.168.0.1:10000, got %s:%d", resolved.Host, resolved.Port)
+	}
+}
+
+func TestResolveKubeletEndpointFallback(t *testing.T) {
+	t.Parallel()
+
+	srv, cfg := newFakeAPIServer(t, testNode())
+	cfg.Secure = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tests := []struct {
+		name         string
+		nodeName     string
+		url          string
+		wantResolved bool
+	}{
+		{name: "no node name", nodeName: "", url: srv.URL, wantResolved: true},
+		{name: "not in cluster", nodeName: "node-1", url: "", wantResolved: true},
+		{name: "node not found", nodeName: "node-2", url: srv.URL, wantResolved: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolved, ok := resolveKubeletEndpoint(ctx, cfg, tt.nodeName, tt.url)
+			if ok != tt.wantResolved {
+				t.Errorf("expected resolved %v, got %v", tt.wantResolved, ok)
+			}
+			if resolved.Host != DefaultKubeletHost {
+				t.Errorf("expected host %q, got %q", DefaultKubeletHost, resolved.Host)
+			}
+			if resolved.Port != DefaultKubeletReadOnlyPort {
+				t.Errorf("expected read-only port %d, got %d", DefaultKubeletReadOnlyPort, resolved.Port)
+			}
+			if resolved.SecurePort != DefaultKubeletSecurePort {
+				t.Errorf("expected secure port %d, got %d", DefaultKubeletSecurePort, resolved.SecurePort)
+			}
+		})
+	}
+}
+
+func TestKubeletEndpointResolverRetry(t *testing.T) {
+	t.Parallel()
+
+	node := testNode()
+	srv, cfg := newFakeAPIServer(t, node)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// API server not ready yet, falls back to the defaults and retries
+	r := &kubeletEndpointResolver{cfg: cfg}
+	resolved := r.resolve(ctx, "node-1", srv.URL+"/not-ready")
+	if resolved.Host != DefaultKubeletHost {
+		t.Fatalf("expected host %q, got %q", DefaultKubeletHost, resolved.Host)
+	}
+
+	resolved = r.resolve(ctx, "node-1", srv.URL)
+	if resolved.Host != "10.0.0.5" {
+		t.Fatalf("expected host 10.0.0.5 after retry, got %q", resolved.Host)
+	}
+
+	// once resolved, no more discovery
+	resolved = r.resolve(ctx, "node-1", "")
+	if resolved.Host != "10.0.0.5" {
+		t.Fatalf("expected the cached host 10.0.0.5, got %q", resolved.Host)
+	}
+}
+
+func TestKubeletEndpointResolverNotInCluster(t *testing.T) {
+	t.Parallel()
+
+	srv, cfg := newFakeAPIServer(t, testNode())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// no cluster detected, thus no retry
+	r := &kubeletEndpointResolver{cfg: cfg}
+	resolved := r.resolve(ctx, "node-1", "")
+	if resolved.Host != DefaultKubeletHost {
+		t.Fatalf("expected host %q, got %q", DefaultKubeletHost, resolved.Host)
+	}
+	resolved = r.resolve(ctx, "node-1", srv.URL)
+	if resolved.Host != DefaultKubeletHost {
+		t.Fatalf("expected the cached host %q, got %q", DefaultKubeletHost, resolved.Host)
+	}
+}
+
+func TestDiscoverKubeletEndpointNoNodeName(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := discoverKubeletEndpoint(context.Background(), Config{}, "", "https://localhost:6443")
+	if !errors.Is(err, ErrNodeNameNotFound) {
+		t.Fatalf("expected %v, got %v", ErrNodeNameNotFound, err)
+	}
+}
+
+func TestKubeletEndpointFromNode(t *testing.T) {
+	t.Parallel()
+
+	host, port := kubeletEndpointFromNode(testNode())
+	if host != "10.0.0.5" || port != 12250 {
+		t.Fatalf("expected 10.0.0.5:12250, got %s:%d", host, port)
+	}
+
+	node := testNode()
+	node.Status.Addresses = node.Status.Addresses[:1]
+	host, _ = kubeletEndpointFromNode(node)
+	if host != "node-1.internal" {
+		t.Fatalf("expected hostname fallback, got %q", host)
+	}
+
+	host, port = kubeletEndpointFromNode(&corev1.Node{})
+	if host != "" || port != 0 {
+		t.Fatalf("expected empty endpoint, got %s:%d", host, port)
+	}
+}
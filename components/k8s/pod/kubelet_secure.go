@@ -0,0 +1,110 @@
+package pod
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leptonai/gpud/log"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	DefaultKubeletSecurePort = 10250
+
+	DefaultServiceAccountTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	DefaultServiceAccountCAFile    = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+)
+
+// Lists the pods from the authenticated kubelet port if configured and
+// the bearer token is found. Otherwise, lists from the read-only port.
+// Empty host uses DefaultKubeletHost.
+func listPods(ctx context.Context, cfg Config) (*corev1.PodList, error) {
+	host := cfg.Host
+	if host == "" {
+		host = DefaultKubeletHost
+	}
+	readOnlyURL := fmt.Sprintf("http://%s/pods", net.JoinHostPort(host, strconv.Itoa(cfg.Port)))
+
+	if !cfg.Secure {
+		return listFromKubeletReadOnly(ctx, readOnlyURL)
+	}
+
+	token, err := readToken(cfg.TokenFile)
+	if err != nil || token == "" {
+		log.Logger.Debugw("no kubelet bearer token found -- falling back to read-only port", "file", cfg.TokenFile, "error", err)
+		return listFromKubeletReadOnly(ctx, readOnlyURL)
+	}
+
+	caPEM, err := os.ReadFile(cfg.CAFile)
+	if err != nil {
+		return nil, err
+	}
+	return listFromKubeletSecure(ctx, fmt.Sprintf("https://%s/pods", net.JoinHostPort(host, strconv.Itoa(cfg.SecurePort))), token, caPEM)
+}
+
+func readToken(file string) (string, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// Lists the pods from the kubelet authenticated port over HTTPS,
+// using the bearer token and the CA certificate (PEM-encoded).
+func ListFromKubeletSecurePort(ctx context.Context, port int, token string, caPEM []byte) (*corev1.PodList, error) {
+	return listFromKubeletSecure(ctx, fmt.Sprintf("https://localhost:%d/pods", port), token, caPEM)
+}
+
+func listFromKubeletSecure(ctx context.Context, url string, token string, caPEM []byte) (*corev1.PodList, error) {
+	cli, err := secureHTTPClient(caPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := cli.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("listing pods from kubelet secure port failed %d", resp.StatusCode)
+	}
+	return parsePodsFromKubeletReadOnlyPort(resp.Body)
+}
+
+func secureHTTPClient(caPEM []byte) (*http.Client, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, errors.New("failed to parse kubelet CA certificate")
+	}
+	tr := &http.Transport{
+		DisableCompression: true,
+		TLSClientConfig: &tls.Config{
+			RootCAs:    pool,
+			MinVersion: tls.VersionTLS12,
+		},
+	}
+	return &http.Client{
+		Transport: tr,
+		Timeout:   30 * time.Second,
+	}, nil
+}
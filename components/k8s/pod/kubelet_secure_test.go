@@ -0,0 +1,75 @@
+package pod
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestListFromKubeletSecure(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		http.ServeFile(w, r, "kubelet-readonly-pods.json")
+	}))
+	defer srv.Close()
+
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pods, err := listFromKubeletSecure(ctx, srv.URL+"/pods", "test-token", caPEM)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(pods.Items) != 2 {
+		t.Fatalf("expected 2 pods, got %d", len(pods.Items))
+	}
+
+	if _, err := listFromKubeletSecure(ctx, srv.URL+"/pods", "wrong-token", caPEM); err == nil {
+		t.Fatal("expected error with wrong token")
+	}
+	if _, err := listFromKubeletSecure(ctx, srv.URL+"/pods", "test-token", []byte("invalid")); err == nil {
+		t.Fatal("expected error with invalid CA")
+	}
+}
+
+func TestListPodsFallbackToReadOnlyPort(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		http.ServeFile(w, r, "kubelet-readonly-pods.json")
+	}))
+	defer srv.Close()
+
+	port, _ := strconv.ParseInt(srv.URL[len("http://127.0.0.1:"):], 10, 32)
+
+	cfg := Config{
+		Port:      int(port),
+		Secure:    true,
+		TokenFile: filepath.Join(t.TempDir(), "does-not-exist"),
+	}
+	cfg.SetDefaultsIfNotSet()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pods, err := listPods(ctx, cfg)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(pods.Items) != 2 {
+		t.Fatalf("expected 2 pods, got %d", len(pods.Items))
+	}
+}
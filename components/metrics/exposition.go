@@ -0,0 +1,190 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leptonai/gpud/components"
+)
+
+const (
+	labelComponent     = "component"
+	labelSecondaryName = "secondary_name"
+
+	// prefixes the extra info keys colliding with the reserved labels
+	// (as Prometheus does for the colliding target labels)
+	labelExportedPrefix = "exported_"
+)
+
+// MergedMetric is the latest value of a metric series across all the components,
+// where a series is identified by its component, name, and labels.
+type MergedMetric struct {
+	Component   string            `json:"component"`
+	Name        string            `json:"name"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Value       float64           `json:"value"`
+	UnixSeconds int64             `json:"unix_seconds"`
+}
+
+// Merges the metrics of all the components since the given time,
+// keeping only the latest value of each series.
+// The metric secondary name and the extra info (e.g., "gpu_id")
+// are converted to the labels, and the extra info keys colliding with
+// the reserved labels ("component" and "secondary_name") are prefixed
+// with "exported_" (e.g., "exported_component").
+// A failing component does not fail the others, and its error
+// is returned joined with the others (along with the partial results).
+func MergeMetrics(ctx context.Context, since time.Time, comps ...components.Component) ([]MergedMetric, error) {
+	latest := make(map[string]MergedMetric)
+	var errs []error
+	for _, c := range comps {
+		ms, err := c.Metrics(ctx, since)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("component %s: %w", c.Name(), err))
+			continue
+		}
+		for _, m := range ms {
+			labels := make(map[string]string, len(m.ExtraInfo)+1)
+			if m.MetricSecondaryName != "" {
+				labels[labelSecondaryName] = m.MetricSecondaryName
+			}
+			for k, v := range m.ExtraInfo {
+				labels[extraInfoLabelName(k)] = v
+			}
+
+			merged := MergedMetric{
+				Component:   c.Name(),
+				Name:        m.MetricName,
+				Labels:      labels,
+				Value:       m.Value,
+				UnixSeconds: m.UnixSeconds,
+			}
+			key := seriesKey(merged)
+			if prev, ok := latest[key]; ok && prev.UnixSeconds > merged.UnixSeconds {
+				continue
+			}
+			latest[key] = merged
+		}
+	}
+
+	merged := make([]MergedMetric, 0, len(latest))
+	for _, m := range latest {
+		merged = append(merged, m)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return seriesKey(merged[i]) < seriesKey(merged[j])
+	})
+	return merged, errors.Join(errs...)
+}
+
+// Writes the merged metrics of all the components in JSON.
+func WriteJSON(ctx context.Context, w io.Writer, since time.Time, comps ...components.Component) error {
+	merged, err := MergeMetrics(ctx, since, comps...)
+	if jerr := json.NewEncoder(w).Encode(merged); jerr != nil {
+		return jerr
+	}
+	return err
+}
+
+// Writes the merged metrics of all the components
+// in the Prometheus text exposition format, as gauges.
+// ref. https://prometheus.io/docs/instrumenting/exposition_formats/#text-based-format
+func WritePrometheusText(ctx context.Context, w io.Writer, since time.Time, comps ...components.Component) error {
+	merged, err := MergeMetrics(ctx, since, comps...)
+
+	// group by the metric name, as required by the exposition format
+	byName := make(map[string][]MergedMetric)
+	names := make([]string, 0)
+	for _, m := range merged {
+		name := "gpud_" + sanitizeName(m.Name)
+		if _, ok := byName[name]; !ok {
+			names = append(names, name)
+		}
+		byName[name] = append(byName[name], m)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, werr := fmt.Fprintf(w, "# TYPE %s gauge\n", name); werr != nil {
+			return werr
+		}
+		for _, m := range byName[name] {
+			if _, werr := fmt.Fprintf(w, "%s{%s} %s %d\n",
+				name,
+				formatLabels(m),
+				strconv.FormatFloat(m.Value, 'g', -1, 64),
+				m.UnixSeconds*1000,
+			); werr != nil {
+				return werr
+			}
+		}
+	}
+	return err
+}
+
+func seriesKey(m MergedMetric) string {
+	return m.Component + "/" + m.Name + "{" + formatLabels(m) + "}"
+}
+
+// Returns the sorted labels including the component name.
+func formatLabels(m MergedMetric) string {
+	keys := make([]string, 0, len(m.Labels))
+	for k := range m.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys)+1)
+	pairs = append(pairs, labelComponent+`="`+escapeLabelValue(m.Component)+`"`)
+	for _, k := range keys {
+		pairs = append(pairs, k+`="`+escapeLabelValue(m.Labels[k])+`"`)
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Returns the label name of the extra info key,
+// not to overwrite the reserved labels.
+func extraInfoLabelName(k string) string {
+	name := sanitizeName(k)
+	if name == labelComponent || name == labelSecondaryName {
+		return labelExportedPrefix + name
+	}
+	return name
+}
+
+// Returns the valid Prometheus metric/label name,
+// replacing the invalid characters with "_".
+// e.g., "accelerator-nvidia-ecc" becomes "accelerator_nvidia_ecc".
+func sanitizeName(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'):
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteRune('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+// Escapes the label value (e.g., GPU UUIDs), and trims the surrounding spaces.
+// ref. https://prometheus.io/docs/instrumenting/exposition_formats/#comments-help-text-and-type-information
+func escapeLabelValue(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return strings.ReplaceAll(s, "\n", `\n`)
+}
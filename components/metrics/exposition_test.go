@@ -0,0 +1,140 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leptonai/gpud/components"
+	components_metrics_state "github.com/leptonai/gpud/components/metrics/state"
+)
+
+type mockComponent struct {
+	name    string
+	metrics []components.Metric
+	err     error
+}
+
+func (m *mockComponent) Name() string { return m.name }
+func (m *mockComponent) States(context.Context) ([]components.State, error) {
+	return nil, nil
+}
+func (m *mockComponent) Events(context.Context, time.Time) ([]components.Event, error) {
+	return nil, nil
+}
+func (m *mockComponent) Metrics(context.Context, time.Time) ([]components.Metric, error) {
+	return m.metrics, m.err
+}
+func (m *mockComponent) Close() error { return nil }
+
+func newMetric(unixSeconds int64, name string, gpuID string, value float64) components.Metric {
+	return components.Metric{
+		Metric: components_metrics_state.Metric{
+			UnixSeconds:         unixSeconds,
+			MetricName:          name,
+			MetricSecondaryName: gpuID,
+			Value:               value,
+		},
+		ExtraInfo: map[string]string{"gpu_id": gpuID},
+	}
+}
+
+func TestWritePrometheusText(t *testing.T) {
+	t.Parallel()
+
+	ecc := &mockComponent{
+		name: "accelerator-nvidia-ecc",
+		metrics: []components.Metric{
+			newMetric(100, "aggregate_total_corrected", "GPU-a", 1),
+			newMetric(200, "aggregate_total_corrected", "GPU-a", 2),
+			newMetric(200, "aggregate_total_corrected", "GPU-\"b\"", 3),
+		},
+	}
+	failing := &mockComponent{name: "failing", err: errors.New("query failed")}
+
+	buf := new(bytes.Buffer)
+	err := WritePrometheusText(context.Background(), buf, time.Time{}, ecc, failing)
+	if err == nil || !strings.Contains(err.Error(), "failing") {
+		t.Fatalf("expected the failing component error, got %v", err)
+	}
+
+	want := `# TYPE gpud_aggregate_total_corrected gauge
+gpud_aggregate_total_corrected{component="accelerator-nvidia-ecc",gpu_id="GPU-\"b\"",secondary_name="GPU-\"b\""} 3 200000
+gpud_aggregate_total_corrected{component="accelerator-nvidia-ecc",gpu_id="GPU-a",secondary_name="GPU-a"} 2 200000
+`
+	if buf.String() != want {
+		t.Fatalf("unexpected output:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	t.Parallel()
+
+	ecc := &mockComponent{
+		name:    "accelerator-nvidia-ecc",
+		metrics: []components.Metric{newMetric(100, "volatile_total_uncorrected", "GPU-a", 1)},
+	}
+
+	buf := new(bytes.Buffer)
+	if err := WriteJSON(context.Background(), buf, time.Time{}, ecc); err != nil {
+		t.Fatal(err)
+	}
+	var merged []MergedMetric
+	if err := json.Unmarshal(buf.Bytes(), &merged); err != nil {
+		t.Fatal(err)
+	}
+	if len(merged) != 1 || merged[0].Labels["gpu_id"] != "GPU-a" || merged[0].Value != 1 {
+		t.Fatalf("unexpected merged metrics %+v", merged)
+	}
+}
+
+func TestMergeMetricsReservedLabels(t *testing.T) {
+	t.Parallel()
+
+	m := newMetric(100, "aggregate_total_corrected", "GPU-a", 1)
+	m.ExtraInfo["component"] = "other"
+	m.ExtraInfo["secondary-name"] = "GPU-b"
+	c := &mockComponent{name: "accelerator-nvidia-ecc", metrics: []components.Metric{m}}
+
+	merged, err := MergeMetrics(context.Background(), time.Time{}, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(merged) != 1 {
+		t.Fatalf("expected 1 merged metric, got %d", len(merged))
+	}
+	labels := merged[0].Labels
+	if labels["secondary_name"] != "GPU-a" {
+		t.Errorf("expected the secondary name not overwritten, got %q", labels["secondary_name"])
+	}
+	if labels["exported_secondary_name"] != "GPU-b" {
+		t.Errorf("expected the colliding extra info prefixed, got %q", labels["exported_secondary_name"])
+	}
+	if _, ok := labels["component"]; ok {
+		t.Errorf("expected no component label from the extra info, got %+v", labels)
+	}
+
+	want := `component="accelerator-nvidia-ecc",exported_component="other",exported_secondary_name="GPU-b",gpu_id="GPU-a",secondary_name="GPU-a"`
+	if got := formatLabels(merged[0]); got != want {
+		t.Fatalf("unexpected labels %s, want %s", got, want)
+	}
+}
+
+func TestSanitizeName(t *testing.T) {
+	t.Parallel()
+
+	for in, want := range map[string]string{
+		"gpu_id":                 "gpu_id",
+		"accelerator-nvidia-ecc": "accelerator_nvidia_ecc",
+		"0abc":                   "_0abc",
+		"a.b/c":                  "a_b_c",
+	} {
+		if got := sanitizeName(in); got != want {
+			t.Errorf("sanitizeName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package metrics
 
 import (
 	"context"
+	"time"
 
 	"github.com/leptonai/gpud/components"
 	"github.com/prometheus/client_golang/prometheus"
@@ -54,6 +55,16 @@ var (
 		},
 		[]string{"component"},
 	)
+	componentsGetLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "gpud",
+			Subsystem: "components",
+			Name:      "get_latency_seconds",
+			Help:      "latency of the component get (poll) queries in seconds",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 2, 15), // 10ms to ~164s
+		},
+		[]string{"component"},
+	)
 )
 
 func Register(reg *prometheus.Registry) error {
@@ -72,6 +83,9 @@ func Register(reg *prometheus.Registry) error {
 	if err := reg.Register(componentsGetFailed); err != nil {
 		return err
 	}
+	if err := reg.Register(componentsGetLatency); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -99,6 +113,12 @@ func SetGetFailed(componentName string) {
 	componentsGetFailed.With(prometheus.Labels{"component": componentName}).Set(1.0)
 }
 
+// Records how long a single component get (poll) query took,
+// regardless of whether it succeeded or failed.
+func SetGetLatency(componentName string, d time.Duration) {
+	componentsGetLatency.With(prometheus.Labels{"component": componentName}).Observe(d.Seconds())
+}
+
 func ReadRegisteredTotal(gatherer prometheus.Gatherer) (int64, error) {
 	metricFamilies, err := gatherer.Gather()
 	if err != nil {
@@ -184,6 +204,24 @@ func ReadGetFailedTotal(gatherer prometheus.Gatherer) (int64, error) {
 	return total, nil
 }
 
+// Returns the total number of the component get latency observations.
+func ReadGetLatencyCount(gatherer prometheus.Gatherer) (uint64, error) {
+	metricFamilies, err := gatherer.Gather()
+	if err != nil {
+		return 0, err
+	}
+
+	total := uint64(0)
+	for _, mf := range metricFamilies {
+		if mf.GetName() == "gpud_components_get_latency_seconds" {
+			for _, m := range mf.GetMetric() {
+				total += m.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+	return total, nil
+}
+
 func NewWatchableComponent(c components.Component) components.WatchableComponent {
 	return &watchableComponent{
 		Component: c,
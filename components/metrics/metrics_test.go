@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestSetGetLatency(t *testing.T) {
+	// not parallel, as the collectors are shared in the package
+
+	reg := prometheus.NewRegistry()
+	if err := Register(reg); err != nil {
+		t.Fatal(err)
+	}
+
+	before, err := ReadGetLatencyCount(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	SetGetLatency("test-component", 250*time.Millisecond)
+	SetGetLatency("test-component", 2*time.Second)
+
+	after, err := ReadGetLatencyCount(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after-before != 2 {
+		t.Fatalf("expected 2 observations, got %d", after-before)
+	}
+
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, mf := range mfs {
+		if mf.GetName() != "gpud_components_get_latency_seconds" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			if m.GetLabel()[0].GetValue() != "test-component" {
+				continue
+			}
+			found = true
+			if got := m.GetHistogram().GetSampleSum(); got < 2.25 {
+				t.Fatalf("expected sample sum >= 2.25s, got %v", got)
+			}
+		}
+	}
+	if !found {
+		t.Fatal("expected latency histogram for test-component")
+	}
+}
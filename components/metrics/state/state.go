@@ -158,12 +158,11 @@ ORDER BY %s ASC;`,
 
 	rows := make(Metrics, 0)
 	for queryRows.Next() {
-		var unixSeconds int64
 		metric := Metric{
 			MetricName:          name,
 			MetricSecondaryName: secondaryName,
 		}
-		if err := queryRows.Scan(&unixSeconds, &metric.Value); err != nil {
+		if err := queryRows.Scan(&metric.UnixSeconds, &metric.Value); err != nil {
 			return nil, err
 		}
 		rows = append(rows, metric)
@@ -8,6 +8,7 @@ import (
 	"os"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/leptonai/gpud/components"
 	components_metrics "github.com/leptonai/gpud/components/metrics"
@@ -97,7 +98,9 @@ func getDefaultPoller() query.Poller {
 }
 
 func Get(ctx context.Context) (_ any, e error) {
+	start := time.Now()
 	defer func() {
+		components_metrics.SetGetLatency(Name, time.Since(start))
 		if e != nil {
 			components_metrics.SetGetFailed(Name)
 		} else {
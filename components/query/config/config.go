@@ -13,14 +13,46 @@ const (
 	DefaultPollInterval   = time.Minute
 	DefaultQueueSize      = 60
 	DefaultStateRetention = 30 * time.Minute
+	DefaultStatesTimeout  = 10 * time.Second
 )
 
 type Config struct {
 	Interval  metav1.Duration `json:"interval"`
 	QueueSize int             `json:"queue_size"`
 	State     *State          `json:"state,omitempty"`
+
+	// OverlapPolicy decides what to do with the poll tick
+	// when the previous get has not returned yet.
+	// Default is OverlapPolicySkipIfRunning.
+	OverlapPolicy OverlapPolicy `json:"overlap_policy,omitempty"`
+
+	// Jitter is the fraction of the interval to randomly delay each poll tick,
+	// so that the pollers started at the same time do not hit
+	// the data source (e.g., nvidia-smi) at the same instant.
+	// 0.1 to 0.2 is recommended for hosts with many components.
+	// Default is zero (no jitter). Values out of [0, 1] are clamped.
+	Jitter float64 `json:"jitter,omitempty"`
+
+	// StatesTimeout is the deadline for the component States call
+	// to fetch the polled data, after which the call returns
+	// an unhealthy state with the timeout reason.
+	// Default is DefaultStatesTimeout.
+	StatesTimeout metav1.Duration `json:"states_timeout,omitempty"`
 }
 
+// OverlapPolicy is the poller behavior when a get call
+// runs longer than the poll interval.
+type OverlapPolicy string
+
+const (
+	// Skips the ticks while the previous get is running,
+	// to protect slow queries (e.g., nvidia-smi) from stacking up.
+	OverlapPolicySkipIfRunning OverlapPolicy = "skip_if_running"
+	// Queues at most one tick while the previous get is running,
+	// and runs it right after the previous get returns.
+	OverlapPolicyQueue OverlapPolicy = "queue"
+)
+
 func DefaultConfig() Config {
 	return Config{
 		Interval:  metav1.Duration{Duration: DefaultPollInterval},
@@ -28,6 +60,8 @@ func DefaultConfig() Config {
 		State: &State{
 			Retention: metav1.Duration{Duration: DefaultStateRetention},
 		},
+		OverlapPolicy: OverlapPolicySkipIfRunning,
+		StatesTimeout: metav1.Duration{Duration: DefaultStatesTimeout},
 	}
 }
 
@@ -38,6 +72,12 @@ func (cfg *Config) SetDefaultsIfNotSet() {
 	if cfg.QueueSize == 0 {
 		cfg.QueueSize = DefaultQueueSize
 	}
+	if cfg.OverlapPolicy == "" {
+		cfg.OverlapPolicy = OverlapPolicySkipIfRunning
+	}
+	if cfg.StatesTimeout.Duration == 0 {
+		cfg.StatesTimeout.Duration = DefaultStatesTimeout
+	}
 	if cfg.State != nil && cfg.State.Retention.Duration == 0 {
 		cfg.State.Retention = metav1.Duration{Duration: DefaultStateRetention}
 	}
@@ -97,3 +97,19 @@ func TestSetDefaultsIfNotSet(t *testing.T) {
 		})
 	}
 }
+
+func TestSetDefaultsIfNotSetOverlapPolicy(t *testing.T) {
+	t.Parallel()
+
+	cfg := Config{}
+	cfg.SetDefaultsIfNotSet()
+	if cfg.OverlapPolicy != OverlapPolicySkipIfRunning {
+		t.Errorf("OverlapPolicy mismatch: got %q, want %q", cfg.OverlapPolicy, OverlapPolicySkipIfRunning)
+	}
+
+	cfg = Config{OverlapPolicy: OverlapPolicyQueue}
+	cfg.SetDefaultsIfNotSet()
+	if cfg.OverlapPolicy != OverlapPolicyQueue {
+		t.Errorf("OverlapPolicy mismatch: got %q, want %q", cfg.OverlapPolicy, OverlapPolicyQueue)
+	}
+}
@@ -0,0 +1,100 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/nxadm/tail"
+)
+
+// Checkpoint is the last processed position of the log file,
+// to resume the poller after restarts without re-reading
+// the already processed lines.
+type Checkpoint struct {
+	// File is the path of the log file.
+	File string `json:"file"`
+	// Offset is the byte offset right after the last processed line.
+	Offset int64 `json:"offset"`
+	// Inode is the inode of the log file at the offset,
+	// to detect the log rotation.
+	Inode uint64 `json:"inode"`
+}
+
+// Saves the checkpoint to the path, atomically replacing the previous one.
+func SaveCheckpoint(path string, cp Checkpoint) error {
+	b, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Loads the checkpoint from the path.
+// Returns nil if no checkpoint has been saved yet.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	cp := new(Checkpoint)
+	if err := json.Unmarshal(b, cp); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint %q: %w", path, err)
+	}
+	return cp, nil
+}
+
+// Returns the seek info to resume tailing the file from the checkpoint.
+// Returns nil to start from the top of the file, if the checkpoint
+// is for another file, or the file has been rotated (inode changed)
+// or truncated (smaller than the offset) since the checkpoint.
+func (cp *Checkpoint) SeekInfo(file string) (*tail.SeekInfo, error) {
+	if cp == nil || cp.File != file {
+		return nil, nil
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if inode, ok := fileInode(info); ok && inode != cp.Inode {
+		return nil, nil
+	}
+	if info.Size() < cp.Offset {
+		return nil, nil
+	}
+	return &tail.SeekInfo{Offset: cp.Offset, Whence: io.SeekStart}, nil
+}
+
+// Returns the checkpoint of the file at the offset.
+func newCheckpoint(file string, offset int64) (Checkpoint, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	inode, _ := fileInode(info)
+	return Checkpoint{File: file, Offset: offset, Inode: inode}, nil
+}
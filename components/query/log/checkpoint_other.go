@@ -0,0 +1,11 @@
+//go:build !unix
+
+package log
+
+import "os"
+
+// Returns the inode of the file
+// (not supported on this platform).
+func fileInode(info os.FileInfo) (uint64, bool) {
+	return 0, false
+}
@@ -0,0 +1,156 @@
+package log
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	query_config "github.com/leptonai/gpud/components/query/config"
+	query_log_config "github.com/leptonai/gpud/components/query/log/config"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCheckpointSeekInfo(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "test.log")
+	if err := os.WriteFile(file, []byte("line 1\nline 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cpFile := filepath.Join(dir, "checkpoint.json")
+
+	cp, err := LoadCheckpoint(cpFile)
+	if err != nil || cp != nil {
+		t.Fatalf("expected no checkpoint, got %+v, %v", cp, err)
+	}
+	if seekInfo, err := cp.SeekInfo(file); err != nil || seekInfo != nil {
+		t.Fatalf("expected to start from the top without checkpoint, got %+v, %v", seekInfo, err)
+	}
+
+	saved, err := newCheckpoint(file, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveCheckpoint(cpFile, saved); err != nil {
+		t.Fatal(err)
+	}
+	cp, err = LoadCheckpoint(cpFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *cp != saved {
+		t.Fatalf("loaded checkpoint %+v, want %+v", *cp, saved)
+	}
+
+	seekInfo, err := cp.SeekInfo(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seekInfo == nil || seekInfo.Offset != 7 {
+		t.Fatalf("expected to resume from offset 7, got %+v", seekInfo)
+	}
+
+	// checkpoint of another file
+	if seekInfo, err := cp.SeekInfo(filepath.Join(dir, "other.log")); err != nil || seekInfo != nil {
+		t.Fatalf("expected to start from the top for another file, got %+v, %v", seekInfo, err)
+	}
+
+	// rotated (new inode), while the old file is kept around
+	// so that the new file does not reuse the inode
+	if err := os.Rename(file, file+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte("line 3\nline 4\nline 5\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if seekInfo, err := cp.SeekInfo(file); err != nil || seekInfo != nil {
+		t.Fatalf("expected to start from the top after rotation, got %+v, %v", seekInfo, err)
+	}
+
+	// truncated
+	rotated, err := newCheckpoint(file, 14)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(file, 7); err != nil {
+		t.Fatal(err)
+	}
+	if seekInfo, err := rotated.SeekInfo(file); err != nil || seekInfo != nil {
+		t.Fatalf("expected to start from the top after truncation, got %+v, %v", seekInfo, err)
+	}
+}
+
+func TestPollerCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "test.log")
+	if err := os.WriteFile(file, []byte("line 1\nline 2\nline 3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cpFile := filepath.Join(dir, "checkpoint.json")
+
+	// already processed the first two lines before the restart
+	cp, err := newCheckpoint(file, int64(len("line 1\nline 2\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveCheckpoint(cpFile, cp); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := pollLines(t, query_log_config.Config{File: file, CheckpointFile: cpFile})
+	if len(lines) != 1 || lines[0] != "line 3" {
+		t.Fatalf("expected to resume from line 3, got %v", lines)
+	}
+
+	cp2, err := LoadCheckpoint(cpFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp2 == nil || cp2.Offset != int64(len("line 1\nline 2\nline 3\n")) {
+		t.Fatalf("expected the checkpoint at the end of the file, got %+v", cp2)
+	}
+
+	// rotated, restarts from the top of the new file
+	if err := os.Rename(file, file+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte("line 4\nline 5\nline 6\nline 7\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	lines = pollLines(t, query_log_config.Config{File: file, CheckpointFile: cpFile})
+	if len(lines) != 4 || lines[0] != "line 4" {
+		t.Fatalf("expected to read the rotated file from the top, got %v", lines)
+	}
+}
+
+// Polls the log file until the poller flushes the lines, and returns them.
+func pollLines(t *testing.T, cfg query_log_config.Config) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	pl, err := newPoller(ctx, cfg, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create log poller: %v", err)
+	}
+	defer pl.Stop("test")
+
+	pl.Start(ctx, query_config.Config{Interval: metav1.Duration{Duration: time.Second}}, "test")
+
+	time.Sleep(3 * time.Second)
+
+	items, err := pl.Find(time.Time{})
+	if err != nil {
+		t.Fatalf("failed to find items: %v", err)
+	}
+	lines := make([]string, 0, len(items))
+	for _, item := range items {
+		lines = append(lines, item.Line)
+	}
+	return lines
+}
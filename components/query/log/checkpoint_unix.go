@@ -0,0 +1,17 @@
+//go:build unix
+
+package log
+
+import (
+	"os"
+	"syscall"
+)
+
+// Returns the inode of the file.
+func fileInode(info os.FileInfo) (uint64, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(st.Ino), true
+}
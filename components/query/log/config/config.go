@@ -6,11 +6,14 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"time"
 
 	query_config "github.com/leptonai/gpud/components/query/config"
 	query_log_filter "github.com/leptonai/gpud/components/query/log/filter"
 
 	"github.com/nxadm/tail"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const DefaultBufferSize = 2000
@@ -39,11 +42,32 @@ type Config struct {
 	// (e.g., good healthy log messages).
 	RejectFilters []*query_log_filter.Filter `json:"reject_filters"`
 
+	// Collapses the identical events that repeat within this window
+	// into a single event with its occurrence count.
+	// Only applies to the components that support de-duplication.
+	// Zero to disable (default).
+	DedupWindow metav1.Duration `json:"dedup_window,omitempty"`
+
 	DB       *sql.DB        `json:"-"`
 	SeekInfo *tail.SeekInfo `json:"seek_info,omitempty"`
 
 	// Used to commit the last seek info to disk.
 	SeekInfoSyncer func(ctx context.Context, file string, seekInfo tail.SeekInfo) `json:"-"`
+
+	// Called once for each line the poller reads (after the filters and the parsing),
+	// in the order read, so that the line is counted once (e.g., for the metrics)
+	// regardless of how many times the polled items are queried.
+	LineObserver func(line string, time time.Time) `json:"-"`
+
+	// CheckpointFile is the path to record the last processed offset
+	// (and inode) of the log file on every flush, so that the poller
+	// resumes from there after restarts, instead of re-reading
+	// the already processed lines.
+	// If the log file has been rotated since, the poller starts
+	// from the top of the new file.
+	// Ignored if SeekInfo is set, or the poller runs commands.
+	// Empty to disable (default).
+	CheckpointFile string `json:"checkpoint_file,omitempty"`
 }
 
 // For each interval, execute the scanning operation
@@ -67,6 +91,14 @@ func (cfg *Config) Validate() error {
 	if len(cfg.SelectFilters) > 0 && len(cfg.RejectFilters) > 0 {
 		return errors.New("cannot have both select and reject filters")
 	}
+	for _, f := range append(append([]*query_log_filter.Filter{}, cfg.SelectFilters...), cfg.RejectFilters...) {
+		if err := f.Compile(); err != nil {
+			return fmt.Errorf("invalid filter %q: %w", f.Name, err)
+		}
+	}
+	if cfg.DedupWindow.Duration < 0 {
+		return errors.New("dedup window must be non-negative")
+	}
 	return nil
 }
 
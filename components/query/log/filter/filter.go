@@ -18,14 +18,38 @@ type Filter struct {
 	Regex *string        `json:"regex,omitempty"`
 	regex *regexp.Regexp `json:"-"`
 
+	// ExcludeRegex is the optional regex to exclude the known-benign lines.
+	// A line matching Substring or Regex but also matching ExcludeRegex
+	// is not reported (exclusion takes precedence).
+	ExcludeRegex *string        `json:"exclude_regex,omitempty"`
+	excludeRegex *regexp.Regexp `json:"-"`
+
 	// OwnerReferences is a list of component names that watches on this filter.
 	// Useful when multiple components watch on the same log file.
 	// e.g., if the component X and Y both watch on the same log file,
 	// with the same filter rule (substring/regex), this field will be
 	// set to [x, y].
 	OwnerReferences []string `json:"owner_references,omitempty"`
+
+	// Severity is the optional severity of the matched logs
+	// (e.g., SeverityWarn), so that the consumers can prioritize.
+	Severity string `json:"severity,omitempty"`
+	// SuggestedAction is the optional remediation hint for the matched logs.
+	SuggestedAction string `json:"suggested_action,omitempty"`
+
+	// MultiLine is the optional multi-line mode, to assemble the matched line
+	// and its following lines into a single block (see BlockAssembler).
+	// Defaults to the single-line mode if nil.
+	MultiLine *MultiLine `json:"multi_line,omitempty"`
 }
 
+// Severity levels of the filter, which match the component event types.
+const (
+	SeverityInfo  = "info"
+	SeverityWarn  = "warn"
+	SeverityError = "error"
+)
+
 func (f *Filter) JSON() ([]byte, error) {
 	return json.Marshal(f)
 }
@@ -51,7 +75,8 @@ func ParseFilterYAML(data []byte) (*Filter, error) {
 	return f, nil
 }
 
-// Compiles the regex, if set.
+// Compiles the regex and the exclude regex, if set,
+// and validates the multi-line mode.
 func (f *Filter) Compile() error {
 	if f.Regex != nil {
 		rgx, err := regexp.Compile(*f.Regex)
@@ -60,11 +85,51 @@ func (f *Filter) Compile() error {
 		}
 		f.regex = rgx
 	}
+	if f.ExcludeRegex != nil {
+		rgx, err := regexp.Compile(*f.ExcludeRegex)
+		if err != nil {
+			return err
+		}
+		f.excludeRegex = rgx
+	}
+	if f.MultiLine != nil {
+		if err := f.MultiLine.compile(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// Returns true if the component is one of the owner references.
+func (f *Filter) OwnedBy(owner string) bool {
+	for _, ref := range f.OwnerReferences {
+		if ref == owner {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the filters owned by the component (see OwnerReferences),
+// in the same order as the given filters.
+func FiltersForOwner(filters []*Filter, owner string) []*Filter {
+	owned := make([]*Filter, 0)
+	for _, f := range filters {
+		if f.OwnedBy(owner) {
+			owned = append(owned, f)
+		}
+	}
+	return owned
+}
+
+func (f *Filter) needsCompile() bool {
+	return (f.Regex != nil && f.regex == nil) ||
+		(f.ExcludeRegex != nil && f.excludeRegex == nil) ||
+		(f.MultiLine != nil && f.MultiLine.needsCompile())
+}
+
 func (f *Filter) MatchString(line string) (bool, error) {
-	if f.Regex != nil && f.regex == nil {
+	if f.needsCompile() {
 		if err := f.Compile(); err != nil {
 			return false, err
 		}
@@ -73,7 +138,7 @@ func (f *Filter) MatchString(line string) (bool, error) {
 }
 
 func (f *Filter) MatchBytes(line []byte) (bool, error) {
-	if f.Regex != nil && f.regex == nil {
+	if f.needsCompile() {
 		if err := f.Compile(); err != nil {
 			return false, err
 		}
@@ -82,21 +147,19 @@ func (f *Filter) MatchBytes(line []byte) (bool, error) {
 }
 
 func (f *Filter) matchString(line string) bool {
-	if f.Substring != nil && strings.Contains(line, *f.Substring) {
-		return true
-	}
-	if f.regex != nil && f.regex.MatchString(line) {
-		return true
+	included := (f.Substring != nil && strings.Contains(line, *f.Substring)) ||
+		(f.regex != nil && f.regex.MatchString(line))
+	if !included {
+		return false
 	}
-	return false
+	return f.excludeRegex == nil || !f.excludeRegex.MatchString(line)
 }
 
 func (f *Filter) matchBytes(line []byte) bool {
-	if f.Substring != nil && bytes.Contains(line, []byte(*f.Substring)) {
-		return true
-	}
-	if f.regex != nil && f.regex.Match(line) {
-		return true
+	included := (f.Substring != nil && bytes.Contains(line, []byte(*f.Substring))) ||
+		(f.regex != nil && f.regex.Match(line))
+	if !included {
+		return false
 	}
-	return false
+	return f.excludeRegex == nil || !f.excludeRegex.Match(line)
 }
@@ -0,0 +1,96 @@
+package filter
+
+import (
+	"testing"
+
+	"k8s.io/utils/ptr"
+)
+
+func TestFilterExcludeRegex(t *testing.T) {
+	t.Parallel()
+
+	f := &Filter{
+		Name:         "oom",
+		Regex:        ptr.To(`Out of memory:`),
+		ExcludeRegex: ptr.To(`\(test-harness\)`),
+	}
+
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{line: "Out of memory: Killed process 123, UID 48, (httpd).", want: true},
+		// matches both the include and exclude, exclusion takes precedence
+		{line: "Out of memory: Killed process 456, UID 0, (test-harness).", want: false},
+		// matches only the exclude
+		{line: "started (test-harness)", want: false},
+		{line: "nothing to see here", want: false},
+	}
+	for _, tt := range tests {
+		got, err := f.MatchString(tt.line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("MatchString(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+		got, err = f.MatchBytes([]byte(tt.line))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("MatchBytes(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestFilterExcludeRegexWithSubstring(t *testing.T) {
+	t.Parallel()
+
+	f := &Filter{
+		Name:         "xid",
+		Substring:    ptr.To("NVRM: Xid"),
+		ExcludeRegex: ptr.To(`: 13,`),
+	}
+	if ok, _ := f.MatchString("NVRM: Xid (PCI:0000:01:00): 79, GPU has fallen off the bus."); !ok {
+		t.Error("expected match")
+	}
+	if ok, _ := f.MatchString("NVRM: Xid (PCI:0000:01:00): 13, Graphics Exception"); ok {
+		t.Error("expected exclusion")
+	}
+}
+
+func TestFilterCompileInvalidExcludeRegex(t *testing.T) {
+	t.Parallel()
+
+	f := &Filter{
+		Name:         "invalid",
+		Regex:        ptr.To(`ok`),
+		ExcludeRegex: ptr.To(`(`),
+	}
+	if err := f.Compile(); err == nil {
+		t.Fatal("expected compile error")
+	}
+	if _, err := f.MatchString("ok"); err == nil {
+		t.Fatal("expected match error")
+	}
+}
+
+func TestFiltersForOwner(t *testing.T) {
+	t.Parallel()
+
+	filters := []*Filter{
+		{Name: "a", OwnerReferences: []string{"x"}},
+		{Name: "b", OwnerReferences: []string{"y"}},
+		{Name: "c", OwnerReferences: []string{"y", "x"}},
+		{Name: "d"},
+	}
+
+	owned := FiltersForOwner(filters, "x")
+	if len(owned) != 2 || owned[0].Name != "a" || owned[1].Name != "c" {
+		t.Fatalf("unexpected filters for x: %+v", owned)
+	}
+	if owned := FiltersForOwner(filters, "z"); len(owned) != 0 {
+		t.Fatalf("expected no filters for z, got %+v", owned)
+	}
+}
@@ -0,0 +1,182 @@
+package filter
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultMultiLineMaxLines is the default max number of the lines
+// in a multi-line block, when only the end regex is set.
+const DefaultMultiLineMaxLines = 512
+
+// DefaultMultiLineIdleTimeout is the default time to wait for the next line
+// of the incomplete multi-line block, before the block is flushed as is.
+const DefaultMultiLineIdleTimeout = 5 * time.Second
+
+var ErrMultiLineNoEnd = errors.New("multi-line requires the end regex or the max lines")
+
+// MultiLine is the optional multi-line mode of the filter,
+// to assemble the line matching the filter (the start line)
+// and its following lines into a single block
+// (e.g., kernel stack traces, OOM killer dumps).
+//
+// The block ends at the line matching the end regex (inclusive),
+// at the max lines, at the start of the next block, when no line follows
+// within the idle timeout (see BlockAssembler.FlushIdle),
+// or at the end of the stream, whichever comes first.
+type MultiLine struct {
+	// EndRegex is the optional regex of the last line of the block.
+	EndRegex *string        `json:"end_regex,omitempty"`
+	endRegex *regexp.Regexp `json:"-"`
+
+	// MaxLines is the max number of the lines in the block, including the start line.
+	// Must be at least 2, as a block of the start line alone is a single line.
+	// Defaults to DefaultMultiLineMaxLines if zero and the end regex is set.
+	MaxLines int `json:"max_lines,omitempty"`
+
+	// IdleTimeout is the time to wait for the next line of the incomplete block
+	// (e.g., the end line that the older kernels never print),
+	// before the block is flushed as is, so that a followed stream
+	// does not hold the block back indefinitely.
+	// Defaults to DefaultMultiLineIdleTimeout if zero.
+	// Negative to disable (the block waits for the next line).
+	IdleTimeout metav1.Duration `json:"idle_timeout,omitempty"`
+}
+
+// Validates and compiles the end regex, if set.
+func (m *MultiLine) compile() error {
+	if m.MaxLines < 0 || m.MaxLines == 1 {
+		return errors.New("multi-line max lines must be zero (default) or at least 2")
+	}
+	if m.EndRegex == nil && m.MaxLines == 0 {
+		return ErrMultiLineNoEnd
+	}
+	if m.EndRegex != nil {
+		rgx, err := regexp.Compile(*m.EndRegex)
+		if err != nil {
+			return err
+		}
+		m.endRegex = rgx
+	}
+	return nil
+}
+
+func (m *MultiLine) needsCompile() bool {
+	return m.EndRegex != nil && m.endRegex == nil
+}
+
+func (m *MultiLine) idleTimeout() time.Duration {
+	if m.IdleTimeout.Duration == 0 {
+		return DefaultMultiLineIdleTimeout
+	}
+	return m.IdleTimeout.Duration
+}
+
+func (m *MultiLine) maxLines() int {
+	if m.MaxLines > 0 {
+		return m.MaxLines
+	}
+	return DefaultMultiLineMaxLines
+}
+
+// Block is the lines assembled by a multi-line filter.
+type Block[T any] struct {
+	// Filter is the multi-line filter that matched the start line.
+	Filter *Filter
+	// Lines are the lines in the block, starting from the start line.
+	Lines []string
+	// First is the metadata of the start line (e.g., timestamp).
+	First T
+	// Last is the metadata of the last line (e.g., file offset).
+	Last T
+}
+
+// Returns the lines in the block joined with the newline.
+func (b *Block[T]) Text() string {
+	return strings.Join(b.Lines, "\n")
+}
+
+// BlockAssembler assembles the lines into the blocks
+// for the filters with the multi-line mode.
+// The lines must be fed in order, and the assembler is not safe for concurrent use.
+type BlockAssembler[T any] struct {
+	pending *Block[T]
+	// the time the pending block was last fed
+	updated time.Time
+}
+
+// Feeds the next line with its metadata, and the filter that matched the line
+// (nil if none matched, regardless of the multi-line mode).
+//
+// Returns the completed block, if any, which must be emitted before the line.
+// Returns started true if the line is the start line of a new block,
+// in which case the line must not be emitted on its own.
+// The lines in the middle of a block are returned as not started,
+// so the caller may still emit them on their own if they match any other filter.
+func (a *BlockAssembler[T]) Feed(line string, meta T, matched *Filter) (done *Block[T], started bool) {
+	a.updated = time.Now()
+
+	if matched != nil && matched.MultiLine != nil {
+		done = a.pending
+		a.pending = &Block[T]{
+			Filter: matched,
+			Lines:  []string{line},
+			First:  meta,
+			Last:   meta,
+		}
+		if a.pending.complete(line) {
+			done, a.pending = a.pending, nil
+		}
+		return done, true
+	}
+
+	if a.pending == nil {
+		return nil, false
+	}
+
+	a.pending.Lines = append(a.pending.Lines, line)
+	a.pending.Last = meta
+	if a.pending.complete(line) {
+		done, a.pending = a.pending, nil
+	}
+	return done, false
+}
+
+// Returns the pending block if no line has been fed for its idle timeout
+// since the last line (see MultiLine.IdleTimeout), and resets the assembler.
+// Call periodically while waiting for the next line of a followed stream.
+func (a *BlockAssembler[T]) FlushIdle(now time.Time) *Block[T] {
+	if a.pending == nil {
+		return nil
+	}
+	timeout := a.pending.Filter.MultiLine.idleTimeout()
+	if timeout < 0 || now.Sub(a.updated) < timeout {
+		return nil
+	}
+	return a.Flush()
+}
+
+// Returns the pending block, if any, and resets the assembler.
+// Call at the end of the stream.
+func (a *BlockAssembler[T]) Flush() *Block[T] {
+	done := a.pending
+	a.pending = nil
+	return done
+}
+
+// Returns true if the last added line completes the block.
+func (b *Block[T]) complete(last string) bool {
+	ml := b.Filter.MultiLine
+	if len(b.Lines) >= ml.maxLines() {
+		return true
+	}
+	// the end regex never matches the start line
+	if len(b.Lines) > 1 && ml.endRegex != nil && ml.endRegex.MatchString(last) {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,239 @@
+package filter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+const testOOMDump = `python invoked oom-killer: gfp_mask=0x140cca(GFP_HIGHUSER_MOVABLE|__GFP_COMP), order=0, oom_score_adj=0
+CPU: 3 PID: 123 Comm: python Not tainted 5.15.0-1053-nvidia #54-Ubuntu
+Call Trace:
+ dump_stack_lvl+0x48/0x70
+ oom_kill_process+0x10b/0x190
+Tasks state (memory values in pages):
+[  pid  ]   uid  tgid total_vm      rss pgtables_bytes swapents oom_score_adj name
+[    123]     0   123  8388608  8123456 66060288        0             0 python
+oom-kill:constraint=CONSTRAINT_NONE,nodemask=(null),cpuset=/,mems_allowed=0,global_oom,task_memcg=/,task=python,pid=123,uid=0
+Out of memory: Killed process 123 (python) total-vm:33554432kB, anon-rss:32493824kB
+eth0: link down`
+
+// Feeds the lines matched by the filters (first match), and returns the emitted texts
+// in order, with the filter name (empty for the unmatched lines).
+func assemble(t *testing.T, lines []string, filters ...*Filter) [][2]string {
+	var (
+		a       BlockAssembler[int]
+		emitted [][2]string
+	)
+	for i, line := range lines {
+		var matched *Filter
+		for _, f := range filters {
+			ok, err := f.MatchString(line)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok {
+				matched = f
+				break
+			}
+		}
+
+		done, started := a.Feed(line, i, matched)
+		if done != nil {
+			if done.Last-done.First != len(done.Lines)-1 {
+				t.Fatalf("unexpected block metadata [%d, %d] for %d lines", done.First, done.Last, len(done.Lines))
+			}
+			emitted = append(emitted, [2]string{done.Filter.Name, done.Text()})
+		}
+		if matched != nil && !started {
+			emitted = append(emitted, [2]string{matched.Name, line})
+		}
+	}
+	if done := a.Flush(); done != nil {
+		emitted = append(emitted, [2]string{done.Filter.Name, done.Text()})
+	}
+	return emitted
+}
+
+func TestBlockAssemblerOOMDump(t *testing.T) {
+	t.Parallel()
+
+	lines := strings.Split(testOOMDump, "\n")
+	oomKiller := &Filter{
+		Name:  "oom_killer",
+		Regex: ptr.To(`(?i)\b(invoked|triggered) oom-killer\b`),
+		MultiLine: &MultiLine{
+			EndRegex: ptr.To(`oom-kill:constraint=`),
+		},
+	}
+	oomKill := &Filter{
+		Name:  "oom_kill",
+		Regex: ptr.To(`Out of memory:`),
+	}
+
+	emitted := assemble(t, lines, oomKiller, oomKill)
+	if len(emitted) != 2 {
+		t.Fatalf("expected 2 emitted, got %d: %q", len(emitted), emitted)
+	}
+	if emitted[0][0] != "oom_killer" {
+		t.Fatalf("expected the oom_killer block first, got %q", emitted[0][0])
+	}
+	if expected := strings.Join(lines[:9], "\n"); emitted[0][1] != expected {
+		t.Fatalf("expected block:\n%s\ngot:\n%s", expected, emitted[0][1])
+	}
+	if !strings.Contains(emitted[0][1], "8123456 66060288        0             0 python") {
+		t.Fatal("expected the victim table in the block")
+	}
+	if emitted[1] != [2]string{"oom_kill", lines[9]} {
+		t.Fatalf("unexpected single line %q", emitted[1])
+	}
+
+	// single-line mode by default
+	oomKiller.MultiLine = nil
+	emitted = assemble(t, lines, oomKiller, oomKill)
+	if len(emitted) != 2 || emitted[0][1] != lines[0] {
+		t.Fatalf("expected the single lines, got %q", emitted)
+	}
+}
+
+func TestBlockAssemblerEnds(t *testing.T) {
+	t.Parallel()
+
+	lines := strings.Split(testOOMDump, "\n")
+
+	// bounded by the max lines
+	f := &Filter{
+		Name:      "oom_killer",
+		Substring: ptr.To("invoked oom-killer"),
+		MultiLine: &MultiLine{MaxLines: 3},
+	}
+	emitted := assemble(t, lines, f)
+	if len(emitted) != 1 || emitted[0][1] != strings.Join(lines[:3], "\n") {
+		t.Fatalf("expected the 3-line block, got %q", emitted)
+	}
+
+	// end regex never matched, flushed at the end of the stream
+	f = &Filter{
+		Name:      "oom_killer",
+		Substring: ptr.To("invoked oom-killer"),
+		MultiLine: &MultiLine{EndRegex: ptr.To(`never matches`)},
+	}
+	emitted = assemble(t, lines, f)
+	if len(emitted) != 1 || emitted[0][1] != testOOMDump {
+		t.Fatalf("expected the flushed block, got %q", emitted)
+	}
+
+	// the next start line ends the pending block
+	emitted = assemble(t, append(lines[:2:2], lines...), f)
+	if len(emitted) != 2 || emitted[0][1] != strings.Join(lines[:2], "\n") || emitted[1][1] != testOOMDump {
+		t.Fatalf("expected 2 blocks, got %q", emitted)
+	}
+}
+
+func TestBlockAssemblerFlushIdle(t *testing.T) {
+	t.Parallel()
+
+	// the older kernels never print the end line
+	var lines []string
+	for _, line := range strings.Split(testOOMDump, "\n") {
+		if strings.HasPrefix(line, "oom-kill:constraint=") {
+			break
+		}
+		lines = append(lines, line)
+	}
+
+	f := &Filter{
+		Name:      "oom_killer",
+		Substring: ptr.To("invoked oom-killer"),
+		MultiLine: &MultiLine{EndRegex: ptr.To(`oom-kill:constraint=`), IdleTimeout: metav1.Duration{Duration: time.Minute}},
+	}
+	if err := f.Compile(); err != nil {
+		t.Fatal(err)
+	}
+
+	var a BlockAssembler[int]
+	if done := a.FlushIdle(time.Now()); done != nil {
+		t.Fatalf("expected no block without the pending block, got %+v", done)
+	}
+	for i, line := range lines {
+		var matched *Filter
+		if ok, _ := f.MatchString(line); ok {
+			matched = f
+		}
+		if done, _ := a.Feed(line, i, matched); done != nil {
+			t.Fatalf("unexpected block before the end line: %q", done.Text())
+		}
+	}
+
+	// not idle yet
+	if done := a.FlushIdle(time.Now()); done != nil {
+		t.Fatalf("unexpected block before the idle timeout: %q", done.Text())
+	}
+
+	done := a.FlushIdle(time.Now().Add(time.Minute))
+	if done == nil {
+		t.Fatal("expected the block flushed after the idle timeout")
+	}
+	if done.Text() != strings.Join(lines, "\n") || done.First != 0 || done.Last != len(lines)-1 {
+		t.Fatalf("unexpected flushed block: %+v", done)
+	}
+	if a.Flush() != nil {
+		t.Fatal("expected no pending block after the idle flush")
+	}
+
+	// negative disables the idle flush
+	f.MultiLine.IdleTimeout = metav1.Duration{Duration: -1}
+	a.Feed(lines[0], 0, f)
+	if done := a.FlushIdle(time.Now().Add(time.Hour)); done != nil {
+		t.Fatalf("unexpected block with the idle flush disabled: %q", done.Text())
+	}
+}
+
+func TestMultiLineCompile(t *testing.T) {
+	t.Parallel()
+
+	f := &Filter{Name: "x", Substring: ptr.To("x"), MultiLine: &MultiLine{}}
+	if err := f.Compile(); !errors.Is(err, ErrMultiLineNoEnd) {
+		t.Fatalf("expected %v, got %v", ErrMultiLineNoEnd, err)
+	}
+	f.MultiLine = &MultiLine{MaxLines: -1}
+	if err := f.Compile(); err == nil {
+		t.Fatal("expected error for the negative max lines")
+	}
+	// the start line alone would complete the block,
+	// dropping the pending block on the next start line
+	f.MultiLine = &MultiLine{MaxLines: 1}
+	if err := f.Compile(); err == nil {
+		t.Fatal("expected error for the single max line")
+	}
+	f.MultiLine = &MultiLine{EndRegex: ptr.To(`(`)}
+	if err := f.Compile(); err == nil {
+		t.Fatal("expected error for the invalid end regex")
+	}
+	if _, err := f.MatchString("x"); err == nil {
+		t.Fatal("expected lazy compile error for the invalid end regex")
+	}
+
+	f.MultiLine = &MultiLine{EndRegex: ptr.To(`^end$`)}
+	b, err := f.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseFilterJSON(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.MultiLine == nil || *parsed.MultiLine.EndRegex != `^end$` {
+		t.Fatalf("expected the multi-line round trip, got %s", b)
+	}
+	if err := parsed.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	if parsed.MultiLine.maxLines() != DefaultMultiLineMaxLines {
+		t.Fatalf("expected the default max lines, got %d", parsed.MultiLine.maxLines())
+	}
+}
@@ -0,0 +1,61 @@
+package log
+
+import (
+	query_log_tail "github.com/leptonai/gpud/components/query/log/tail"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LineParser parses the streamed log line into an item,
+// to plug the log source specific parsing (e.g., the timestamp format)
+// into the poller (see NewWithLineParser).
+type LineParser interface {
+	// Returns the item parsed from the line, and false to drop the line.
+	// The zero fields of the item fall back to the streamed line
+	// (e.g., the time the line was read, the matched filter).
+	ParseLine(line []byte) (Item, bool, error)
+}
+
+var _ LineParser = LineParserFunc(nil)
+
+// LineParserFunc adapts the function to the LineParser.
+type LineParserFunc func(line []byte) (Item, bool, error)
+
+func (f LineParserFunc) ParseLine(line []byte) (Item, bool, error) {
+	return f(line)
+}
+
+// Returns the item of the streamed line parsed by the parser, and false to drop the line.
+// The zero fields of the parsed item are set from the streamed line.
+func parseLine(parser LineParser, line query_log_tail.Line) (Item, bool, error) {
+	streamed := Item{
+		Time:    metav1.Time{Time: line.Time},
+		Line:    line.Text,
+		Matched: line.MatchedFilter,
+		Error:   line.Err,
+	}
+	if parser == nil {
+		return streamed, true, nil
+	}
+
+	item, ok, err := parser.ParseLine([]byte(line.Text))
+	if err != nil {
+		return streamed, true, err
+	}
+	if !ok {
+		return Item{}, false, nil
+	}
+	if item.Time.IsZero() {
+		item.Time = streamed.Time
+	}
+	if item.Line == "" {
+		item.Line = streamed.Line
+	}
+	if item.Matched == nil {
+		item.Matched = streamed.Matched
+	}
+	if item.Error == nil {
+		item.Error = streamed.Error
+	}
+	return item, true, nil
+}
@@ -0,0 +1,133 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	query_log_config "github.com/leptonai/gpud/components/query/log/config"
+	query_log_filter "github.com/leptonai/gpud/components/query/log/filter"
+	query_log_tail "github.com/leptonai/gpud/components/query/log/tail"
+
+	"github.com/nxadm/tail"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// parses the lines in the format of "<RFC3339 time> <level> <message>",
+// dropping the debug lines
+var testLevelParser = LineParserFunc(func(line []byte) (Item, bool, error) {
+	fields := bytes.SplitN(line, []byte(" "), 3)
+	if len(fields) != 3 {
+		return Item{}, false, errors.New("invalid line")
+	}
+	if string(fields[1]) == "debug" {
+		return Item{}, false, nil
+	}
+	t, err := time.Parse(time.RFC3339, string(fields[0]))
+	if err != nil {
+		return Item{}, false, err
+	}
+	return Item{
+		Time:    metav1.NewTime(t),
+		Matched: &query_log_filter.Filter{Name: string(fields[1])},
+	}, true, nil
+})
+
+func TestParseLine(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC()
+	streamed := func(text string) query_log_tail.Line {
+		return query_log_tail.Line{
+			Line:          &tail.Line{Text: text, Time: now},
+			MatchedFilter: &query_log_filter.Filter{Name: "streamed"},
+		}
+	}
+
+	// no parser
+	item, ok, err := parseLine(nil, streamed("hello"))
+	if err != nil || !ok || item.Line != "hello" || !item.Time.Time.Equal(now) || item.Matched.Name != "streamed" {
+		t.Fatalf("unexpected item %+v (ok %v, error %v)", item, ok, err)
+	}
+
+	item, ok, err = parseLine(testLevelParser, streamed("2024-07-09T18:14:07Z error failed"))
+	if err != nil || !ok {
+		t.Fatalf("unexpected result (ok %v, error %v)", ok, err)
+	}
+	if item.Line != "2024-07-09T18:14:07Z error failed" {
+		t.Fatalf("expected the streamed line, got %q", item.Line)
+	}
+	if expected := time.Date(2024, time.July, 9, 18, 14, 7, 0, time.UTC); !item.Time.Time.Equal(expected) {
+		t.Fatalf("expected the parsed time %v, got %v", expected, item.Time)
+	}
+	if item.Matched.Name != "error" {
+		t.Fatalf("expected the parsed filter, got %+v", item.Matched)
+	}
+
+	// dropped
+	if _, ok, err = parseLine(testLevelParser, streamed("2024-07-09T18:14:07Z debug noisy")); err != nil || ok {
+		t.Fatalf("expected the line dropped (ok %v, error %v)", ok, err)
+	}
+
+	// failed to parse, kept as streamed
+	item, ok, err = parseLine(testLevelParser, streamed("not-a-time error failed"))
+	if err == nil || !ok || !item.Time.Time.Equal(now) || item.Matched.Name != "streamed" {
+		t.Fatalf("expected the streamed item with the error, got %+v (ok %v, error %v)", item, ok, err)
+	}
+}
+
+func TestPollerWithLineParser(t *testing.T) {
+	t.Parallel()
+
+	f, err := os.CreateTemp(os.TempDir(), "test-log")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(f.Name())
+
+	lines := "2024-07-09T18:14:07Z error failed to connect\n" +
+		"2024-07-09T18:14:08Z debug retrying\n" +
+		"2024-07-09T18:14:09Z info connected\n"
+	if _, err := f.WriteString(lines); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	poller, err := NewWithLineParser(ctx, query_log_config.Config{File: f.Name()}, testLevelParser)
+	if err != nil {
+		t.Fatalf("failed to create log poller: %v", err)
+	}
+
+	var items []Item
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(100 * time.Millisecond) {
+		items, err = poller.Find(time.Time{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items) >= 2 {
+			break
+		}
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items without the debug line, got %d: %+v", len(items), items)
+	}
+	for i, expected := range []struct {
+		level string
+		sec   int
+	}{{"error", 7}, {"info", 9}} {
+		if items[i].Matched == nil || items[i].Matched.Name != expected.level {
+			t.Fatalf("item %d: expected level %q, got %+v", i, expected.level, items[i].Matched)
+		}
+		if items[i].Time.Second() != expected.sec || items[i].Time.Year() != 2024 {
+			t.Fatalf("item %d: expected the parsed time, got %v", i, items[i].Time)
+		}
+	}
+}
@@ -72,7 +72,8 @@ type Item struct {
 type poller struct {
 	query.Poller
 
-	cfg query_log_config.Config
+	cfg    query_log_config.Config
+	parser LineParser
 
 	tailLogger             query_log_tail.Streamer
 	tailFileSeekInfoMu     sync.RWMutex
@@ -81,13 +82,25 @@ type poller struct {
 
 	bufferedItemsMu sync.RWMutex
 	bufferedItems   []Item
+	// the seek info right after the last buffered item
+	bufferedSeekInfo *tail.SeekInfo
 }
 
+// Creates the log poller that parses the timestamp of each command output line
+// with the parseTime function (the file lines are timestamped when read).
 func New(ctx context.Context, cfg query_log_config.Config, parseTime query_log_tail.ParseTimeFunc) (Poller, error) {
-	return newPoller(ctx, cfg, parseTime)
+	return newPoller(ctx, cfg, parseTime, nil)
 }
 
-func newPoller(ctx context.Context, cfg query_log_config.Config, parseTime query_log_tail.ParseTimeFunc) (*poller, error) {
+// Creates the log poller that parses each streamed line with the parser,
+// so that the log sources (e.g., dmesg, fabric manager) share the same polling
+// with their own parsing. If nil, the lines are polled as streamed.
+// The line that fails to parse is polled as streamed.
+func NewWithLineParser(ctx context.Context, cfg query_log_config.Config, parser LineParser) (Poller, error) {
+	return newPoller(ctx, cfg, nil, parser)
+}
+
+func newPoller(ctx context.Context, cfg query_log_config.Config, parseTime query_log_tail.ParseTimeFunc, parser LineParser) (*poller, error) {
 	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
@@ -102,7 +115,14 @@ func newPoller(ctx context.Context, cfg query_log_config.Config, parseTime query
 	var tailLogger query_log_tail.Streamer
 	var err error
 	if cfg.File != "" {
-		tailLogger, err = query_log_tail.NewFromFile(cfg.File, cfg.SeekInfo, options...)
+		seekInfo := cfg.SeekInfo
+		if seekInfo == nil && cfg.CheckpointFile != "" {
+			seekInfo, err = resumeSeekInfo(cfg.CheckpointFile, cfg.File)
+			if err != nil {
+				return nil, err
+			}
+		}
+		tailLogger, err = query_log_tail.NewFromFile(cfg.File, seekInfo, options...)
 	} else {
 		tailLogger, err = query_log_tail.NewFromCommand(ctx, cfg.Commands, options...)
 	}
@@ -112,6 +132,7 @@ func newPoller(ctx context.Context, cfg query_log_config.Config, parseTime query
 
 	pl := &poller{
 		cfg:                    cfg,
+		parser:                 parser,
 		tailLogger:             tailLogger,
 		tailFileSeekInfoSyncer: cfg.SeekInfoSyncer,
 		bufferedItems:          make([]Item, 0, cfg.BufferSize),
@@ -124,6 +145,17 @@ func newPoller(ctx context.Context, cfg query_log_config.Config, parseTime query
 		copied := make([]Item, len(pl.bufferedItems))
 		copy(copied, pl.bufferedItems)
 		pl.bufferedItems = pl.bufferedItems[:0]
+
+		if pl.bufferedSeekInfo != nil && cfg.File != "" && cfg.CheckpointFile != "" {
+			cp, err := newCheckpoint(pl.tailLogger.File(), pl.bufferedSeekInfo.Offset)
+			if err == nil {
+				err = SaveCheckpoint(cfg.CheckpointFile, cp)
+			}
+			if err != nil {
+				log.Logger.Warnw("failed to save checkpoint", "file", cfg.CheckpointFile, "error", err)
+			}
+			pl.bufferedSeekInfo = nil
+		}
 		return copied, nil
 	}
 
@@ -146,16 +178,38 @@ func newPoller(ctx context.Context, cfg query_log_config.Config, parseTime query
 	return pl, nil
 }
 
+// Returns the seek info to resume the file from the checkpoint,
+// or nil to start from the top of the file.
+func resumeSeekInfo(checkpointFile string, file string) (*tail.SeekInfo, error) {
+	cp, err := LoadCheckpoint(checkpointFile)
+	if err != nil {
+		return nil, err
+	}
+	seekInfo, err := cp.SeekInfo(file)
+	if err != nil {
+		return nil, err
+	}
+	log.Logger.Debugw("resuming from checkpoint", "file", file, "checkpoint", cp, "seekInfo", seekInfo)
+	return seekInfo, nil
+}
+
 func (pl *poller) pollSync(ctx context.Context) {
 	for line := range pl.tailLogger.Line() {
-		item := Item{
-			Time:    metav1.Time{Time: line.Time},
-			Line:    line.Text,
-			Matched: line.MatchedFilter,
-			Error:   line.Err,
+		item, ok, err := parseLine(pl.parser, line)
+		if err != nil {
+			log.Logger.Warnw("failed to parse log line", "line", line.Text, "error", err)
+		}
+
+		if ok && pl.cfg.LineObserver != nil {
+			pl.cfg.LineObserver(item.Line, item.Time.Time)
 		}
+
 		pl.bufferedItemsMu.Lock()
-		pl.bufferedItems = append(pl.bufferedItems, item)
+		if ok {
+			pl.bufferedItems = append(pl.bufferedItems, item)
+		}
+		seekInfo := line.SeekInfo
+		pl.bufferedSeekInfo = &seekInfo
 		pl.bufferedItemsMu.Unlock()
 
 		pl.tailFileSeekInfoMu.Lock()
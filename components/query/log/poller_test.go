@@ -3,6 +3,7 @@ package log
 import (
 	"context"
 	"os"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -17,14 +18,18 @@ import (
 func TestPoller(t *testing.T) {
 	t.Parallel()
 
+	observed := atomic.Int64{}
 	cfg := query_log_config.Config{
 		File: "tail/testdata/kubelet.0.log",
+		LineObserver: func(line string, _ time.Time) {
+			observed.Add(1)
+		},
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
 
-	poller, err := newPoller(ctx, cfg, nil)
+	poller, err := newPoller(ctx, cfg, nil, nil)
 	if err != nil {
 		t.Fatalf("failed to create log poller: %v", err)
 	}
@@ -57,6 +62,14 @@ func TestPoller(t *testing.T) {
 		t.Fatalf("expected 20 seek info sync, got %d", synced)
 	}
 
+	// each line is observed once, regardless of the queries
+	if _, err := poller.Find(time.Now().Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if n := observed.Load(); n != 20 {
+		t.Fatalf("expected 20 lines observed, got %d", n)
+	}
+
 	evs, err := poller.TailScan(ctx, query_log_tail.WithLinesToTail(1000))
 	if err != nil {
 		t.Fatalf("failed to tail: %v", err)
@@ -82,7 +95,7 @@ func TestPollerTail(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
 
-	poller, err := newPoller(ctx, cfg, nil)
+	poller, err := newPoller(ctx, cfg, nil, nil)
 	if err != nil {
 		t.Fatalf("failed to create log poller: %v", err)
 	}
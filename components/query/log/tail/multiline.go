@@ -0,0 +1,71 @@
+package tail
+
+import (
+	"time"
+
+	query_log_filter "github.com/leptonai/gpud/components/query/log/filter"
+
+	"github.com/nxadm/tail"
+)
+
+// The interval to check the incomplete multi-line block for the idle timeout
+// (see query_log_filter.MultiLine.IdleTimeout).
+const multiLineIdleCheckInterval = time.Second
+
+// lineAssembler assembles the streamed lines into the multi-line blocks,
+// for the select filters with the multi-line mode (see query_log_filter.MultiLine).
+// The lines of the single-line filters are passed through as is.
+type lineAssembler struct {
+	blocks query_log_filter.BlockAssembler[*tail.Line]
+}
+
+// Returns the lines to send for the next streamed line, in order.
+// The lines not included by the filters are still fed,
+// as they may be the following lines of a multi-line block.
+func (a *lineAssembler) feed(line *tail.Line, shouldInclude bool, matchedFilter *query_log_filter.Filter) []Line {
+	if !shouldInclude {
+		matchedFilter = nil
+	}
+	done, started := a.blocks.Feed(line.Text, line, matchedFilter)
+
+	lines := make([]Line, 0, 2)
+	if done != nil {
+		lines = append(lines, blockLine(done))
+	}
+	if shouldInclude && !started {
+		lines = append(lines, Line{Line: line, MatchedFilter: matchedFilter})
+	}
+	return lines
+}
+
+// Returns the pending multi-line block, if idle for its timeout.
+func (a *lineAssembler) flushIdle(now time.Time) []Line {
+	done := a.blocks.FlushIdle(now)
+	if done == nil {
+		return nil
+	}
+	return []Line{blockLine(done)}
+}
+
+// Returns the pending multi-line block, if any.
+func (a *lineAssembler) flush() []Line {
+	done := a.blocks.Flush()
+	if done == nil {
+		return nil
+	}
+	return []Line{blockLine(done)}
+}
+
+// Returns the block as a single line, timestamped with the start line
+// and with the seek info of the last line.
+func blockLine(b *query_log_filter.Block[*tail.Line]) Line {
+	return Line{
+		Line: &tail.Line{
+			Text:     b.Text(),
+			Num:      b.First.Num,
+			SeekInfo: b.Last.SeekInfo,
+			Time:     b.First.Time,
+		},
+		MatchedFilter: b.Filter,
+	}
+}
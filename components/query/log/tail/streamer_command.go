@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	query_log_filter "github.com/leptonai/gpud/components/query/log/filter"
@@ -56,10 +57,14 @@ func NewFromCommand(ctx context.Context, commands [][]string, opts ...OpOption)
 var _ Streamer = (*commandStreamer)(nil)
 
 type commandStreamer struct {
-	op    *Op
-	ctx   context.Context
-	proc  process.Process
-	lineC chan Line
+	op   *Op
+	ctx  context.Context
+	proc process.Process
+
+	// guards the line channel from the sends after closed
+	mu     sync.RWMutex
+	closed bool
+	lineC  chan Line
 }
 
 func (sr *commandStreamer) File() string {
@@ -76,47 +81,77 @@ func (sr *commandStreamer) Line() <-chan Line {
 
 func (sr *commandStreamer) pollLoops(scanner *bufio.Scanner) {
 	var (
-		s             string
 		ts            time.Time
 		err           error
 		shouldInclude bool
 		matchedFilter *query_log_filter.Filter
+		assembler     lineAssembler
 	)
 
-	for scanner.Scan() {
+	// reads the lines in the background, so that the incomplete multi-line block
+	// is flushed while waiting for the next line
+	textc := make(chan string)
+	go func() {
+		defer close(textc)
+		for scanner.Scan() {
+			select {
+			case <-sr.ctx.Done():
+				return
+			case textc <- scanner.Text():
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(multiLineIdleCheckInterval)
+	defer ticker.Stop()
+
+	for {
 		select {
 		case <-sr.ctx.Done():
 			return
-		default:
-		}
 
-		s = scanner.Text()
-		ts, err = sr.op.parseTime([]byte(s))
-		if err != nil {
-			log.Logger.Warnw("error parsing time", "error", err)
-			continue
-		}
-		if ts.IsZero() {
-			ts = time.Now().UTC()
+		case now := <-ticker.C:
+			sr.send(assembler.flushIdle(now))
+
+		case s, ok := <-textc:
+			if !ok {
+				// the output is closed, send the incomplete multi-line block
+				sr.send(assembler.flush())
+				return
+			}
+
+			ts, err = sr.op.parseTime([]byte(s))
+			if err != nil {
+				log.Logger.Warnw("error parsing time", "error", err)
+				continue
+			}
+			if ts.IsZero() {
+				ts = time.Now().UTC()
+			}
+
+			shouldInclude, matchedFilter, err = sr.op.applyFilter(s)
+			if err != nil {
+				log.Logger.Warnw("error applying filter", "error", err)
+				continue
+			}
+
+			sr.send(assembler.feed(&tail.Line{Text: s, Time: ts}, shouldInclude, matchedFilter))
 		}
+	}
+}
 
-		shouldInclude, matchedFilter, err = sr.op.applyFilter(s)
-		if err != nil {
-			log.Logger.Warnw("error applying filter", "error", err)
-			continue
-		}
-		if !shouldInclude {
-			continue
-		}
+// Sends the lines without blocking, dropping the lines
+// if the channel is full or already closed.
+func (sr *commandStreamer) send(lines []Line) {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
 
+	for _, l := range lines {
+		if sr.closed {
+			return
+		}
 		select {
-		case sr.lineC <- Line{
-			Line: &tail.Line{
-				Text: s,
-				Time: ts,
-			},
-			MatchedFilter: matchedFilter,
-		}:
+		case sr.lineC <- l:
 		default:
 			log.Logger.Debugw("channel is full -- dropped output", "pid", sr.proc.PID())
 		}
@@ -124,7 +159,12 @@ func (sr *commandStreamer) pollLoops(scanner *bufio.Scanner) {
 }
 
 func (sr *commandStreamer) waitCommand() {
-	defer close(sr.lineC)
+	defer func() {
+		sr.mu.Lock()
+		sr.closed = true
+		close(sr.lineC)
+		sr.mu.Unlock()
+	}()
 	select {
 	case <-sr.ctx.Done():
 	case <-sr.proc.Wait():
@@ -66,23 +66,43 @@ func (sr *fileStreamer) Line() <-chan Line {
 }
 
 func (sr *fileStreamer) pollLoops() {
-	for line := range sr.file.Lines {
-		shouldInclude, matchedFilter, err := sr.op.applyFilter(line.Text)
-		if err != nil {
-			log.Logger.Warnw("error applying filter", "error", err)
-			continue
-		}
-		if !shouldInclude {
-			continue
-		}
-
-		if line.Time.IsZero() {
-			line.Time = time.Now().UTC()
+	var assembler lineAssembler
+
+	// flushes the incomplete multi-line block while waiting for the next line
+	ticker := time.NewTicker(multiLineIdleCheckInterval)
+	defer ticker.Stop()
+
+loop:
+	for {
+		select {
+		case now := <-ticker.C:
+			for _, l := range assembler.flushIdle(now) {
+				sr.lineC <- l
+			}
+
+		case line, ok := <-sr.file.Lines:
+			if !ok {
+				break loop
+			}
+
+			shouldInclude, matchedFilter, err := sr.op.applyFilter(line.Text)
+			if err != nil {
+				log.Logger.Warnw("error applying filter", "error", err)
+				continue
+			}
+
+			if line.Time.IsZero() {
+				line.Time = time.Now().UTC()
+			}
+
+			for _, l := range assembler.feed(line, shouldInclude, matchedFilter) {
+				sr.lineC <- l
+			}
 		}
+	}
 
-		sr.lineC <- Line{
-			Line:          line,
-			MatchedFilter: matchedFilter,
-		}
+	// the tail is stopped, send the incomplete multi-line block
+	for _, l := range assembler.flush() {
+		sr.lineC <- l
 	}
 }
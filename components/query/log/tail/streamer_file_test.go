@@ -3,8 +3,14 @@ package tail
 import (
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 	"time"
+
+	query_log_filter "github.com/leptonai/gpud/components/query/log/filter"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 )
 
 func TestFileStreamer(t *testing.T) {
@@ -38,3 +44,97 @@ func TestFileStreamer(t *testing.T) {
 		}
 	}
 }
+
+func TestFileStreamerMultiLine(t *testing.T) {
+	streamer, err := NewFromFile(
+		"testdata/dmesg.oom.log",
+		nil,
+		WithSelectFilter(
+			&query_log_filter.Filter{
+				Name:  "oom_killer",
+				Regex: ptr.To(`(?i)\b(invoked|triggered) oom-killer\b`),
+				MultiLine: &query_log_filter.MultiLine{
+					EndRegex: ptr.To(`oom-kill:constraint=`),
+				},
+			},
+			&query_log_filter.Filter{
+				Name:  "oom_kill",
+				Regex: ptr.To(`Out of memory:`),
+			},
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := readFileToLines(t, "testdata/dmesg.oom.log")
+	expected := []struct {
+		filter string
+		text   string
+	}{
+		{filter: "oom_killer", text: strings.Join(lines[1:14], "\n")},
+		{filter: "oom_kill", text: lines[14]},
+	}
+	for _, exp := range expected {
+		select {
+		case line := <-streamer.Line():
+			if line.MatchedFilter == nil || line.MatchedFilter.Name != exp.filter {
+				t.Fatalf("expected filter %q, got %+v", exp.filter, line.MatchedFilter)
+			}
+			if line.Text != exp.text {
+				t.Fatalf("expected:\n%s\ngot:\n%s", exp.text, line.Text)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout")
+		}
+	}
+}
+
+func TestFileStreamerMultiLineIdleFlush(t *testing.T) {
+	tmpf, err := os.CreateTemp("", "test*.txt")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpf.Name())
+
+	// the older kernels never print the end line
+	lines := []string{
+		"python invoked oom-killer: gfp_mask=0x140cca(GFP_HIGHUSER_MOVABLE|__GFP_COMP), order=0, oom_score_adj=0",
+		"CPU: 3 PID: 123 Comm: python Not tainted 4.15.0-213-generic #224-Ubuntu",
+		"Call Trace:",
+	}
+	if _, err := tmpf.WriteString(strings.Join(lines, "\n") + "\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	streamer, err := NewFromFile(
+		tmpf.Name(),
+		nil,
+		WithSelectFilter(
+			&query_log_filter.Filter{
+				Name:  "oom_killer",
+				Regex: ptr.To(`(?i)\b(invoked|triggered) oom-killer\b`),
+				MultiLine: &query_log_filter.MultiLine{
+					EndRegex:    ptr.To(`oom-kill:constraint=`),
+					IdleTimeout: metav1.Duration{Duration: 200 * time.Millisecond},
+				},
+			},
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the followed file never ends, so the block is only flushed when idle
+	select {
+	case line := <-streamer.Line():
+		if line.MatchedFilter == nil || line.MatchedFilter.Name != "oom_killer" {
+			t.Fatalf("expected filter %q, got %+v", "oom_killer", line.MatchedFilter)
+		}
+		if line.Text != strings.Join(lines, "\n") {
+			t.Fatalf("expected:\n%s\ngot:\n%s", strings.Join(lines, "\n"), line.Text)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the idle flush")
+	}
+}
@@ -2,7 +2,10 @@ package query
 
 import (
 	"context"
+	"errors"
+	"math/rand/v2"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	query_config "github.com/leptonai/gpud/components/query/config"
@@ -39,8 +42,40 @@ type Poller interface {
 	// All returns all results.
 	// Useful for constructing the events.
 	All(since time.Time) ([]Item, error)
+	// History returns up to the n most recent successful results
+	// (without errors), newest first.
+	// Only the results in memory are returned, so the retention is
+	// bounded by the queue size (see query_config.Config.QueueSize).
+	// Useful for computing trends (e.g., rate of change).
+	History(n int) ([]Item, error)
+
+	// ForcePoll runs the get immediately, rather than waiting for the next tick,
+	// and stores the result as a normal tick would (reflected in Last).
+	// Waits for the in-flight get, if any, to avoid running concurrently.
+	// Returns ErrPollerNotStarted if the poller is not started.
+	ForcePoll(ctx context.Context) (Item, error)
+
+	// Drain stops the new polls (the scheduled ticks and ForcePoll),
+	// and waits for the in-flight get, if any, to finish,
+	// so that the shutdown does not interrupt a half-done get.
+	// Returns ctx.Err() if the context is done before the in-flight get finishes.
+	// The poller still needs to be stopped (see Stop).
+	// Only meant for the shutdown: on a poller shared by multiple components,
+	// the polls stop for all of them until the draining component calls Stop
+	// (or another component calls Start), which resumes the polls.
+	Drain(ctx context.Context) error
+
+	// DroppedTicks returns the number of poll ticks skipped
+	// because the previous get was still running
+	// (see query_config.OverlapPolicy).
+	DroppedTicks() uint64
 }
 
+var (
+	ErrPollerNotStarted = errors.New("poller not started")
+	ErrPollerDraining   = errors.New("poller draining")
+)
+
 // Item is the basic unit of data that poller returns.
 // If enabled, each result is persisted in the storage.
 type Item struct {
@@ -58,14 +93,15 @@ type Item struct {
 type GetFunc func(context.Context) (any, error)
 
 func New(id string, cfg query_config.Config, getFunc GetFunc) Poller {
-	return &poller{
+	pl := &poller{
 		id:                 id,
 		tableName:          GetTableName(id),
-		startPollFunc:      startPoll,
 		getFunc:            getFunc,
 		cfg:                cfg,
 		inflightComponents: make(map[string]any),
 	}
+	pl.startPollFunc = pl.startPoll
+	return pl
 }
 
 var _ Poller = (*poller)(nil)
@@ -77,6 +113,13 @@ type poller struct {
 	startPollFunc startPollFunc
 	getFunc       GetFunc
 
+	// guards the in-flight get calls
+	getMu        sync.Mutex
+	droppedTicks atomic.Uint64
+	// set to true once drained, to skip the new polls
+	// reset on Start and Stop, in case the poller is shared
+	draining atomic.Bool
+
 	ctxMu  sync.RWMutex
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -92,15 +135,18 @@ type poller struct {
 
 type startPollFunc func(ctx context.Context, id string, interval time.Duration, get GetFunc) <-chan Item
 
-func startPoll(ctx context.Context, id string, interval time.Duration, get GetFunc) <-chan Item {
+func (pl *poller) startPoll(ctx context.Context, id string, interval time.Duration, get GetFunc) <-chan Item {
 	ch := make(chan Item, 1)
-	go pollLoops(ctx, id, ch, interval, get)
+	go pl.pollLoops(ctx, id, ch, interval, get)
 	return ch
 }
 
-func pollLoops(ctx context.Context, id string, ch chan<- Item, interval time.Duration, get GetFunc) {
+func (pl *poller) pollLoops(ctx context.Context, id string, ch chan<- Item, interval time.Duration, get GetFunc) {
+	jitter := pl.Config().Jitter
+
 	// to get output very first time and start wait
-	ticker := time.NewTicker(1)
+	// (delayed by the jitter, if any, to spread the pollers started together)
+	ticker := time.NewTicker(1 + jitterDuration(interval, jitter))
 	defer ticker.Stop()
 	for {
 		select {
@@ -116,12 +162,38 @@ func pollLoops(ctx context.Context, id string, ch chan<- Item, interval time.Dur
 			return
 
 		case <-ticker.C:
-			ticker.Reset(interval)
+			ticker.Reset(interval + jitterDuration(interval, jitter))
 		}
 
-		log.Logger.Debugw("polling", "id", id)
+		if pl.draining.Load() {
+			log.Logger.Debugw("poller draining -- skipping this tick", "id", id)
+			continue
+		}
 
+		queue := pl.Config().OverlapPolicy == query_config.OverlapPolicyQueue
+		if queue {
+			pl.getMu.Lock()
+		} else if !pl.getMu.TryLock() {
+			pl.droppedTicks.Add(1)
+			log.Logger.Debugw("previous get still running -- skipping this tick", "id", id)
+			continue
+		}
+
+		log.Logger.Debugw("polling", "id", id)
 		output, err := get(ctx)
+		pl.getMu.Unlock()
+
+		if !queue {
+			// the ticks that fired while the get was running are stale
+			select {
+			case <-ticker.C:
+				pl.droppedTicks.Add(1)
+				log.Logger.Debugw("get took longer than the interval -- skipping the missed tick", "id", id)
+				ticker.Reset(interval + jitterDuration(interval, jitter))
+			default:
+			}
+		}
+
 		if err != nil {
 			log.Logger.Debugw("polling error", "id", id, "error", err)
 			select {
@@ -155,6 +227,18 @@ func pollLoops(ctx context.Context, id string, ch chan<- Item, interval time.Dur
 	}
 }
 
+// Returns a random duration in [0, interval*jitter),
+// with the jitter clamped to [0, 1].
+func jitterDuration(interval time.Duration, jitter float64) time.Duration {
+	if jitter <= 0 || interval <= 0 {
+		return 0
+	}
+	if jitter > 1 {
+		jitter = 1
+	}
+	return time.Duration(rand.Float64() * jitter * float64(interval))
+}
+
 func (pl *poller) ID() string {
 	return pl.id
 }
@@ -176,9 +260,12 @@ func (pl *poller) Start(ctx context.Context, cfg query_config.Config, componentN
 	pl.ctxMu.Lock()
 	defer pl.ctxMu.Unlock()
 
+	pl.cfgMu.Lock()
 	pl.cfg = cfg
+	pl.cfgMu.Unlock()
 
 	pl.inflightComponents[componentName] = struct{}{}
+	pl.draining.Store(false)
 	started := pl.ctx != nil
 	if started {
 		return
@@ -212,6 +299,10 @@ func (pl *poller) Stop(componentName string) bool {
 	}
 	delete(pl.inflightComponents, componentName)
 
+	// the draining component is done, so resume the polls
+	// for the other components sharing this poller (if any)
+	pl.draining.Store(false)
+
 	// do not cancel if there's any inflight component "after" this
 	if len(pl.inflightComponents) > 0 {
 		log.Logger.Debugw("skipping stopping the underlying poller -- inflights >0", "inflightComponents", len(pl.inflightComponents))
@@ -277,3 +368,75 @@ func (pl *poller) All(since time.Time) ([]Item, error) {
 	}
 	return items, nil
 }
+
+func (pl *poller) History(n int) ([]Item, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	pl.lastItemsMu.RLock()
+	defer pl.lastItemsMu.RUnlock()
+
+	items := make([]Item, 0, n)
+	for i := len(pl.lastItems) - 1; i >= 0 && len(items) < n; i-- {
+		if pl.lastItems[i].Error != nil {
+			continue
+		}
+		items = append(items, pl.lastItems[i])
+	}
+	return items, nil
+}
+
+func (pl *poller) ForcePoll(ctx context.Context) (Item, error) {
+	pl.ctxMu.RLock()
+	started := pl.ctx != nil
+	pl.ctxMu.RUnlock()
+	if !started {
+		return Item{}, ErrPollerNotStarted
+	}
+	if pl.draining.Load() {
+		return Item{}, ErrPollerDraining
+	}
+
+	// same in-flight guard as the scheduled ticks
+	pl.getMu.Lock()
+	output, err := pl.getFunc(ctx)
+	pl.getMu.Unlock()
+
+	item := Item{
+		Time:   metav1.Time{Time: time.Now().UTC()},
+		Output: output,
+		Error:  err,
+	}
+
+	// maybe no state at the time
+	if err == nil && output == nil {
+		return item, nil
+	}
+
+	pl.processItem(item)
+	return item, err
+}
+
+func (pl *poller) Drain(ctx context.Context) error {
+	pl.draining.Store(true)
+
+	done := make(chan struct{})
+	go func() {
+		// acquired once the in-flight get, if any, finishes
+		pl.getMu.Lock()
+		pl.getMu.Unlock()
+		close(done)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-done:
+		return nil
+	}
+}
+
+func (pl *poller) DroppedTicks() uint64 {
+	return pl.droppedTicks.Load()
+}
@@ -2,7 +2,9 @@ package query
 
 import (
 	"context"
+	"errors"
 	"reflect"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -161,3 +163,243 @@ func TestPollerStartStop(t *testing.T) {
 		t.Errorf("expected startFunc to be called 1 time, got %d", startFuncCalled)
 	}
 }
+
+func TestPollerOverlapPolicy(t *testing.T) {
+	t.Parallel()
+
+	for _, policy := range []query_config.OverlapPolicy{query_config.OverlapPolicySkipIfRunning, query_config.OverlapPolicyQueue} {
+		t.Run(string(policy), func(t *testing.T) {
+			t.Parallel()
+
+			calls := atomic.Int64{}
+			cfg := query_config.Config{
+				Interval:      metav1.Duration{Duration: 20 * time.Millisecond},
+				QueueSize:     100,
+				OverlapPolicy: policy,
+			}
+			pl := New("test-"+string(policy), cfg, func(ctx context.Context) (any, error) {
+				calls.Add(1)
+				time.Sleep(70 * time.Millisecond)
+				return "ok", nil
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			pl.Start(ctx, cfg, "test")
+			defer pl.Stop("test")
+
+			time.Sleep(500 * time.Millisecond)
+
+			if calls.Load() == 0 {
+				t.Fatal("expected get to be called")
+			}
+			switch policy {
+			case query_config.OverlapPolicySkipIfRunning:
+				if pl.DroppedTicks() == 0 {
+					t.Fatal("expected dropped ticks")
+				}
+			case query_config.OverlapPolicyQueue:
+				if pl.DroppedTicks() != 0 {
+					t.Fatalf("expected no dropped ticks, got %d", pl.DroppedTicks())
+				}
+			}
+		})
+	}
+}
+
+func TestJitterDuration(t *testing.T) {
+	t.Parallel()
+
+	interval := time.Minute
+	if d := jitterDuration(interval, 0); d != 0 {
+		t.Fatalf("expected no jitter, got %v", d)
+	}
+	if d := jitterDuration(interval, -0.5); d != 0 {
+		t.Fatalf("expected no jitter for negative fraction, got %v", d)
+	}
+	for i := 0; i < 100; i++ {
+		if d := jitterDuration(interval, 0.2); d < 0 || d >= 12*time.Second {
+			t.Fatalf("expected jitter in [0, 12s), got %v", d)
+		}
+		if d := jitterDuration(interval, 5); d < 0 || d >= interval {
+			t.Fatalf("expected clamped jitter in [0, 1m), got %v", d)
+		}
+	}
+}
+
+func TestPollerHistory(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	q := &poller{
+		lastItems: []Item{
+			{Time: metav1.NewTime(now.Add(-4 * time.Second)), Output: 1},
+			{Time: metav1.NewTime(now.Add(-3 * time.Second)), Output: 2},
+			{Time: metav1.NewTime(now.Add(-2 * time.Second)), Error: errors.New("failed")},
+			{Time: metav1.NewTime(now.Add(-1 * time.Second)), Output: 3},
+		},
+	}
+
+	items, err := q.History(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].Output != 3 || items[1].Output != 2 {
+		t.Fatalf("unexpected history %+v", items)
+	}
+
+	items, err = q.History(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 3 || items[2].Output != 1 {
+		t.Fatalf("unexpected history %+v", items)
+	}
+
+	items, err = q.History(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 0 {
+		t.Fatalf("expected no history, got %+v", items)
+	}
+}
+
+func TestPollerForcePoll(t *testing.T) {
+	t.Parallel()
+
+	calls := atomic.Int64{}
+	cfg := query_config.Config{
+		Interval:  metav1.Duration{Duration: time.Hour},
+		QueueSize: 10,
+	}
+	pl := New("test-force-poll", cfg, func(ctx context.Context) (any, error) {
+		return calls.Add(1), nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if _, err := pl.ForcePoll(ctx); !errors.Is(err, ErrPollerNotStarted) {
+		t.Fatalf("expected ErrPollerNotStarted, got %v", err)
+	}
+
+	pl.Start(ctx, cfg, "test")
+	defer pl.Stop("test")
+
+	// wait for the very first tick
+	time.Sleep(100 * time.Millisecond)
+
+	item, err := pl.ForcePoll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Output != int64(2) {
+		t.Fatalf("expected output 2, got %v", item.Output)
+	}
+
+	last, err := pl.Last()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last == nil || last.Output != int64(2) {
+		t.Fatalf("expected last to reflect the forced result, got %+v", last)
+	}
+}
+
+func TestPollerDrain(t *testing.T) {
+	t.Parallel()
+
+	calls := atomic.Int64{}
+	finished := atomic.Bool{}
+	started := make(chan struct{}, 1)
+	cfg := query_config.Config{
+		Interval:  metav1.Duration{Duration: time.Hour},
+		QueueSize: 10,
+	}
+	pl := New("test-drain", cfg, func(ctx context.Context) (any, error) {
+		n := calls.Add(1)
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		// slow get
+		time.Sleep(500 * time.Millisecond)
+		finished.Store(true)
+		return n, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pl.Start(ctx, cfg, "test")
+	defer pl.Stop("test")
+
+	// wait for the very first tick to be in-flight
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the first poll")
+	}
+
+	// the in-flight get outlives the deadline
+	drainCtx, drainCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	err := pl.Drain(drainCtx)
+	drainCancel()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	// waits for the in-flight get to finish
+	drainCtx, drainCancel = context.WithTimeout(ctx, 5*time.Second)
+	err = pl.Drain(drainCtx)
+	drainCancel()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !finished.Load() {
+		t.Fatal("expected the in-flight get to finish before the drain returns")
+	}
+
+	if _, err := pl.ForcePoll(ctx); !errors.Is(err, ErrPollerDraining) {
+		t.Fatalf("expected %v, got %v", ErrPollerDraining, err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected no new polls once drained, got %d calls", n)
+	}
+}
+
+func TestPollerDrainShared(t *testing.T) {
+	t.Parallel()
+
+	calls := atomic.Int64{}
+	cfg := query_config.Config{
+		Interval:  metav1.Duration{Duration: time.Hour},
+		QueueSize: 10,
+	}
+	pl := New("test-drain-shared", cfg, func(ctx context.Context) (any, error) {
+		return calls.Add(1), nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pl.Start(ctx, cfg, "a")
+	pl.Start(ctx, cfg, "b")
+	defer pl.Stop("b")
+
+	if err := pl.Drain(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pl.ForcePoll(ctx); !errors.Is(err, ErrPollerDraining) {
+		t.Fatalf("expected %v, got %v", ErrPollerDraining, err)
+	}
+
+	// the other component still polls once the draining one stops
+	if pl.Stop("a") {
+		t.Fatal("expected the shared poller not stopped")
+	}
+	if _, err := pl.ForcePoll(ctx); err != nil {
+		t.Fatalf("expected the polls resumed, got %v", err)
+	}
+}
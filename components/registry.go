@@ -0,0 +1,168 @@
+package components
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/leptonai/gpud/errdefs"
+)
+
+// Defines an optional component interface that starts its background routines
+// (e.g., pollers) separately from the construction.
+type Starter interface {
+	Start(ctx context.Context) error
+}
+
+// Registry tracks the lifecycle of a set of components,
+// so that a single call starts or shuts down all of them
+// without leaking any poller.
+type Registry struct {
+	mu      sync.RWMutex
+	entries []*registryEntry
+	closed  bool
+}
+
+type registryEntry struct {
+	comp    Component
+	started bool
+	closed  bool
+}
+
+// Returns a new empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Registers the component, whose name must be unique in the registry.
+// Returns an error if the registry has already been closed.
+func (r *Registry) Register(c Component) error {
+	if c == nil {
+		return fmt.Errorf("nil component: %w", errdefs.ErrInvalidArgument)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return fmt.Errorf("registry closed: %w", errdefs.ErrUnavailable)
+	}
+	for _, e := range r.entries {
+		if e.comp.Name() == c.Name() {
+			return fmt.Errorf("component %s already registered: %w", c.Name(), errdefs.ErrAlreadyExists)
+		}
+	}
+	r.entries = append(r.entries, &registryEntry{comp: c})
+	return nil
+}
+
+// Starts all the registered components that implement Starter
+// and have not been started yet, in the registration order.
+// A failing component does not stop the remaining ones from starting,
+// and the errors are joined into the returned error.
+func (r *Registry) StartAll(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return fmt.Errorf("registry closed: %w", errdefs.ErrUnavailable)
+	}
+
+	var errs []error
+	for _, e := range r.entries {
+		if e.started {
+			continue
+		}
+		if s, ok := e.comp.(Starter); ok {
+			if err := s.Start(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("component %s: %w", e.comp.Name(), err))
+				continue
+			}
+		}
+		e.started = true
+	}
+	return errors.Join(errs...)
+}
+
+// Closes all the registered components in the reverse registration order,
+// and returns the errors from each component's Close joined.
+// Each component is closed at most once, and the registry
+// does not accept new components once closed.
+// Safe to call multiple times.
+func (r *Registry) CloseAll() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.closeAll()
+}
+
+// Drains all the registered components that implement Drainer concurrently,
+// waiting for their in-flight polls to finish until the context is done,
+// and then closes all the components as CloseAll does.
+// Returns the names of the components that did not drain in time
+// (in the registration order), and the drain and close errors joined.
+// The components are closed even if they did not drain in time.
+func (r *Registry) DrainAndClose(ctx context.Context) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// stop accepting new components while draining
+	r.closed = true
+
+	drainErrs := make([]error, len(r.entries))
+	var wg sync.WaitGroup
+	for i, e := range r.entries {
+		if e.closed {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			drainErrs[i] = Drain(ctx, e.comp)
+		}()
+	}
+	wg.Wait()
+
+	var undrained []string
+	var errs []error
+	for i, err := range drainErrs {
+		if err == nil {
+			continue
+		}
+		name := r.entries[i].comp.Name()
+		undrained = append(undrained, name)
+		errs = append(errs, fmt.Errorf("component %s: drain: %w", name, err))
+	}
+	errs = append(errs, r.closeAll())
+	return undrained, errors.Join(errs...)
+}
+
+func (r *Registry) closeAll() error {
+	r.closed = true
+
+	var errs []error
+	for i := len(r.entries) - 1; i >= 0; i-- {
+		e := r.entries[i]
+		if e.closed {
+			continue
+		}
+		e.closed = true
+		if err := e.comp.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("component %s: %w", e.comp.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Returns the registered components in the registration order.
+func (r *Registry) List() []Component {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	comps := make([]Component, 0, len(r.entries))
+	for _, e := range r.entries {
+		comps = append(comps, e.comp)
+	}
+	return comps
+}
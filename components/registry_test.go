@@ -0,0 +1,143 @@
+package components
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/leptonai/gpud/errdefs"
+)
+
+type lifecycleComponent struct {
+	mockComponent
+	startErr error
+	closeErr error
+	starts   int
+	closes   int
+	closed   *[]string
+}
+
+func (c *lifecycleComponent) Start(ctx context.Context) error {
+	c.starts++
+	return c.startErr
+}
+
+func (c *lifecycleComponent) Close() error {
+	c.closes++
+	*c.closed = append(*c.closed, c.name)
+	return c.closeErr
+}
+
+type drainingComponent struct {
+	lifecycleComponent
+	// the in-flight poll duration
+	inflight time.Duration
+}
+
+func (c *drainingComponent) Drain(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(c.inflight):
+		return nil
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	var closed []string
+	errStart := errors.New("start failed")
+	errClose := errors.New("close failed")
+	a := &lifecycleComponent{mockComponent: mockComponent{name: "a"}, closed: &closed}
+	b := &lifecycleComponent{mockComponent: mockComponent{name: "b"}, startErr: errStart, closeErr: errClose, closed: &closed}
+	c := &mockComponent{name: "c"}
+
+	r := NewRegistry()
+	for _, comp := range []Component{a, b, c} {
+		if err := r.Register(comp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Register(&mockComponent{name: "a"}); !errors.Is(err, errdefs.ErrAlreadyExists) {
+		t.Fatalf("expected %v, got %v", errdefs.ErrAlreadyExists, err)
+	}
+
+	list := r.List()
+	if len(list) != 3 || list[0].Name() != "a" || list[1].Name() != "b" || list[2].Name() != "c" {
+		t.Fatalf("unexpected list: %+v", list)
+	}
+
+	if err := r.StartAll(context.Background()); !errors.Is(err, errStart) {
+		t.Fatalf("expected %v, got %v", errStart, err)
+	}
+	// the started components are not started again, the failed ones are retried
+	if err := r.StartAll(context.Background()); !errors.Is(err, errStart) {
+		t.Fatalf("expected %v, got %v", errStart, err)
+	}
+	if a.starts != 1 || b.starts != 2 {
+		t.Fatalf("unexpected starts: a %d, b %d", a.starts, b.starts)
+	}
+
+	if err := r.CloseAll(); !errors.Is(err, errClose) {
+		t.Fatalf("expected %v, got %v", errClose, err)
+	}
+	if err := r.CloseAll(); err != nil {
+		t.Fatalf("expected no error on the second close, got %v", err)
+	}
+	if a.closes != 1 || b.closes != 1 {
+		t.Fatalf("unexpected closes: a %d, b %d", a.closes, b.closes)
+	}
+	if len(closed) != 2 || closed[0] != "b" || closed[1] != "a" {
+		t.Fatalf("expected reverse close order, got %v", closed)
+	}
+
+	if err := r.Register(&mockComponent{name: "d"}); !errors.Is(err, errdefs.ErrUnavailable) {
+		t.Fatalf("expected %v, got %v", errdefs.ErrUnavailable, err)
+	}
+	if err := r.StartAll(context.Background()); !errors.Is(err, errdefs.ErrUnavailable) {
+		t.Fatalf("expected %v, got %v", errdefs.ErrUnavailable, err)
+	}
+}
+
+func TestRegistryDrainAndClose(t *testing.T) {
+	t.Parallel()
+
+	var closed []string
+	fast := &drainingComponent{lifecycleComponent: lifecycleComponent{mockComponent: mockComponent{name: "fast"}, closed: &closed}, inflight: 10 * time.Millisecond}
+	slow := &drainingComponent{lifecycleComponent: lifecycleComponent{mockComponent: mockComponent{name: "slow"}, closed: &closed}, inflight: time.Hour}
+	plain := &lifecycleComponent{mockComponent: mockComponent{name: "plain"}, closed: &closed}
+
+	r := NewRegistry()
+	for _, comp := range []Component{fast, slow, plain} {
+		if err := r.Register(comp); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	undrained, err := r.DrainAndClose(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+	if len(undrained) != 1 || undrained[0] != "slow" {
+		t.Fatalf("expected only the slow component undrained, got %v", undrained)
+	}
+
+	// closed even if not drained in time
+	if len(closed) != 3 || closed[0] != "plain" || closed[1] != "slow" || closed[2] != "fast" {
+		t.Fatalf("expected reverse close order, got %v", closed)
+	}
+	if err := r.Register(&mockComponent{name: "d"}); !errors.Is(err, errdefs.ErrUnavailable) {
+		t.Fatalf("expected %v, got %v", errdefs.ErrUnavailable, err)
+	}
+
+	// already closed, so nothing to drain or close
+	undrained, err = r.DrainAndClose(context.Background())
+	if err != nil || len(undrained) != 0 {
+		t.Fatalf("expected no-op on the second call, got %v, %v", undrained, err)
+	}
+}
@@ -0,0 +1,45 @@
+package components
+
+import (
+	"strings"
+	"text/tabwriter"
+)
+
+// The max number of the characters of the reason column in FormatStatesTable,
+// longer reasons are truncated with "...".
+const StatesTableMaxReasonWidth = 80
+
+// Renders the states as a human-readable table with the name, status, and reason
+// columns aligned, for the CLI output (e.g., "gpud status").
+// The status is the tri-state health status (see State.HealthStatus).
+// The reasons longer than StatesTableMaxReasonWidth are truncated,
+// and the line breaks in the reasons are replaced with spaces.
+// Returns only the header for the empty states.
+func FormatStatesTable(states []State) string {
+	buf := new(strings.Builder)
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+
+	_, _ = w.Write([]byte("NAME\tSTATUS\tREASON\n"))
+	for _, s := range states {
+		_, _ = w.Write([]byte(s.Name + "\t" + string(s.HealthStatus()) + "\t" + truncateReason(s.Reason, StatesTableMaxReasonWidth) + "\n"))
+	}
+	_ = w.Flush()
+
+	// no trailing padding for the empty reasons
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// Returns the reason in a single line, truncated to the max characters.
+func truncateReason(reason string, width int) string {
+	reason = strings.Join(strings.Fields(reason), " ")
+
+	runes := []rune(reason)
+	if len(runes) <= width {
+		return reason
+	}
+	return string(runes[:width-3]) + "..."
+}
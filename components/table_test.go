@@ -0,0 +1,71 @@
+package components
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update-golden", false, "update the golden files in testdata")
+
+func TestFormatStatesTable(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		states []State
+		golden string
+	}{
+		{
+			name:   "empty",
+			states: nil,
+			golden: "states_table_empty.golden",
+		},
+		{
+			name: "mixed",
+			states: []State{
+				{Name: "ecc_errors", Healthy: true, Status: StatusHealthy},
+				{Name: "ecc_errors_gpu_0", Healthy: true, Status: StatusDegraded, Reason: "gpu 0 (GPU-0) has 0 volatile uncorrected errors, 3 volatile corrected errors"},
+				{Name: "fabric_manager", Healthy: false, Reason: "fabric manager not running\n(failed to connect)"},
+				{Name: "dmesg", Healthy: false, Status: StatusUnhealthy, Reason: strings.Repeat("NVRM: Xid (PCI:0000:1b:00): 79, GPU has fallen off the bus. ", 3)},
+			},
+			golden: "states_table_mixed.golden",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FormatStatesTable(tt.states)
+
+			path := filepath.Join("testdata", tt.golden)
+			if *updateGolden {
+				if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != string(want) {
+				t.Fatalf("unexpected table (run with -update-golden to update)\nexpected:\n%s\ngot:\n%s", want, got)
+			}
+		})
+	}
+}
+
+func TestTruncateReason(t *testing.T) {
+	t.Parallel()
+
+	if got := truncateReason("short", 10); got != "short" {
+		t.Fatalf("expected %q, got %q", "short", got)
+	}
+	if got := truncateReason("0123456789abc", 10); got != "0123456..." {
+		t.Fatalf("expected %q, got %q", "0123456...", got)
+	}
+	// truncates by characters, not bytes
+	if got := truncateReason("가나다라마바사아자차카", 5); got != "가나..." {
+		t.Fatalf("expected %q, got %q", "가나...", got)
+	}
+}
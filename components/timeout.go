@@ -0,0 +1,61 @@
+package components
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrFetchTimeout is returned by FetchWithTimeout when the context is done
+// (e.g., canceled or the deadline exceeded) before the fetch returns.
+var ErrFetchTimeout = errors.New("fetch did not complete in time")
+
+// Runs the fetch with the timeout (no timeout if zero), and returns
+// ErrFetchTimeout as soon as the context is done, without waiting for
+// the fetch to return, so the caller (e.g., States) honors the context
+// cancellation promptly even if the underlying data fetch blocks.
+// The fetch keeps running in the background until it returns.
+func FetchWithTimeout[T any](ctx context.Context, timeout time.Duration, fetch func() (T, error)) (T, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	if err := ctx.Err(); err != nil {
+		var zero T
+		return zero, fmt.Errorf("%w: %w", ErrFetchTimeout, err)
+	}
+
+	type result struct {
+		v   T
+		err error
+	}
+	// buffered to not leak the goroutine when the context is done first
+	rc := make(chan result, 1)
+	go func() {
+		v, err := fetch()
+		rc <- result{v: v, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		var zero T
+		return zero, fmt.Errorf("%w: %w", ErrFetchTimeout, ctx.Err())
+	case r := <-rc:
+		return r.v, r.err
+	}
+}
+
+// Returns the unhealthy state of the component
+// whose data fetch did not complete in time.
+func TimeoutState(name string, err error) State {
+	return State{
+		Name:       name,
+		Healthy:    false,
+		Error:      err.Error(),
+		Reason:     "data fetch did not complete in time",
+		ReasonCode: ReasonTimeout,
+	}
+}
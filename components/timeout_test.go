@@ -0,0 +1,52 @@
+package components
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFetchWithTimeout(t *testing.T) {
+	t.Parallel()
+
+	v, err := FetchWithTimeout(context.Background(), time.Second, func() (int, error) {
+		return 1, nil
+	})
+	if err != nil || v != 1 {
+		t.Fatalf("FetchWithTimeout() = %d, %v, want 1, nil", v, err)
+	}
+
+	errFetch := errors.New("fetch failed")
+	if _, err := FetchWithTimeout(context.Background(), 0, func() (int, error) {
+		return 0, errFetch
+	}); !errors.Is(err, errFetch) {
+		t.Fatalf("FetchWithTimeout() error = %v, want %v", err, errFetch)
+	}
+
+	blockc := make(chan struct{})
+	defer close(blockc)
+	blocking := func() (int, error) {
+		<-blockc
+		return 1, nil
+	}
+
+	start := time.Now()
+	if _, err := FetchWithTimeout(context.Background(), 100*time.Millisecond, blocking); !errors.Is(err, ErrFetchTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("FetchWithTimeout() error = %v, want %v", err, ErrFetchTimeout)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("FetchWithTimeout() took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := FetchWithTimeout(ctx, time.Minute, blocking); !errors.Is(err, ErrFetchTimeout) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("FetchWithTimeout() error = %v, want %v", err, ErrFetchTimeout)
+	}
+
+	s := TimeoutState("test", ErrFetchTimeout)
+	if s.Healthy || s.ReasonCode != ReasonTimeout || s.Name != "test" {
+		t.Fatalf("unexpected timeout state: %+v", s)
+	}
+}
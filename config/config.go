@@ -41,6 +41,17 @@ type Config struct {
 
 	// Set false to disable auto update
 	EnableAutoUpdate bool `json:"enable_auto_update"`
+
+	// Configures the shared NVIDIA poller used by all NVIDIA components.
+	NVIDIA *NVIDIA `json:"nvidia,omitempty"`
+}
+
+// Configures the shared NVIDIA poller.
+type NVIDIA struct {
+	// Set true to populate the nvidia-smi output (e.g., driver version, ECC error counts)
+	// from NVML instead of running nvidia-smi
+	// (falls back to nvidia-smi if NVML is not available or fails).
+	PreferNVML bool `json:"prefer_nvml,omitempty"`
 }
 
 // Configures the local web configuration.
@@ -109,7 +109,7 @@ func DefaultConfig(ctx context.Context) (*Config, error) {
 
 		cctx, ccancel := context.WithTimeout(ctx, 5*time.Second)
 		defer ccancel()
-		if _, _, conn, err := containerd_pod.Connect(cctx, containerd_pod.DefaultContainerRuntimeEndpoint); err == nil {
+		if _, _, conn, err := containerd_pod.Connect(cctx, containerd_pod.DefaultContainerRuntimeEndpoint, containerd_pod.DefaultDialTimeout); err == nil {
 			log.Logger.Debugw("containerd default cri endpoint open, containerd running", "endpoint", containerd_pod.DefaultContainerRuntimeEndpoint)
 			containerdRunning = true
 			_ = conn.Close()
@@ -145,10 +145,10 @@ func DefaultConfig(ctx context.Context) (*Config, error) {
 				log.Logger.Debugw("auto-detected kubelet readonly port -- configuring k8s pod components", "port", k8s_pod.DefaultKubeletReadOnlyPort)
 
 				// "k8s_pod" requires kubelet read-only port
-				// assume if kubelet is running, it opens the most common read-only port 10255
+				// leave the endpoint empty to discover the kubelet endpoint
+				// (falls back to the most common read-only port 10255)
 				cfg.Components[k8s_pod.Name] = k8s_pod.Config{
 					Query: query_config.DefaultConfig(),
-					Port:  k8s_pod.DefaultKubeletReadOnlyPort,
 				}
 			}
 		}
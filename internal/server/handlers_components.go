@@ -35,6 +35,12 @@ func (g *globalHandler) registerComponentRoutes(r gin.IRoutes) []componentHandle
 		Desc: URLPathComponentsDesc,
 	})
 
+	r.GET(URLPathComponentsDescribe, g.getComponentsDescribe)
+	paths = append(paths, componentHandlerDescription{
+		Path: URLPathComponentsDescribe,
+		Desc: URLPathComponentsDescribeDesc,
+	})
+
 	r.GET(URLPathStates, g.getStates)
 	paths = append(paths, componentHandlerDescription{
 		Path: URLPathStates,
@@ -102,6 +108,48 @@ func (g *globalHandler) getComponents(c *gin.Context) {
 	}
 }
 
+const (
+	URLPathComponentsDescribe     = "/components/describe"
+	URLPathComponentsDescribeDesc = "Get the name, description, and tags of all components"
+)
+
+// getComponentsDescribe godoc
+// @Summary Fetch the descriptions of all components in gpud
+// @Description get gpud component names, descriptions, and tags
+// @ID getComponentsDescribe
+// @Produce  json
+// @Success 200 {object} []components.ComponentInfo
+// @Router /v1/components/describe [get]
+func (g *globalHandler) getComponentsDescribe(c *gin.Context) {
+	infos := make([]lep_components.ComponentInfo, 0, len(g.components))
+	for _, comp := range g.components {
+		infos = append(infos, lep_components.Describe(comp))
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+
+	switch c.GetHeader(RequestHeaderContentType) {
+	case RequestHeaderYAML:
+		yb, err := yaml.Marshal(infos)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusInternalServerError, "message": "failed to marshal components " + err.Error()})
+			return
+		}
+		c.String(http.StatusOK, string(yb))
+
+	case RequestHeaderJSON, "":
+		if c.GetHeader(RequestHeaderJSONIndent) == "true" {
+			c.IndentedJSON(http.StatusOK, infos)
+			return
+		}
+		c.JSON(http.StatusOK, infos)
+
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"code": errdefs.ErrInvalidArgument, "message": "invalid content type"})
+	}
+}
+
 const (
 	URLPathStates     = "/states"
 	URLPathStatesDesc = "Get the states of all gpud components"
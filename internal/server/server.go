@@ -11,7 +11,6 @@ import (
 	"database/sql"
 	"encoding/pem"
 	"fmt"
-	"io"
 	"math/big"
 	"net/http"
 	"net/http/pprof"
@@ -48,6 +47,7 @@ import (
 	nvidia_processes "github.com/leptonai/gpud/components/accelerator/nvidia/processes"
 	nvidia_query "github.com/leptonai/gpud/components/accelerator/nvidia/query"
 	nvidia_query_nvml "github.com/leptonai/gpud/components/accelerator/nvidia/query/nvml"
+	nvidia_query_sxid "github.com/leptonai/gpud/components/accelerator/nvidia/query/sxid"
 	nvidia_temperature "github.com/leptonai/gpud/components/accelerator/nvidia/temperature"
 	nvidia_utilization "github.com/leptonai/gpud/components/accelerator/nvidia/utilization"
 	containerd_pod "github.com/leptonai/gpud/components/containerd/pod"
@@ -86,6 +86,8 @@ type Server struct {
 	fifo                  *goOS.File
 	session               *session.Session
 	enableAutoUpdate      bool
+	// tracks the registered components to close them all on stop
+	registry *components.Registry
 }
 
 func New(ctx context.Context, config *lepconfig.Config, endpoint string) (_ *Server, retErr error) {
@@ -109,6 +111,7 @@ func New(ctx context.Context, config *lepconfig.Config, endpoint string) (_ *Ser
 		db:               db,
 		fifoPath:         fifoPath,
 		enableAutoUpdate: config.EnableAutoUpdate,
+		registry:         components.NewRegistry(),
 	}
 	defer func() {
 		if retErr != nil {
@@ -173,6 +176,11 @@ func New(ctx context.Context, config *lepconfig.Config, endpoint string) (_ *Ser
 		return nil, fmt.Errorf("dependency check failed: %w", err)
 	}
 
+	// set once before any NVIDIA component starts the shared poller
+	if config.NVIDIA != nil {
+		nvidia_query.SetDefaultPreferNVML(config.NVIDIA.PreferNVML)
+	}
+
 	allComponents := make([]components.Component, 0)
 	if _, ok := config.Components[os.Name]; !ok {
 		allComponents = append(allComponents, os.New(ctx, os.Config{Query: defaultQueryCfg}))
@@ -386,7 +394,18 @@ func New(ctx context.Context, config *lepconfig.Config, endpoint string) (_ *Ser
 			allComponents = append(allComponents, nvidia_error_xid.New(ctx, cfg))
 
 		case nvidia_error_sxid.Name:
-			allComponents = append(allComponents, nvidia_error_sxid.New())
+			cfg := nvidia_error_sxid.Config{}
+			if configValue != nil {
+				parsed, err := nvidia_error_sxid.ParseConfig(configValue, db)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse component %s config: %w", k, err)
+				}
+				cfg = *parsed
+			}
+			if err := cfg.Validate(); err != nil {
+				return nil, fmt.Errorf("failed to validate component %s config: %w", k, err)
+			}
+			allComponents = append(allComponents, nvidia_error_sxid.New(cfg))
 
 		case nvidia_clock.Name:
 			cfg := nvidia_clock.Config{Query: defaultQueryCfg}
@@ -733,6 +752,9 @@ func New(ctx context.Context, config *lepconfig.Config, endpoint string) (_ *Ser
 			log.Logger.Warnw("failed to register component", "name", c.Name(), "error", err)
 			continue
 		}
+		if err := s.registry.Register(c); err != nil {
+			log.Logger.Warnw("failed to register component lifecycle", "name", c.Name(), "error", err)
+		}
 
 		if orig, ok := c.(interface{ Unwrap() interface{} }); ok {
 			if prov, ok := orig.Unwrap().(components.PromRegisterer); ok {
@@ -816,6 +838,14 @@ func New(ctx context.Context, config *lepconfig.Config, endpoint string) (_ *Ser
 		Desc: URLPathHealthzDesc,
 	})
 
+	sxidHandler := gin.WrapH(nvidia_query_sxid.Handler())
+	router.GET(nvidia_query_sxid.URLPath, sxidHandler)
+	router.GET(nvidia_query_sxid.URLPath+"/:id", sxidHandler)
+	registeredPaths = append(registeredPaths, componentHandlerDescription{
+		Path: nvidia_query_sxid.URLPath,
+		Desc: "Get the NVIDIA NVSwitch SXid error details",
+	})
+
 	admin := router.Group("/admin")
 
 	admin.GET(URLPathConfig, createConfigHandler(config))
@@ -873,18 +903,22 @@ func New(ctx context.Context, config *lepconfig.Config, endpoint string) (_ *Ser
 
 const checkMark = "\033[32m✔\033[0m"
 
+// the max time to wait for the in-flight component polls on the shutdown
+const componentsDrainTimeout = 15 * time.Second
+
 func (s *Server) Stop() {
 	if s.session != nil {
 		s.session.Stop()
 	}
-	for name, component := range components.GetAllComponents() {
-		closer, ok := component.(io.Closer)
-		if !ok {
-			continue
-		}
-		if err := closer.Close(); err != nil {
-			log.Logger.Errorf("failed to close plugin %v: %v", name, err)
-		}
+
+	ctx, cancel := context.WithTimeout(context.Background(), componentsDrainTimeout)
+	undrained, err := s.registry.DrainAndClose(ctx)
+	cancel()
+	if len(undrained) > 0 {
+		log.Logger.Warnw("components did not drain in time", "components", undrained)
+	}
+	if err != nil {
+		log.Logger.Errorw("failed to close components", "error", err)
 	}
 	log.Logger.Debugw("closed db", "error", s.db.Close())
 
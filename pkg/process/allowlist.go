@@ -0,0 +1,70 @@
+package process
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+// ErrCommandNotAllowed is returned by New when the command
+// is not in the allowlist (see WithAllowedCommands).
+var ErrCommandNotAllowed = errors.New("command not allowed")
+
+// Returns the allowed paths of the commands, resolved as the commands to run
+// (looked up in the PATH unless with a path separator) with the symlinks evaluated.
+// The commands that cannot be resolved (e.g., not installed) are skipped,
+// as they cannot be launched anyway.
+func resolveAllowedCommands(commands []string) (map[string]struct{}, error) {
+	allowed := make(map[string]struct{}, len(commands))
+	for _, name := range commands {
+		if name == "" {
+			return nil, errors.New("empty allowed command")
+		}
+		path, err := resolveCommand(name, "")
+		if err != nil {
+			continue
+		}
+		canonical, err := canonicalPath(path)
+		if err != nil {
+			continue
+		}
+		allowed[canonical] = struct{}{}
+	}
+	return allowed, nil
+}
+
+// Returns nil if the binary to launch is in the allowed paths,
+// or no allowlist is configured.
+// In the bash script mode, the binary to launch is bash itself,
+// thus allowing bash allows any script.
+func checkAllowedCommands(commands [][]string, runAsBashScript bool, workingDir string, allowed map[string]struct{}) error {
+	if allowed == nil {
+		return nil
+	}
+
+	name := "bash"
+	if !runAsBashScript {
+		name = commands[0][0]
+	}
+	path, err := resolveCommand(name, workingDir)
+	if err != nil {
+		return err
+	}
+	canonical, err := canonicalPath(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve command %q: %w", name, err)
+	}
+	if _, ok := allowed[canonical]; !ok {
+		return fmt.Errorf("%w: %q (resolved to %q)", ErrCommandNotAllowed, name, canonical)
+	}
+	return nil
+}
+
+// Returns the absolute path with the symlinks evaluated.
+func canonicalPath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(abs)
+}
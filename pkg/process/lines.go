@@ -0,0 +1,64 @@
+package process
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+)
+
+// Returns the channel of the complete stdout lines (without the trailing newline),
+// and the channel of the error that stopped the scanning, if any.
+// Both channels are closed when the stdout reaches EOF or the context is done.
+// The pipe is closed once the command exits, so any stdout not yet read by then is lost
+// without an error: use WithOutputFile for the short-lived commands.
+func (p *process) StdoutLines(ctx context.Context) (<-chan string, <-chan error) {
+	return readLines(ctx, p.StdoutReader())
+}
+
+// Returns the channel of the complete stderr lines (without the trailing newline),
+// and the channel of the error that stopped the scanning, if any.
+// Both channels are closed when the stderr reaches EOF or the context is done.
+// The pipe is closed once the command exits, so any stderr not yet read by then is lost
+// without an error: use WithOutputFile for the short-lived commands.
+func (p *process) StderrLines(ctx context.Context) (<-chan string, <-chan error) {
+	return readLines(ctx, p.StderrReader())
+}
+
+var errReaderNotAvailable = errors.New("reader not available")
+
+func readLines(ctx context.Context, rd io.Reader) (<-chan string, <-chan error) {
+	linec := make(chan string)
+	errc := make(chan error, 1)
+
+	if rd == nil {
+		errc <- errReaderNotAvailable
+		close(linec)
+		close(errc)
+		return linec, errc
+	}
+
+	go func() {
+		defer close(errc)
+		defer close(linec)
+
+		scanner := bufio.NewScanner(rd)
+		for scanner.Scan() {
+			select {
+			case <-ctx.Done():
+				errc <- ctx.Err()
+				return
+			case linec <- scanner.Text():
+			}
+		}
+
+		// the pipe is closed once the command exits, treat it as EOF
+		// (the closed pipe does not tell whether any output was left unread,
+		// so returning the error would fail the reads that did reach the end)
+		if err := scanner.Err(); err != nil && !errors.Is(err, os.ErrClosed) {
+			errc <- err
+		}
+	}()
+	return linec, errc
+}
@@ -1,9 +1,13 @@
 package process
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"syscall"
 	"time"
 )
 
@@ -11,10 +15,29 @@ type OpOption func(*Op)
 
 type Op struct {
 	envs            []string
+	inheritEnvs     bool
 	outputFile      *os.File
+	maxOutputBytes  int64
+	killOnMaxOutput bool
+	stdinReader     io.Reader
+	combinedOutput  bool
 	runAsBashScript bool
+	workingDir      string
+	credential      *credential
+
+	allowedCommands []string
+	// the canonical paths of the allowed commands, nil if no allowlist
+	allowedPaths map[string]struct{}
+
+	gracefulShutdownTimeout time.Duration
+	stopSignal              os.Signal
+	stallTimeout            time.Duration
 
 	restartConfig *RestartConfig
+	stateStore    Store
+
+	preStartHook func(context.Context) error
+	postStopHook func(context.Context) error
 }
 
 func (op *Op) applyOpts(opts []OpOption) error {
@@ -34,8 +57,67 @@ func (op *Op) applyOpts(opts []OpOption) error {
 		foundEnvs[parts[0]] = parts[1]
 	}
 
-	if op.restartConfig != nil && op.restartConfig.Interval == 0 {
-		op.restartConfig.Interval = 5 * time.Second
+	if op.combinedOutput && op.outputFile != nil {
+		return errors.New("cannot use both combined output and output file")
+	}
+
+	if op.maxOutputBytes < 0 {
+		return fmt.Errorf("invalid max output bytes: %d", op.maxOutputBytes)
+	}
+	if op.maxOutputBytes > 0 && op.outputFile == nil {
+		return errors.New("max output bytes requires output file")
+	}
+	if op.killOnMaxOutput && op.maxOutputBytes == 0 {
+		return errors.New("kill on max output requires max output bytes")
+	}
+
+	if op.workingDir != "" {
+		info, err := os.Stat(op.workingDir)
+		if err != nil {
+			return fmt.Errorf("invalid working directory %q: %w", op.workingDir, err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("invalid working directory %q: not a directory", op.workingDir)
+		}
+	}
+
+	if op.allowedCommands != nil {
+		var err error
+		op.allowedPaths, err = resolveAllowedCommands(op.allowedCommands)
+		if err != nil {
+			return err
+		}
+	}
+
+	if op.credential != nil {
+		if err := validateCredential(op.credential.uid, op.credential.gid); err != nil {
+			return err
+		}
+	}
+
+	if op.gracefulShutdownTimeout == 0 {
+		op.gracefulShutdownTimeout = DefaultGracefulShutdownTimeout
+	}
+
+	if op.stopSignal == nil {
+		op.stopSignal = syscall.SIGTERM
+	}
+	if _, ok := op.stopSignal.(syscall.Signal); !ok {
+		return fmt.Errorf("invalid stop signal: %v", op.stopSignal)
+	}
+
+	if op.stallTimeout < 0 {
+		return fmt.Errorf("invalid stall timeout: %v", op.stallTimeout)
+	}
+
+	if op.restartConfig != nil {
+		if err := op.restartConfig.validate(); err != nil {
+			return err
+		}
+	}
+
+	if op.stateStore == nil {
+		op.stateStore = NewMemoryStore()
 	}
 
 	return nil
@@ -49,16 +131,148 @@ func WithEnvs(envs ...string) OpOption {
 	}
 }
 
+// Set true to inherit the environment variables of the current process
+// (os.Environ), followed by the ones set via WithEnvs.
+// Later entries override earlier ones, so WithEnvs takes precedence.
+// Default is to replace the whole environment with the ones set via WithEnvs,
+// for isolation (if no env is set, the current environment is used).
+func WithInheritEnvs() OpOption {
+	return func(op *Op) {
+		op.inheritEnvs = true
+	}
+}
+
 // Sets the file to which stderr and stdout will be written.
 // For instance, you can set it to os.Stderr to pipe all the sub-process
 // stderr and stdout to the parent process's stderr.
 // Default is to set the os.Pipe to forward its output via io.ReadCloser.
+// Use this for the short-lived commands whose output must be read in full,
+// as the default pipes are closed once the command exits,
+// dropping the output not yet read (e.g., via StdoutLines).
 func WithOutputFile(file *os.File) OpOption {
 	return func(op *Op) {
 		op.outputFile = file
 	}
 }
 
+// Sets the maximum number of bytes written to the output file
+// (see WithOutputFile), across all the restarts.
+// The output exceeding the limit is discarded, and the process
+// reports it via Process.Truncated.
+// Default is zero, which means unlimited.
+func WithMaxOutputBytes(n int64) OpOption {
+	return func(op *Op) {
+		op.maxOutputBytes = n
+	}
+}
+
+// Set true to stop the process (as in Stop, with the stop signal first)
+// once the output exceeds the limit set via WithMaxOutputBytes.
+// Default is to keep the process running and discard the output.
+func WithKillOnMaxOutputBytes() OpOption {
+	return func(op *Op) {
+		op.killOnMaxOutput = true
+	}
+}
+
+// Set true to pipe both stdout and stderr to a single reader
+// in the order they are written, as a terminal would show them.
+// Use CombinedReader to read the interleaved output.
+// Cannot be used with WithOutputFile.
+func WithCombinedOutput() OpOption {
+	return func(op *Op) {
+		op.combinedOutput = true
+	}
+}
+
+// Sets the reader from which the process reads its stdin.
+// If the reader implements io.Seeker, it is rewound to the start
+// on every restart. Otherwise, the reader is consumed once and
+// the restarted processes will read an empty stdin.
+func WithStdinReader(rd io.Reader) OpOption {
+	return func(op *Op) {
+		op.stdinReader = rd
+	}
+}
+
+// Sets the working directory of the process.
+// The directory must exist.
+// Default is to inherit the working directory of the current process.
+func WithWorkingDir(dir string) OpOption {
+	return func(op *Op) {
+		op.workingDir = dir
+	}
+}
+
+// Sets the allowlist of the commands that the process can launch,
+// as the command names (looked up in the PATH) or the paths.
+// New rejects the command whose resolved path (with the symlinks evaluated)
+// is not in the allowlist, with an error wrapping ErrCommandNotAllowed.
+// In the bash script mode (see WithRunAsBashScript), bash itself must be allowed,
+// and the script lines are not checked.
+// An empty allowlist rejects all the commands.
+// Default is to allow any command.
+func WithAllowedCommands(commands []string) OpOption {
+	return func(op *Op) {
+		op.allowedCommands = append(make([]string, 0, len(commands)), commands...)
+	}
+}
+
+type credential struct {
+	uid uint32
+	gid uint32
+}
+
+// Sets the user and group IDs to run the process as,
+// to drop the privileges of the daemon.
+// Requires the daemon to run as root, unless the IDs
+// match the current effective user and group.
+// Only supported on Unix.
+func WithCredential(uid, gid uint32) OpOption {
+	return func(op *Op) {
+		op.credential = &credential{uid: uid, gid: gid}
+	}
+}
+
+// DefaultGracefulShutdownTimeout is the default time to wait
+// for the process to exit after the stop signal, before sending SIGKILL.
+const DefaultGracefulShutdownTimeout = 3 * time.Second
+
+// Sets the time to wait for the process to exit after the stop signal,
+// before escalating to SIGKILL.
+// Default is DefaultGracefulShutdownTimeout.
+func WithGracefulShutdownTimeout(timeout time.Duration) OpOption {
+	return func(op *Op) {
+		op.gracefulShutdownTimeout = timeout
+	}
+}
+
+// Sets the signal to gracefully stop the process (e.g., os.Interrupt or
+// syscall.SIGQUIT for the tools that dump their state on those signals),
+// sent to the process group on Stop or context cancellation.
+// SIGKILL is still sent if the process does not exit within
+// the graceful shutdown timeout.
+// Default is SIGTERM.
+func WithStopSignal(sig os.Signal) OpOption {
+	return func(op *Op) {
+		op.stopSignal = sig
+	}
+}
+
+// Sets the timeout to stop the process when no output is read from
+// stdout/stderr (or written to the output file), for the helpers that hang
+// without exiting. The stalled process is stopped as in Stop (the stop signal
+// first, then SIGKILL), and exits with an error wrapping ErrProcessStalled,
+// so it is restarted if configured (see WithRestartConfig).
+// Without WithOutputFile, the output must be consumed (e.g., via StdoutReader)
+// to count as the activity.
+// Default is zero, which disables the stall detection.
+func WithStallTimeout(timeout time.Duration) OpOption {
+	return func(op *Op) {
+		op.stallTimeout = timeout
+	}
+}
+
 // Set true to run commands as a bash script.
 // This is useful for running multiple/complicated commands.
 func WithRunAsBashScript() OpOption {
@@ -73,3 +287,36 @@ func WithRestartConfig(config RestartConfig) OpOption {
 		op.restartConfig = &config
 	}
 }
+
+// Sets the store to persist the restart state (restart count, last exit, and
+// the backoff interval), so that the restart limit and the backoff are
+// resumed from the last saved state when the process is re-created
+// (e.g., after the daemon restarts).
+// The first run of the re-created process is not counted as a restart.
+// Default is an in-memory store (see NewMemoryStore).
+func WithStateStore(store Store) OpOption {
+	return func(op *Op) {
+		op.stateStore = store
+	}
+}
+
+// Sets the hook to run before the process starts (e.g., to create
+// the coredump directory for the helper), on Start and before every restart.
+// If the hook fails, the process is not started: Start returns the error,
+// and a pending restart is abandoned with the error.
+func WithPreStartHook(hook func(context.Context) error) OpOption {
+	return func(op *Op) {
+		op.preStartHook = hook
+	}
+}
+
+// Sets the hook to run after the process stops (e.g., to clean up
+// the files left by the helper), at the end of Stop and after every
+// exit that is followed by a restart.
+// The hook runs once for each successful pre-start (see WithPreStartHook).
+// The hook error is returned by Stop, but does not prevent the restarts.
+func WithPostStopHook(hook func(context.Context) error) OpOption {
+	return func(op *Op) {
+		op.postStopHook = hook
+	}
+}
@@ -0,0 +1,53 @@
+package process
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// limitedWriter writes up to "limit" bytes to the underlying writer,
+// and discards the rest while reporting success, so that the process
+// is not blocked (or killed by SIGPIPE) on the output.
+type limitedWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	limit   int64
+	written int64
+
+	truncated atomic.Bool
+
+	// called once when the limit is exceeded
+	onExceededOnce sync.Once
+	onExceeded     func()
+}
+
+func (lw *limitedWriter) Write(b []byte) (int, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	remaining := lw.limit - lw.written
+	if int64(len(b)) <= remaining {
+		n, err := lw.w.Write(b)
+		lw.written += int64(n)
+		return n, err
+	}
+
+	if remaining > 0 {
+		n, err := lw.w.Write(b[:remaining])
+		lw.written += int64(n)
+		if err != nil {
+			return n, err
+		}
+	}
+
+	lw.truncated.Store(true)
+	if lw.onExceeded != nil {
+		lw.onExceededOnce.Do(lw.onExceeded)
+	}
+	return len(b), nil
+}
+
+func (lw *limitedWriter) isTruncated() bool {
+	return lw.truncated.Load()
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -17,28 +18,131 @@ import (
 	"github.com/leptonai/gpud/log"
 )
 
+// ErrProcessKilled is returned by Stop when the process did not exit
+// within the graceful shutdown timeout and had to be killed with SIGKILL.
+var ErrProcessKilled = errors.New("process killed after graceful shutdown timeout")
+
 type Process interface {
 	Start(ctx context.Context) error
 	Stop(ctx context.Context) error
 
 	// Waits for the process to exit and returns the error, if any.
 	// If the command completes successfully, the error will be nil.
+	// The error is sent for every exit, including the ones followed by a restart.
 	Wait() <-chan error
 
+	// Blocks until the process exits with no more restarts, and returns
+	// the last error (nil if the command completed successfully).
+	// Returns ctx.Err() if the context is done first.
+	// Returns the stored result immediately if the process has already exited.
+	WaitContext(ctx context.Context) error
+
 	PID() int32
 
+	// Returns the time the current (or last) process run started,
+	// updated on every restart. Zero before the process starts.
+	StartedAt() time.Time
+	// Returns true if the process has started and not exited yet.
+	Running() bool
+
+	// Returns the exit code of the last process run,
+	// and false if the process has not exited yet.
+	// The exit code is -1 if the process was terminated by a signal.
+	ExitCode() (int, bool)
+
+	// Returns the number of times the process has been restarted
+	// (see RestartConfig), since the last healthy run if
+	// RestartConfig.MinHealthyDuration is set.
+	RestartCount() int
+
+	// Returns true if the output has been truncated
+	// for exceeding the limit set via WithMaxOutputBytes.
+	Truncated() bool
+
 	StdoutReader() io.Reader
 	StderrReader() io.Reader
+	// Returns the reader for the interleaved stdout and stderr output.
+	// Only available with WithCombinedOutput or WithOutputFile.
+	CombinedReader() io.Reader
+
+	// Returns the line-oriented feed of the stdout,
+	// closed on EOF or when the context is done.
+	// The lines not yet read when the command exits are lost
+	// (use WithOutputFile for the short-lived commands).
+	StdoutLines(ctx context.Context) (<-chan string, <-chan error)
+	// Returns the line-oriented feed of the stderr,
+	// closed on EOF or when the context is done.
+	// The lines not yet read when the command exits are lost
+	// (use WithOutputFile for the short-lived commands).
+	StderrLines(ctx context.Context) (<-chan string, <-chan error)
 }
 
+// RestartLimitUnlimited is the RestartConfig.Limit value to restart
+// the process without limits. Any non-positive limit is treated as unlimited.
+const RestartLimitUnlimited = 0
+
 // RestartConfig is the configuration for the process restart.
 type RestartConfig struct {
 	// Set true to restart the process on error exit.
 	OnError bool
 	// Set the maximum number of restarts.
+	// Set to RestartLimitUnlimited (or any non-positive value)
+	// to restart without limits.
 	Limit int
 	// Set the interval between restarts.
+	// Must be positive if OnError is true.
 	Interval time.Duration
+	// Set the multiplier (> 1) to grow the interval between restarts
+	// geometrically, starting from Interval, for consecutive error exits
+	// (a successful exit stops the restarts, so the next run starts from Interval).
+	// Zero (or <= 1) keeps the fixed Interval.
+	BackoffMultiplier float64
+	// Set the maximum interval between restarts when BackoffMultiplier is set.
+	// Zero means no upper bound.
+	MaxInterval time.Duration
+	// Set the minimum run time for the process to be considered healthy.
+	// If the process ran at least this long before an error exit,
+	// the restart count and the backoff are reset, so that a long-lived
+	// process that occasionally crashes does not exhaust the Limit.
+	// Otherwise, the exit counts towards the Limit as a crash.
+	// Zero counts every error exit towards the Limit.
+	// As with the unlimited restarts, the errors must be read from
+	// Process.Wait to keep restarting.
+	MinHealthyDuration time.Duration
+}
+
+func (cfg *RestartConfig) validate() error {
+	if !cfg.OnError {
+		return nil
+	}
+	if cfg.Interval <= 0 {
+		return fmt.Errorf("invalid restart config: interval must be positive when restarting on error (got %v)", cfg.Interval)
+	}
+	if cfg.BackoffMultiplier < 0 {
+		return fmt.Errorf("invalid restart config: backoff multiplier must not be negative (got %v)", cfg.BackoffMultiplier)
+	}
+	if cfg.MaxInterval < 0 {
+		return fmt.Errorf("invalid restart config: max interval must not be negative (got %v)", cfg.MaxInterval)
+	}
+	if cfg.MaxInterval > 0 && cfg.MaxInterval < cfg.Interval {
+		return fmt.Errorf("invalid restart config: max interval %v must not be less than interval %v", cfg.MaxInterval, cfg.Interval)
+	}
+	if cfg.MinHealthyDuration < 0 {
+		return fmt.Errorf("invalid restart config: min healthy duration must not be negative (got %v)", cfg.MinHealthyDuration)
+	}
+	return nil
+}
+
+// Returns the next restart interval after the given interval.
+func (cfg *RestartConfig) nextInterval(interval time.Duration) time.Duration {
+	if cfg.BackoffMultiplier <= 1 {
+		return cfg.Interval
+	}
+	next := time.Duration(float64(interval) * cfg.BackoffMultiplier)
+	if cfg.MaxInterval > 0 && next > cfg.MaxInterval {
+		next = cfg.MaxInterval
+	}
+	return next
 }
 
 type process struct {
@@ -51,15 +155,55 @@ type process struct {
 	pid         int32
 	commandArgs []string
 	envs        []string
+	workingDir  string
+	credential  *credential
 	runBashFile *os.File
-
-	outputFile   *os.File
-	stdoutReader io.ReadCloser
-	stderrReader io.ReadCloser
+	// set to true once the command has been started at least once
+	started bool
+	// closed when the current command exits
+	exitedc chan struct{}
+	// the time the current command started
+	startedAt time.Time
+
+	gracefulShutdownTimeout time.Duration
+	stopSignal              syscall.Signal
+
+	// zero to disable the stall detection
+	stallTimeout time.Duration
+	// the last output time in unix nanoseconds, reset on every run
+	lastOutput atomic.Int64
+	// set to true once the current run is stopped for the stall
+	stalled atomic.Bool
+
+	exitCode int32
+	// set to 1 once the exit code is available
+	exited int32
+
+	restartCount int32
+	// the restart interval resumed from the state store, zero if none
+	resumedRestartInterval time.Duration
+	stateStore             Store
+
+	// closed when the process exits with no more restarts
+	waitDone chan struct{}
+	waitErr  error
+
+	stdinReader    io.Reader
+	combinedOutput bool
+	outputFile     *os.File
+	// non-nil if the output file size is limited
+	outputLimiter *limitedWriter
+	stdoutReader  io.ReadCloser
+	stderrReader  io.ReadCloser
 
 	wg sync.WaitGroup
 
 	restartConfig *RestartConfig
+
+	preStartHook func(context.Context) error
+	postStopHook func(context.Context) error
+	// set to 1 when the post-stop hook is due for the last pre-start
+	postStopPending int32
 }
 
 func New(commands [][]string, opts ...OpOption) (Process, error) {
@@ -67,17 +211,11 @@ func New(commands [][]string, opts ...OpOption) (Process, error) {
 	if err := op.applyOpts(opts); err != nil {
 		return nil, err
 	}
-	if len(commands) == 0 {
-		return nil, errors.New("no commands provided")
-	}
-	if len(commands) > 1 && !op.runAsBashScript {
-		return nil, errors.New("cannot run multiple commands without a bash script mode")
+	if err := validateCommands(commands, op.runAsBashScript, op.workingDir); err != nil {
+		return nil, err
 	}
-	for _, args := range commands {
-		cmd := strings.Split(args[0], " ")[0]
-		if !commandExists(cmd) {
-			return nil, fmt.Errorf("command not found: %q", cmd)
-		}
+	if err := checkAllowedCommands(commands, op.runAsBashScript, op.workingDir, op.allowedPaths); err != nil {
+		return nil, err
 	}
 
 	var cmdArgs []string
@@ -95,36 +233,74 @@ func New(commands [][]string, opts ...OpOption) (Process, error) {
 			_ = bashFile.Sync()
 		}()
 		cmdArgs = []string{"bash", bashFile.Name()}
-	}
 
-	for _, args := range commands {
-		if bashFile == nil {
-			cmdArgs = args
-			continue
-		}
-
-		if _, err := bashFile.Write([]byte(strings.Join(args, " "))); err != nil {
-			return nil, err
-		}
-		if _, err := bashFile.Write([]byte("\n")); err != nil {
+		if _, err := bashFile.Write([]byte(bashScriptBody(commands))); err != nil {
 			return nil, err
 		}
+	} else {
+		cmdArgs = commands[0]
+	}
+
+	envs := op.envs
+	if op.inheritEnvs {
+		envs = append(os.Environ(), op.envs...)
 	}
 
 	errcBuffer := 1
 	if op.restartConfig != nil && op.restartConfig.OnError && op.restartConfig.Limit > 0 {
-		errcBuffer = op.restartConfig.Limit
+		// the initial run plus the restarts
+		errcBuffer = op.restartConfig.Limit + 1
 	}
-	return &process{
+	p := &process{
 		cmd:         nil,
 		errc:        make(chan error, errcBuffer),
 		commandArgs: cmdArgs,
-		envs:        op.envs,
+		envs:        envs,
+		workingDir:  op.workingDir,
+		credential:  op.credential,
 		runBashFile: bashFile,
+		stdinReader: op.stdinReader,
 		outputFile:  op.outputFile,
 
+		combinedOutput: op.combinedOutput,
+
+		gracefulShutdownTimeout: op.gracefulShutdownTimeout,
+		stopSignal:              op.stopSignal.(syscall.Signal),
+		stallTimeout:            op.stallTimeout,
+
 		restartConfig: op.restartConfig,
-	}, nil
+		stateStore:    op.stateStore,
+
+		preStartHook: op.preStartHook,
+		postStopHook: op.postStopHook,
+	}
+
+	state, err := op.stateStore.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load process state: %w", err)
+	}
+	if state != nil {
+		log.Logger.Debugw("resuming process state", "restartCount", state.RestartCount, "restartInterval", state.RestartInterval)
+		p.restartCount = int32(state.RestartCount)
+		p.resumedRestartInterval = state.RestartInterval
+	}
+
+	if op.maxOutputBytes > 0 {
+		p.outputLimiter = &limitedWriter{
+			w:     op.outputFile,
+			limit: op.maxOutputBytes,
+		}
+		if op.killOnMaxOutput {
+			p.outputLimiter.onExceeded = func() {
+				log.Logger.Warnw("process output exceeded the limit, stopping the process", "limit", op.maxOutputBytes)
+
+				// sends the stop signal to the process (see cmd.Cancel)
+				// no lock, as the writer runs while the command is waited
+				p.cancel()
+			}
+		}
+	}
+	return p, nil
 }
 
 func (p *process) Start(ctx context.Context) error {
@@ -139,14 +315,28 @@ func (p *process) Start(ctx context.Context) error {
 	p.ctx = cctx
 	p.cancel = ccancel
 
+	if err := p.runPreStartHook(cctx); err != nil {
+		ccancel()
+		return err
+	}
 	if err := p.startCommand(); err != nil {
+		// not started, pair the pre-start hook and allow the retries
+		p.cmd = nil
+		ccancel()
+		if hookErr := p.runPostStopHook(ctx); hookErr != nil {
+			err = errors.Join(err, hookErr)
+		}
 		return err
 	}
 
+	waitDone := make(chan struct{})
+	p.waitDone = waitDone
+
 	p.wg.Add(1)
 	go func() {
 		defer p.wg.Done()
-		p.cmdWait()
+		p.waitErr = p.cmdWait()
+		close(waitDone)
 	}()
 
 	return nil
@@ -154,13 +344,60 @@ func (p *process) Start(ctx context.Context) error {
 
 func (p *process) startCommand() error {
 	log.Logger.Debugw("starting command", "command", p.commandArgs)
-	p.cmd = exec.CommandContext(p.ctx, p.commandArgs[0], p.commandArgs[1:]...)
-	p.cmd.Env = p.envs
+	cmd := exec.CommandContext(p.ctx, p.commandArgs[0], p.commandArgs[1:]...)
+	cmd.Env = p.envs
+	cmd.Dir = p.workingDir
+	p.setSysProcAttr(cmd)
+
+	// on context cancellation, send the stop signal (instead of the default SIGKILL)
+	// to the whole process group, and escalate to SIGKILL
+	// if the process does not exit in time
+	cmd.Cancel = func() error {
+		return signalProcessGroup(cmd.Process, p.stopSignal)
+	}
+	cmd.WaitDelay = p.gracefulShutdownTimeout
+
+	p.cmd = cmd
+	p.exitedc = make(chan struct{})
+
+	if p.stdinReader != nil {
+		stdin := p.stdinReader
+		if p.started {
+			// restarted process, rewind the stdin if possible
+			// otherwise, the reader has already been consumed
+			if seeker, ok := stdin.(io.Seeker); ok {
+				if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+					return fmt.Errorf("failed to rewind stdin: %w", err)
+				}
+			} else {
+				stdin = strings.NewReader("")
+			}
+		}
+		p.cmd.Stdin = stdin
+	}
 
 	switch {
+	case p.outputLimiter != nil:
+		// same writer for both, so the output is interleaved and counted once
+		w := p.trackWriter(p.outputLimiter)
+		p.cmd.Stdout = w
+		p.cmd.Stderr = w
+
 	case p.outputFile != nil:
-		p.cmd.Stdout = p.outputFile
-		p.cmd.Stderr = p.outputFile
+		w := p.trackWriter(p.outputFile)
+		p.cmd.Stdout = w
+		p.cmd.Stderr = w
+
+	case p.combinedOutput:
+		var err error
+		p.stdoutReader, err = p.cmd.StdoutPipe()
+		if err != nil {
+			return fmt.Errorf("failed to get stdout pipe: %w", err)
+		}
+		// share the same pipe writer so the output is interleaved
+		p.cmd.Stderr = p.cmd.Stdout
+		p.stdoutReader = p.trackReader(p.stdoutReader)
+		p.stderrReader = nil
 
 	default:
 		var err error
@@ -172,26 +409,89 @@ func (p *process) startCommand() error {
 		if err != nil {
 			return fmt.Errorf("failed to get stderr pipe: %w", err)
 		}
+		p.stdoutReader = p.trackReader(p.stdoutReader)
+		p.stderrReader = p.trackReader(p.stderrReader)
 	}
 
 	if err := p.cmd.Start(); err != nil {
+		if p.credential != nil && errors.Is(err, os.ErrPermission) {
+			return fmt.Errorf("failed to start command as uid %d gid %d (permission denied): %w", p.credential.uid, p.credential.gid, err)
+		}
 		return fmt.Errorf("failed to start command: %w", err)
 	}
+	p.started = true
+	p.startedAt = time.Now().UTC()
 	atomic.StoreInt32(&p.pid, int32(p.cmd.Process.Pid))
+	atomic.StoreInt32(&p.exited, 0)
+
+	if p.stallTimeout > 0 {
+		p.lastOutput.Store(time.Now().UnixNano())
+		p.stalled.Store(false)
+		go p.watchStall(p.cmd.Process, p.exitedc)
+	}
 
 	return nil
 }
 
+// Returns the writer recording the output activity,
+// if the stall detection is enabled.
+func (p *process) trackWriter(w io.Writer) io.Writer {
+	if p.stallTimeout <= 0 {
+		return w
+	}
+	return &activityWriter{w: w, last: &p.lastOutput}
+}
+
+// Returns the reader recording the output activity,
+// if the stall detection is enabled.
+func (p *process) trackReader(r io.ReadCloser) io.ReadCloser {
+	if p.stallTimeout <= 0 {
+		return r
+	}
+	return &activityReader{r: r, last: &p.lastOutput}
+}
+
 func (p *process) Wait() <-chan error {
 	return p.errc
 }
 
-func (p *process) cmdWait() {
-	restartCount := 0
+func (p *process) WaitContext(ctx context.Context) error {
+	p.cmdMu.RLock()
+	waitDone := p.waitDone
+	p.cmdMu.RUnlock()
+
+	if waitDone == nil {
+		return errors.New("process not started")
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-waitDone:
+		return p.waitErr
+	}
+}
+
+// Waits for the command (and its restarts) to exit,
+// and returns the last error.
+func (p *process) cmdWait() error {
+	var lastErr error
+	var restartInterval time.Duration
+	if p.restartConfig != nil {
+		restartInterval = p.restartConfig.Interval
+		if p.resumedRestartInterval > 0 {
+			restartInterval = p.resumedRestartInterval
+		}
+	}
 	for {
+		cmd := p.cmd
+		exitedc := p.exitedc
+
 		errc := make(chan error)
 		go func() {
-			errc <- p.cmd.Wait()
+			err := cmd.Wait()
+			close(exitedc)
+			errc <- err
 		}()
 
 		select {
@@ -199,54 +499,164 @@ func (p *process) cmdWait() {
 			// command aborted (e.g., Stop called)
 			// cmd.Wait will return error
 			err := <-errc
+			p.setExitCode(err)
 			p.errc <- err
-			return
+			return err
 
 		case err := <-errc:
+			p.setExitCode(err)
+			if err != nil && p.stalled.Load() {
+				err = fmt.Errorf("%w: %w", ErrProcessStalled, err)
+			}
 			p.errc <- err
+			lastErr = err
 
 			if err == nil {
 				log.Logger.Debugw("process exited successfully")
-				return
+
+				// a successful exit resets the backoff
+				p.saveState(0)
+				return nil
 			}
 
-			if exitErr, ok := err.(*exec.ExitError); ok {
+			var exitErr *exec.ExitError
+			if errors.Is(err, ErrProcessStalled) {
+				log.Logger.Warnw("command stopped for no output", "error", err, "cmd", cmd.String(), "stallTimeout", p.stallTimeout)
+			} else if errors.As(err, &exitErr) {
 				if exitErr.ExitCode() == -1 {
 					if p.ctx.Err() != nil {
-						log.Logger.Debugw("command was terminated (exit code -1) by the root context cancellation", "cmd", p.cmd.String(), "contextError", p.ctx.Err())
+						log.Logger.Debugw("command was terminated (exit code -1) by the root context cancellation", "cmd", cmd.String(), "contextError", p.ctx.Err())
 					} else {
-						log.Logger.Warnw("command was terminated (exit code -1) for unknown reasons", "cmd", p.cmd.String())
+						log.Logger.Warnw("command was terminated (exit code -1) for unknown reasons", "cmd", cmd.String())
 					}
 				} else {
-					log.Logger.Warnw("command exited with non-zero status", "error", err, "cmd", p.cmd.String(), "exitCode", exitErr.ExitCode())
+					log.Logger.Warnw("command exited with non-zero status", "error", err, "cmd", cmd.String(), "exitCode", exitErr.ExitCode())
 				}
 			} else {
-				log.Logger.Warnw("error waiting for command to finish", "error", err, "cmd", p.cmd.String())
+				log.Logger.Warnw("error waiting for command to finish", "error", err, "cmd", cmd.String())
 			}
 
 			if p.restartConfig == nil || !p.restartConfig.OnError {
 				log.Logger.Warnw("process exited with error", "error", err)
-				return
+				p.saveState(0)
+				return err
+			}
+
+			if p.restartConfig.MinHealthyDuration > 0 {
+				if ran := time.Since(p.StartedAt()); ran >= p.restartConfig.MinHealthyDuration {
+					log.Logger.Debugw("process ran long enough before exiting, resetting restart count", "ran", ran, "minHealthyDuration", p.restartConfig.MinHealthyDuration)
+					atomic.StoreInt32(&p.restartCount, 0)
+					restartInterval = p.restartConfig.Interval
+				}
 			}
 
+			// the interval for the restart after the upcoming one,
+			// as the daemon may restart before the upcoming restart
+			p.saveState(p.restartConfig.nextInterval(restartInterval))
+
+			restartCount := int(atomic.LoadInt32(&p.restartCount))
 			if p.restartConfig.Limit > 0 && restartCount >= p.restartConfig.Limit {
 				log.Logger.Warnw("process exited with error, but restart limits reached", "restartCount", restartCount, "error", err)
-				return
+				return err
+			}
+
+			if err := p.runPostStopHook(p.ctx); err != nil {
+				log.Logger.Warnw("post-stop hook failed before restart", "error", err)
 			}
 		}
 
 		select {
 		case <-p.ctx.Done():
-			return
-		case <-time.After(p.restartConfig.Interval):
+			return lastErr
+		case <-time.After(restartInterval):
 		}
+		restartInterval = p.restartConfig.nextInterval(restartInterval)
 
-		if err := p.startCommand(); err != nil {
+		if err := p.runPreStartHook(p.ctx); err != nil {
+			log.Logger.Warnw("pre-start hook failed, not restarting command", "error", err)
+			return err
+		}
+		if err := p.restartCommand(); err != nil {
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				// stopped before the restart, pair the pre-start hook
+				if err := p.runPostStopHook(context.Background()); err != nil {
+					log.Logger.Warnw("post-stop hook failed", "error", err)
+				}
+				return lastErr
+			}
 			log.Logger.Warnw("failed to restart command", "error", err)
-			return
+			// not restarted, pair the pre-start hook
+			if err := p.runPostStopHook(context.Background()); err != nil {
+				log.Logger.Warnw("post-stop hook failed", "error", err)
+			}
+			return err
+		}
+
+		atomic.AddInt32(&p.restartCount, 1)
+	}
+}
+
+// Starts the command again under the lock, unless the process
+// has been stopped in the meantime.
+func (p *process) restartCommand() error {
+	p.cmdMu.Lock()
+	defer p.cmdMu.Unlock()
+
+	if err := p.ctx.Err(); err != nil {
+		return err
+	}
+	return p.startCommand()
+}
+
+// Runs the pre-start hook, if any, and marks the post-stop hook as due.
+func (p *process) runPreStartHook(ctx context.Context) error {
+	if p.preStartHook != nil {
+		if err := p.preStartHook(ctx); err != nil {
+			return fmt.Errorf("pre-start hook failed: %w", err)
 		}
+	}
+	atomic.StoreInt32(&p.postStopPending, 1)
+	return nil
+}
 
-		restartCount++
+// Runs the post-stop hook, if due for the last pre-start.
+func (p *process) runPostStopHook(ctx context.Context) error {
+	if p.postStopHook == nil || !atomic.CompareAndSwapInt32(&p.postStopPending, 1, 0) {
+		return nil
+	}
+	if err := p.postStopHook(ctx); err != nil {
+		return fmt.Errorf("post-stop hook failed: %w", err)
+	}
+	return nil
+}
+
+// Saves the restart state with the interval to wait
+// before the next restart on an error exit.
+func (p *process) saveState(restartInterval time.Duration) {
+	code, _ := p.ExitCode()
+	state := State{
+		RestartCount:    p.RestartCount(),
+		LastExitCode:    code,
+		LastExitTime:    time.Now().UTC(),
+		RestartInterval: restartInterval,
+	}
+	if err := p.stateStore.Save(state); err != nil {
+		log.Logger.Warnw("failed to save process state", "error", err)
+	}
+}
+
+// Stores the exit code from the error returned by cmd.Wait.
+// The exit code is not available if the error is not an exit error
+// (e.g., failed to wait for the process due to I/O errors).
+func (p *process) setExitCode(err error) {
+	if err == nil {
+		atomic.StoreInt32(&p.exitCode, 0)
+		atomic.StoreInt32(&p.exited, 1)
+		return
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		atomic.StoreInt32(&p.exitCode, int32(exitErr.ExitCode()))
+		atomic.StoreInt32(&p.exited, 1)
 	}
 }
 
@@ -258,42 +668,98 @@ func (p *process) Stop(ctx context.Context) error {
 		return errors.New("process not started")
 	}
 
+	// sends the stop signal to the process (see cmd.Cancel)
 	p.cancel()
 
-	finished := false
-	if err := p.cmd.Process.Signal(syscall.SIGTERM); err != nil {
-		if err.Error() == "os: process already finished" {
-			finished = true
-		} else {
-			log.Logger.Warnw("failed to send SIGTERM to process", "error", err)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.exitedc:
+	case <-time.After(p.gracefulShutdownTimeout):
+		if err := signalProcessGroup(p.cmd.Process, syscall.SIGKILL); err != nil && !errors.Is(err, os.ErrProcessDone) {
+			log.Logger.Warnw("failed to send SIGKILL to process", "error", err)
 		}
-	}
-
-	if !finished {
 		select {
-		case <-p.ctx.Done():
+		case <-ctx.Done():
 			return ctx.Err()
-		case <-time.After(3 * time.Second):
-			if err := p.cmd.Process.Kill(); err != nil {
-				log.Logger.Warnw("failed to send SIGKILL to process", "error", err)
-			}
+		case <-p.exitedc:
 		}
 	}
+	killed := killedBySIGKILL(p.cmd.ProcessState)
+
+	// the child processes may outlive the direct child
+	// (e.g., ignoring the stop signal), so kill the rest of the group
+	_ = signalProcessGroup(p.cmd.Process, syscall.SIGKILL)
 
 	if p.runBashFile != nil {
 		_ = p.runBashFile.Sync()
 		_ = p.runBashFile.Close()
-		return os.RemoveAll(p.runBashFile.Name())
+		if err := os.RemoveAll(p.runBashFile.Name()); err != nil {
+			return err
+		}
 	}
 
 	p.cmd = nil
-	return nil
+
+	var err error
+	if killed {
+		err = ErrProcessKilled
+	}
+	if hookErr := p.runPostStopHook(ctx); hookErr != nil {
+		err = errors.Join(err, hookErr)
+	}
+	return err
+}
+
+func killedBySIGKILL(state *os.ProcessState) bool {
+	if state == nil {
+		return false
+	}
+	ws, ok := state.Sys().(syscall.WaitStatus)
+	return ok && ws.Signaled() && ws.Signal() == syscall.SIGKILL
 }
 
 func (p *process) PID() int32 {
 	return atomic.LoadInt32(&p.pid)
 }
 
+func (p *process) StartedAt() time.Time {
+	p.cmdMu.RLock()
+	defer p.cmdMu.RUnlock()
+
+	return p.startedAt
+}
+
+func (p *process) Running() bool {
+	p.cmdMu.RLock()
+	defer p.cmdMu.RUnlock()
+
+	if p.exitedc == nil {
+		return false
+	}
+	select {
+	case <-p.exitedc:
+		return false
+	default:
+		return true
+	}
+}
+
+func (p *process) ExitCode() (int, bool) {
+	if atomic.LoadInt32(&p.exited) == 0 {
+		return 0, false
+	}
+	return int(atomic.LoadInt32(&p.exitCode)), true
+}
+
+func (p *process) RestartCount() int {
+	return int(atomic.LoadInt32(&p.restartCount))
+}
+
+func (p *process) Truncated() bool {
+	return p.outputLimiter != nil && p.outputLimiter.isTruncated()
+}
+
 func (p *process) StdoutReader() io.Reader {
 	p.cmdMu.RLock()
 	defer p.cmdMu.RUnlock()
@@ -314,6 +780,19 @@ func (p *process) StderrReader() io.Reader {
 	return p.stderrReader
 }
 
+func (p *process) CombinedReader() io.Reader {
+	p.cmdMu.RLock()
+	defer p.cmdMu.RUnlock()
+
+	if p.outputFile != nil {
+		return p.outputFile
+	}
+	if p.combinedOutput {
+		return p.stdoutReader
+	}
+	return nil
+}
+
 const bashScriptHeader = `#!/bin/bash
 
 # do not mask errors in a pipeline
@@ -327,6 +806,117 @@ set -o errexit
 
 `
 
+// Validates the commands and the options as New does, without creating
+// the bash script file or starting the process (dry run), so that the
+// helper commands can be checked at config load time.
+// Returns the resolved command line, only for display (e.g., logging).
+// In the bash script mode, the process runs the script file created by New
+// ("bash <file>", with the bash script header such as "set -o errexit"),
+// while the returned command line shows the script lines as "bash -c",
+// which is not the exact invocation and may behave differently if run as is.
+func Validate(commands [][]string, opts ...OpOption) (string, error) {
+	op := &Op{}
+	if err := op.applyOpts(opts); err != nil {
+		return "", err
+	}
+	if err := validateCommands(commands, op.runAsBashScript, op.workingDir); err != nil {
+		return "", err
+	}
+	if err := checkAllowedCommands(commands, op.runAsBashScript, op.workingDir, op.allowedPaths); err != nil {
+		return "", err
+	}
+
+	if op.runAsBashScript {
+		bashPath, err := exec.LookPath("bash")
+		if err != nil {
+			return "", fmt.Errorf("command not found: %q (required to run as a bash script)", "bash")
+		}
+		return fmt.Sprintf("%s -c %q", bashPath, strings.TrimSuffix(bashScriptBody(commands), "\n")), nil
+	}
+
+	args := commands[0]
+	path, err := resolveCommand(args[0], op.workingDir)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(append([]string{path}, args[1:]...), " "), nil
+}
+
+// Validates the commands can run in the given mode.
+//
+// In the bash script mode, each command is a script line
+// (e.g., "echo hello && sleep 1", "for i in 1 2; do echo $i; done"),
+// so only the bash command is resolved, not the commands in the lines.
+//
+// Otherwise, the single command must be an argv (the command followed by
+// its arguments as separate elements), and the command must resolve:
+// the commands with a path separator (absolute, or relative to the working directory)
+// must be the executable files, and the others are looked up in the PATH.
+func validateCommands(commands [][]string, runAsBashScript bool, workingDir string) error {
+	if len(commands) == 0 {
+		return errors.New("no commands provided")
+	}
+	for _, args := range commands {
+		if len(args) == 0 || strings.TrimSpace(args[0]) == "" {
+			return errors.New("empty command provided")
+		}
+	}
+
+	if runAsBashScript {
+		if !commandExists("bash") {
+			return fmt.Errorf("command not found: %q (required to run as a bash script)", "bash")
+		}
+		return nil
+	}
+
+	if len(commands) > 1 {
+		return fmt.Errorf("cannot run %d commands without the bash script mode (see WithRunAsBashScript)", len(commands))
+	}
+	args := commands[0]
+	if strings.ContainsAny(args[0], " \t\n") {
+		return fmt.Errorf("invalid command %q: pass the arguments as separate elements, or run as a bash script (see WithRunAsBashScript)", args[0])
+	}
+	_, err := resolveCommand(args[0], workingDir)
+	return err
+}
+
+// Returns the path of the command to run.
+// The command with a path separator is checked as is
+// (relative to the working directory, if set, as exec.Cmd runs it),
+// and the others are looked up in the PATH.
+func resolveCommand(name string, workingDir string) (string, error) {
+	if !strings.Contains(name, string(filepath.Separator)) {
+		path, err := exec.LookPath(name)
+		if err != nil {
+			return "", fmt.Errorf("command not found: %q", name)
+		}
+		return path, nil
+	}
+
+	path := name
+	if !filepath.IsAbs(path) && workingDir != "" {
+		path = filepath.Join(workingDir, path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("command not found: %q: %w", name, err)
+	}
+	if info.IsDir() || info.Mode().Perm()&0111 == 0 {
+		return "", fmt.Errorf("command not executable: %q", name)
+	}
+	return path, nil
+}
+
+// Returns the bash script lines of the commands, without the header.
+func bashScriptBody(commands [][]string) string {
+	var sb strings.Builder
+	for _, args := range commands {
+		sb.WriteString(strings.Join(args, " "))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
 func commandExists(name string) bool {
 	p, err := exec.LookPath(name)
 	if err != nil {
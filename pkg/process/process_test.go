@@ -3,9 +3,13 @@ package process
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -40,6 +44,10 @@ func TestProcess(t *testing.T) {
 		t.Fatal("timeout")
 	}
 
+	if code, ok := p.ExitCode(); !ok || code != 0 {
+		t.Fatalf("expected exit code 0, got %d (available %v)", code, ok)
+	}
+
 	if err := p.Stop(ctx); err != nil {
 		t.Fatal(err)
 	}
@@ -268,17 +276,26 @@ func TestProcessWithRestarts(t *testing.T) {
 			if err == nil {
 				t.Fatal("expected error")
 			}
-			if strings.Contains(err.Error(), "exit status 1") {
-				t.Log(err)
-				continue
+			if code, ok := p.ExitCode(); !ok || code != 1 {
+				t.Fatalf("expected exit code 1, got %d (available %v): %v", code, ok, err)
 			}
-			t.Fatal(err)
+			// the next restart may have already happened
+			if p.RestartCount() > i+1 {
+				t.Fatalf("expected at most %d restarts, got %d", i+1, p.RestartCount())
+			}
+			t.Log(err)
 
 		case <-time.After(2 * time.Second):
 			t.Fatal("timeout")
 		}
 	}
 
+	// wait for the last restart
+	time.Sleep(500 * time.Millisecond)
+	if p.RestartCount() != 3 {
+		t.Fatalf("expected 3 restarts, got %d", p.RestartCount())
+	}
+
 	if err := p.Stop(ctx); err != nil {
 		t.Fatal(err)
 	}
@@ -361,3 +378,1516 @@ func TestProcessStream(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestProcessWithStdinReader(t *testing.T) {
+	t.Parallel()
+
+	p, err := New(
+		[][]string{
+			// keep the process running so the stdout pipe is not closed before read
+			{"cat && sleep 1"},
+		},
+		WithRunAsBashScript(),
+		WithStdinReader(strings.NewReader("hello from stdin")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := p.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("pid: %d", p.PID())
+
+	buf := make([]byte, 1024)
+	n, err := p.StdoutReader().Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := string(buf[:n])
+	expectedOutput := "hello from stdin"
+	if output != expectedOutput {
+		t.Fatalf("expected output %q, but got %q", expectedOutput, output)
+	}
+
+	select {
+	case err := <-p.Wait():
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout")
+	}
+
+	if err := p.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProcessWithCombinedOutput(t *testing.T) {
+	t.Parallel()
+
+	p, err := New(
+		[][]string{
+			// keep the process running so the pipe is not closed before read
+			{"echo hello 1 && echo hello 2 1>&2 && echo hello 3 && sleep 1"},
+		},
+		WithRunAsBashScript(),
+		WithCombinedOutput(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := p.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("pid: %d", p.PID())
+
+	scanner := bufio.NewScanner(p.CombinedReader())
+	var output string
+	for i := 0; i < 3 && scanner.Scan(); i++ {
+		output += scanner.Text() + "\n"
+	}
+	expectedOutput := "hello 1\nhello 2\nhello 3\n"
+	if output != expectedOutput {
+		t.Fatalf("expected output %q, but got %q", expectedOutput, output)
+	}
+
+	select {
+	case err := <-p.Wait():
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout")
+	}
+
+	if err := p.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProcessWithCombinedOutputAndOutputFile(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(
+		[][]string{
+			{"echo", "hello"},
+		},
+		WithOutputFile(os.Stderr),
+		WithCombinedOutput(),
+	)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestProcessStopWithGracefulShutdownTimeout(t *testing.T) {
+	t.Parallel()
+
+	p, err := New(
+		[][]string{
+			{"bash", "-c", "trap '' TERM; sleep 10"},
+		},
+		WithOutputFile(os.Stderr),
+		WithGracefulShutdownTimeout(500*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := p.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("pid: %d", p.PID())
+
+	// wait for the trap to be installed
+	time.Sleep(500 * time.Millisecond)
+
+	start := time.Now()
+	err = p.Stop(ctx)
+	if !errors.Is(err, ErrProcessKilled) {
+		t.Fatalf("expected %v, got %v", ErrProcessKilled, err)
+	}
+	if elapsed := time.Since(start); elapsed < 500*time.Millisecond {
+		t.Fatalf("expected to wait for the graceful shutdown timeout, took %v", elapsed)
+	}
+}
+
+func TestProcessWithRestartsBackoff(t *testing.T) {
+	t.Parallel()
+
+	p, err := New(
+		[][]string{
+			{"echo failing && exit 1"},
+		},
+		WithOutputFile(os.Stderr),
+		WithRunAsBashScript(),
+		WithRestartConfig(RestartConfig{
+			OnError:           true,
+			Limit:             3,
+			Interval:          100 * time.Millisecond,
+			BackoffMultiplier: 3,
+			MaxInterval:       time.Second,
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := p.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("pid: %d", p.PID())
+
+	var exits []time.Time
+	for i := 0; i < 4; i++ {
+		select {
+		case err := <-p.Wait():
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			exits = append(exits, time.Now())
+		case <-time.After(3 * time.Second):
+			t.Fatal("timeout")
+		}
+	}
+
+	// expected gaps are ~100ms, ~300ms, ~900ms
+	for i := 2; i < len(exits); i++ {
+		prev, cur := exits[i-1].Sub(exits[i-2]), exits[i].Sub(exits[i-1])
+		t.Logf("restart gap %d: %v", i-1, cur)
+		if cur <= prev {
+			t.Fatalf("expected restart gap to increase, got %v after %v", cur, prev)
+		}
+	}
+
+	if err := p.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProcessWaitContext(t *testing.T) {
+	t.Parallel()
+
+	p, err := New(
+		[][]string{
+			{"echo hello && exit 2"},
+		},
+		WithOutputFile(os.Stderr),
+		WithRunAsBashScript(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := p.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("pid: %d", p.PID())
+
+	err = p.WaitContext(ctx)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if code, ok := p.ExitCode(); !ok || code != 2 {
+		t.Fatalf("expected exit code 2, got %d (available %v)", code, ok)
+	}
+
+	// already exited, must return the stored result immediately
+	cctx, ccancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer ccancel()
+	if err2 := p.WaitContext(cctx); err2 != err {
+		t.Fatalf("expected stored error %v, got %v", err, err2)
+	}
+
+	if err := p.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProcessWaitContextDeadline(t *testing.T) {
+	t.Parallel()
+
+	p, err := New(
+		[][]string{
+			{"sleep", "9999"},
+		},
+		WithOutputFile(os.Stderr),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := p.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("pid: %d", p.PID())
+
+	cctx, ccancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer ccancel()
+	if err := p.WaitContext(cctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	if err := p.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProcessWithInvalidRestartConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		config RestartConfig
+	}{
+		{
+			name:   "zero interval",
+			config: RestartConfig{OnError: true, Limit: 3},
+		},
+		{
+			name:   "zero interval with unlimited restarts",
+			config: RestartConfig{OnError: true, Limit: RestartLimitUnlimited},
+		},
+		{
+			name:   "negative interval",
+			config: RestartConfig{OnError: true, Limit: 3, Interval: -time.Second},
+		},
+		{
+			name:   "negative backoff multiplier",
+			config: RestartConfig{OnError: true, Interval: time.Second, BackoffMultiplier: -2},
+		},
+		{
+			name:   "negative max interval",
+			config: RestartConfig{OnError: true, Interval: time.Second, BackoffMultiplier: 2, MaxInterval: -time.Second},
+		},
+		{
+			name:   "max interval less than interval",
+			config: RestartConfig{OnError: true, Interval: time.Second, BackoffMultiplier: 2, MaxInterval: time.Millisecond},
+		},
+		{
+			name:   "negative min healthy duration",
+			config: RestartConfig{OnError: true, Interval: time.Second, MinHealthyDuration: -time.Second},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New([][]string{{"echo", "hello"}}, WithRestartConfig(tt.config))
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			t.Log(err)
+		})
+	}
+
+	// restart is disabled, so the interval is not required
+	if _, err := New([][]string{{"echo", "hello"}}, WithRestartConfig(RestartConfig{OnError: false})); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProcessWithInheritEnvs(t *testing.T) {
+	t.Parallel()
+
+	tmpFile, err := os.CreateTemp("", "process-test-*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	p, err := New(
+		[][]string{
+			{`echo "${GPUD_TEST_ENV}" && echo "${PATH}"`},
+		},
+		WithOutputFile(tmpFile),
+		WithRunAsBashScript(),
+		WithEnvs("GPUD_TEST_ENV=hello"),
+		WithInheritEnvs(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := p.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.WaitContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(tmpFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", string(content))
+	}
+	if lines[0] != "hello" {
+		t.Fatalf("expected custom env %q, got %q", "hello", lines[0])
+	}
+	if lines[1] != os.Getenv("PATH") {
+		t.Fatalf("expected PATH %q, got %q", os.Getenv("PATH"), lines[1])
+	}
+}
+
+func TestProcessWithWorkingDir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	expected, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpFile, err := os.CreateTemp("", "process-test-*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	p, err := New(
+		[][]string{
+			{"pwd", "-P"},
+		},
+		WithOutputFile(tmpFile),
+		WithWorkingDir(dir),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := p.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.WaitContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(tmpFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(content)); got != expected {
+		t.Fatalf("expected working dir %q, got %q", expected, got)
+	}
+}
+
+func TestProcessWithInvalidWorkingDir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if _, err := New([][]string{{"pwd"}}, WithWorkingDir(filepath.Join(dir, "not-exist"))); err == nil {
+		t.Fatal("expected error for non-existent working dir")
+	}
+
+	f := filepath.Join(dir, "file")
+	if err := os.WriteFile(f, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New([][]string{{"pwd"}}, WithWorkingDir(f)); err == nil {
+		t.Fatal("expected error for non-directory working dir")
+	}
+}
+
+func TestProcessWithMaxOutputBytes(t *testing.T) {
+	t.Parallel()
+
+	tmpFile, err := os.CreateTemp("", "process-test-*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	p, err := New(
+		[][]string{
+			{"seq 1 100"},
+		},
+		WithOutputFile(tmpFile),
+		WithMaxOutputBytes(25),
+		WithRunAsBashScript(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := p.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.WaitContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(tmpFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(content) != 25 {
+		t.Fatalf("expected 25 bytes, got %d (%q)", len(content), string(content))
+	}
+	if !p.Truncated() {
+		t.Fatal("expected output to be truncated")
+	}
+}
+
+func TestProcessWithKillOnMaxOutputBytes(t *testing.T) {
+	t.Parallel()
+
+	tmpFile, err := os.CreateTemp("", "process-test-*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	p, err := New(
+		[][]string{
+			{"yes 0123456789"},
+		},
+		WithOutputFile(tmpFile),
+		WithMaxOutputBytes(100),
+		WithKillOnMaxOutputBytes(),
+		WithRunAsBashScript(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := p.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.WaitContext(ctx); err == nil {
+		t.Fatal("expected the process to be terminated")
+	}
+	if ctx.Err() != nil {
+		t.Fatalf("expected the process to be stopped before the timeout: %v", ctx.Err())
+	}
+	if !p.Truncated() {
+		t.Fatal("expected output to be truncated")
+	}
+	if err := p.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProcessWithInvalidMaxOutputBytes(t *testing.T) {
+	t.Parallel()
+
+	if _, err := New([][]string{{"echo"}}, WithMaxOutputBytes(10)); err == nil {
+		t.Fatal("expected error for max output bytes without output file")
+	}
+	if _, err := New([][]string{{"echo"}}, WithOutputFile(os.Stderr), WithMaxOutputBytes(-1)); err == nil {
+		t.Fatal("expected error for negative max output bytes")
+	}
+	if _, err := New([][]string{{"echo"}}, WithOutputFile(os.Stderr), WithKillOnMaxOutputBytes()); err == nil {
+		t.Fatal("expected error for kill on max output without limit")
+	}
+}
+
+func TestProcessStdoutLines(t *testing.T) {
+	t.Parallel()
+
+	p, err := New(
+		[][]string{
+			// sleep to keep the pipes open until the lines are read
+			// (the pipes are closed once the command exits)
+			{"echo hello && echo world && echo oops >&2 && sleep 1"},
+		},
+		WithRunAsBashScript(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := p.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop(ctx)
+
+	stdoutLines, stdoutErrc := p.StdoutLines(ctx)
+	stderrLines, stderrErrc := p.StderrLines(ctx)
+
+	stderrDone := make(chan []string)
+	go func() {
+		var stderr []string
+		for line := range stderrLines {
+			stderr = append(stderr, line)
+		}
+		stderrDone <- stderr
+	}()
+
+	var stdout []string
+	for line := range stdoutLines {
+		stdout = append(stdout, line)
+	}
+	if err := <-stdoutErrc; err != nil {
+		t.Fatal(err)
+	}
+	if len(stdout) != 2 || stdout[0] != "hello" || stdout[1] != "world" {
+		t.Fatalf("unexpected stdout lines %q", stdout)
+	}
+
+	stderr := <-stderrDone
+	if err := <-stderrErrc; err != nil {
+		t.Fatal(err)
+	}
+	if len(stderr) != 1 || stderr[0] != "oops" {
+		t.Fatalf("unexpected stderr lines %q", stderr)
+	}
+}
+
+func TestProcessStdoutLinesContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	p, err := New(
+		[][]string{
+			{"yes"},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := p.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop(ctx)
+
+	linesCtx, linesCancel := context.WithCancel(ctx)
+	lines, errc := p.StdoutLines(linesCtx)
+	if line := <-lines; line != "y" {
+		t.Fatalf("expected %q, got %q", "y", line)
+	}
+	linesCancel()
+
+	// drain until the channel is closed
+	for range lines {
+	}
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestProcessWithCredential(t *testing.T) {
+	t.Parallel()
+
+	if os.Geteuid() != 0 {
+		t.Skip("skipping test, requires root to run as a different uid")
+	}
+
+	tmpFile, err := os.CreateTemp("", "process-test-*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	// "nobody" on most distributions
+	uid, gid := uint32(65534), uint32(65534)
+	p, err := New(
+		[][]string{
+			{"id", "-u"},
+		},
+		WithOutputFile(tmpFile),
+		WithCredential(uid, gid),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := p.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.WaitContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(tmpFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(content)); got != fmt.Sprintf("%d", uid) {
+		t.Fatalf("expected uid %d, got %q", uid, got)
+	}
+}
+
+func TestProcessWithCredentialWithoutRoot(t *testing.T) {
+	t.Parallel()
+
+	if os.Geteuid() == 0 {
+		t.Skip("skipping test, requires non-root")
+	}
+
+	_, err := New([][]string{{"id", "-u"}}, WithCredential(uint32(os.Geteuid())+1, uint32(os.Getegid())))
+	if !errors.Is(err, os.ErrPermission) {
+		t.Fatalf("expected permission error, got %v", err)
+	}
+}
+
+func TestProcessStopKillsProcessGroup(t *testing.T) {
+	t.Parallel()
+
+	tmpFile, err := os.CreateTemp("", "process-test-*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	p, err := New(
+		[][]string{
+			{"sleep 1000 & echo $! && wait"},
+		},
+		WithOutputFile(tmpFile),
+		WithRunAsBashScript(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := p.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	childPID := 0
+	for childPID == 0 {
+		select {
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for the child pid")
+		case <-time.After(50 * time.Millisecond):
+		}
+		content, err := os.ReadFile(tmpFile.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := strings.TrimSpace(string(content)); s != "" {
+			if _, err := fmt.Sscanf(s, "%d", &childPID); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if !processRunning(childPID) {
+		t.Fatalf("expected child process %d to be running", childPID)
+	}
+
+	if err := p.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	for processRunning(childPID) {
+		select {
+		case <-ctx.Done():
+			t.Fatalf("expected child process %d to be killed with the parent", childPID)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+// Returns true if the process is running (exists and not a zombie).
+func processRunning(pid int) bool {
+	b, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return false
+	}
+	// the state follows the command name in parentheses
+	s := string(b)
+	idx := strings.LastIndex(s, ")")
+	return idx >= 0 && idx+2 < len(s) && s[idx+2] != 'Z'
+}
+
+func TestProcessWithStateStore(t *testing.T) {
+	t.Parallel()
+
+	// resumes from the state saved before the daemon restart
+	store := NewMemoryStore()
+	if err := store.Save(State{RestartCount: 2, LastExitCode: 1, RestartInterval: 200 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := New(
+		[][]string{
+			{"echo failing && exit 1"},
+		},
+		WithOutputFile(os.Stderr),
+		WithRunAsBashScript(),
+		WithRestartConfig(RestartConfig{
+			OnError:           true,
+			Limit:             3,
+			Interval:          50 * time.Millisecond,
+			BackoffMultiplier: 2,
+		}),
+		WithStateStore(store),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.RestartCount() != 2 {
+		t.Fatalf("expected 2 resumed restarts, got %d", p.RestartCount())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := p.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// only one restart is left before reaching the limit
+	if err := p.WaitContext(ctx); err == nil {
+		t.Fatal("expected error")
+	}
+	if p.RestartCount() != 3 {
+		t.Fatalf("expected 3 restarts, got %d", p.RestartCount())
+	}
+
+	state, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state == nil {
+		t.Fatal("expected saved state")
+	}
+	if state.RestartCount != 3 || state.LastExitCode != 1 || state.LastExitTime.IsZero() {
+		t.Fatalf("unexpected saved state: %+v", state)
+	}
+	// resumed from 200ms, then doubled for each error exit
+	if state.RestartInterval != 800*time.Millisecond {
+		t.Fatalf("expected restart interval 800ms, got %v", state.RestartInterval)
+	}
+
+	if err := p.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProcessWithStopSignal(t *testing.T) {
+	t.Parallel()
+
+	f, err := os.CreateTemp(t.TempDir(), "stop-signal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	p, err := New(
+		[][]string{
+			// exits cleanly only on SIGINT, and ignores SIGTERM
+			{"bash", "-c", "trap 'echo got SIGINT; exit 0' INT; trap '' TERM; sleep 10 & wait"},
+		},
+		WithOutputFile(f),
+		WithStopSignal(os.Interrupt),
+		WithGracefulShutdownTimeout(5*time.Second),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := p.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("pid: %d", p.PID())
+
+	// wait for the trap to be installed
+	time.Sleep(500 * time.Millisecond)
+
+	start := time.Now()
+	if err := p.Stop(ctx); err != nil {
+		t.Fatalf("expected clean stop, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= 5*time.Second {
+		t.Fatalf("expected to exit before the graceful shutdown timeout, took %v", elapsed)
+	}
+
+	b, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "got SIGINT") {
+		t.Fatalf("expected the SIGINT handler to run, got %q", string(b))
+	}
+}
+
+func TestProcessWithInvalidStopSignal(t *testing.T) {
+	t.Parallel()
+
+	_, err := New([][]string{{"echo", "hello"}}, WithStopSignal(invalidSignal{}))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+type invalidSignal struct{}
+
+func (invalidSignal) String() string { return "invalid" }
+func (invalidSignal) Signal()        {}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	cmdLine, err := Validate([][]string{{"echo", "hello"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(cmdLine, "echo hello") || !strings.HasPrefix(cmdLine, "/") {
+		t.Fatalf("unexpected resolved command line %q", cmdLine)
+	}
+
+	cmdLine, err = Validate(
+		[][]string{
+			{"echo hello"},
+			{"echo world"},
+		},
+		WithRunAsBashScript(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(cmdLine, "bash -c") || !strings.Contains(cmdLine, `echo hello\necho world`) {
+		t.Fatalf("unexpected resolved command line %q", cmdLine)
+	}
+
+	tests := []struct {
+		name     string
+		commands [][]string
+		opts     []OpOption
+	}{
+		{name: "no commands"},
+		{name: "command not found", commands: [][]string{{"command-does-not-exist"}}},
+		{name: "multiple commands without bash", commands: [][]string{{"echo", "a"}, {"echo", "b"}}},
+		{name: "invalid env", commands: [][]string{{"echo"}}, opts: []OpOption{WithEnvs("INVALID")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Validate(tt.commands, tt.opts...); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestProcessWithHooks(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var events []string
+	record := func(ev string) func(context.Context) error {
+		return func(context.Context) error {
+			mu.Lock()
+			events = append(events, ev)
+			mu.Unlock()
+			return nil
+		}
+	}
+
+	p, err := New(
+		[][]string{
+			{"false"},
+		},
+		WithRestartConfig(RestartConfig{
+			OnError:  true,
+			Limit:    2,
+			Interval: 10 * time.Millisecond,
+		}),
+		WithPreStartHook(record("pre-start")),
+		WithPostStopHook(record("post-stop")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := p.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.WaitContext(ctx); err == nil {
+		t.Fatal("expected error")
+	}
+	if err := p.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// the initial run and two restarts, each wrapped by the hooks
+	expected := []string{"pre-start", "post-stop", "pre-start", "post-stop", "pre-start", "post-stop"}
+	mu.Lock()
+	defer mu.Unlock()
+	if !reflect.DeepEqual(events, expected) {
+		t.Fatalf("expected hooks %v, got %v", expected, events)
+	}
+}
+
+func TestProcessWithHooksStartFailure(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	var mu sync.Mutex
+	events := []string{}
+	record := func(name string) func(context.Context) error {
+		return func(context.Context) error {
+			mu.Lock()
+			events = append(events, name)
+			mu.Unlock()
+			return nil
+		}
+	}
+
+	p, err := New(
+		[][]string{
+			{"echo", "hello"},
+		},
+		WithWorkingDir(dir),
+		WithPreStartHook(record("pre-start")),
+		WithPostStopHook(record("post-stop")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the command fails to start in the removed working directory
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Start(context.Background()); err == nil || !strings.Contains(err.Error(), "failed to start command") {
+		t.Fatalf("expected start error, got %v", err)
+	}
+	if p.PID() != 0 {
+		t.Fatalf("expected no process, got pid %d", p.PID())
+	}
+	if err := p.(*process).ctx.Err(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the process context to be canceled, got %v", err)
+	}
+
+	expected := []string{"pre-start", "post-stop"}
+	mu.Lock()
+	if !reflect.DeepEqual(events, expected) {
+		mu.Unlock()
+		t.Fatalf("expected hooks %v, got %v", expected, events)
+	}
+	mu.Unlock()
+
+	// the context is released, and the process is not considered started
+	if err := p.Stop(context.Background()); err == nil {
+		t.Fatal("expected error stopping a process that never started")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !reflect.DeepEqual(events, expected) {
+		t.Fatalf("expected no more hooks after stop, got %v", events)
+	}
+}
+
+func TestProcessWithFailingPreStartHook(t *testing.T) {
+	t.Parallel()
+
+	marker := filepath.Join(t.TempDir(), "started")
+	errHook := errors.New("no coredump dir")
+	postStopCalled := false
+
+	p, err := New(
+		[][]string{
+			{"touch", marker},
+		},
+		WithPreStartHook(func(context.Context) error {
+			return errHook
+		}),
+		WithPostStopHook(func(context.Context) error {
+			postStopCalled = true
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.Start(context.Background()); !errors.Is(err, errHook) {
+		t.Fatalf("expected pre-start hook error, got %v", err)
+	}
+	if p.PID() != 0 {
+		t.Fatalf("expected no process, got pid %d", p.PID())
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Fatalf("expected the command to not run, got %v", err)
+	}
+	if err := p.Stop(context.Background()); err == nil {
+		t.Fatal("expected error stopping a process that never started")
+	}
+	if postStopCalled {
+		t.Fatal("expected no post-stop hook without a start")
+	}
+}
+
+func TestProcessStartedAt(t *testing.T) {
+	t.Parallel()
+
+	p, err := New(
+		[][]string{
+			{"sleep 0.2 && exit 1"},
+		},
+		WithRunAsBashScript(),
+		WithRestartConfig(RestartConfig{
+			OnError:  true,
+			Limit:    1,
+			Interval: 100 * time.Millisecond,
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.StartedAt().IsZero() || p.Running() {
+		t.Fatal("expected zero start time and not running before start")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	before := time.Now().UTC()
+	if err := p.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	first := p.StartedAt()
+	if first.Before(before) {
+		t.Fatalf("expected start time after %v, got %v", before, first)
+	}
+	if !p.Running() {
+		t.Fatal("expected running process")
+	}
+
+	if err := p.WaitContext(ctx); err == nil {
+		t.Fatal("expected error")
+	}
+	if p.RestartCount() != 1 {
+		t.Fatalf("expected 1 restart, got %d", p.RestartCount())
+	}
+	if p.Running() {
+		t.Fatal("expected exited process")
+	}
+
+	// the restart ran at least the run time and the interval later
+	second := p.StartedAt()
+	if second.Sub(first) < 300*time.Millisecond {
+		t.Fatalf("expected start time updated by the restart, got %v and %v", first, second)
+	}
+
+	if err := p.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !p.StartedAt().Equal(second) {
+		t.Fatalf("expected the last start time kept after stop, got %v", p.StartedAt())
+	}
+}
+
+func TestProcessRestartMinHealthyDuration(t *testing.T) {
+	t.Parallel()
+
+	p, err := New(
+		[][]string{
+			{"sleep 0.2 && exit 1"},
+		},
+		WithRunAsBashScript(),
+		WithRestartConfig(RestartConfig{
+			OnError:            true,
+			Limit:              1,
+			Interval:           10 * time.Millisecond,
+			MinHealthyDuration: 100 * time.Millisecond,
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := p.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// every run is healthy, so the restarts continue past the limit
+	for i := 0; i < 3; i++ {
+		select {
+		case <-ctx.Done():
+			t.Fatal(ctx.Err())
+		case err := <-p.Wait():
+			if err == nil {
+				t.Fatal("expected error")
+			}
+		}
+		if rc := p.RestartCount(); rc > 1 {
+			t.Fatalf("expected the restart count reset after a healthy run, got %d", rc)
+		}
+	}
+
+	if err := p.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProcessRestartMinHealthyDurationCrash(t *testing.T) {
+	t.Parallel()
+
+	p, err := New(
+		[][]string{
+			{"false"},
+		},
+		WithRestartConfig(RestartConfig{
+			OnError:            true,
+			Limit:              2,
+			Interval:           10 * time.Millisecond,
+			MinHealthyDuration: 10 * time.Second,
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := p.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// every run crashes right away, so the limit applies
+	if err := p.WaitContext(ctx); err == nil {
+		t.Fatal("expected error")
+	}
+	if p.RestartCount() != 2 {
+		t.Fatalf("expected 2 restarts, got %d", p.RestartCount())
+	}
+
+	if err := p.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProcessWithStallTimeout(t *testing.T) {
+	t.Parallel()
+
+	tmpFile, err := os.CreateTemp("", "process-test-*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	// prints once, then hangs without exiting
+	p, err := New(
+		[][]string{
+			{"echo hello && sleep 1000"},
+		},
+		WithOutputFile(tmpFile),
+		WithRunAsBashScript(),
+		WithStallTimeout(300*time.Millisecond),
+		WithGracefulShutdownTimeout(500*time.Millisecond),
+		WithRestartConfig(RestartConfig{
+			OnError:  true,
+			Limit:    1,
+			Interval: 10 * time.Millisecond,
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	started := time.Now()
+	if err := p.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// the initial run and the restarted run both stall
+	for i := 0; i < 2; i++ {
+		select {
+		case <-ctx.Done():
+			t.Fatal(ctx.Err())
+		case err := <-p.Wait():
+			if !errors.Is(err, ErrProcessStalled) {
+				t.Fatalf("expected %v, got %v", ErrProcessStalled, err)
+			}
+		}
+	}
+	if err := p.WaitContext(ctx); !errors.Is(err, ErrProcessStalled) {
+		t.Fatalf("expected %v, got %v", ErrProcessStalled, err)
+	}
+	if elapsed := time.Since(started); elapsed < 600*time.Millisecond {
+		t.Fatalf("expected the runs to be stopped after the stall window, took %v", elapsed)
+	}
+	if rc := p.RestartCount(); rc != 1 {
+		t.Fatalf("expected 1 restart, got %d", rc)
+	}
+
+	content, err := os.ReadFile(tmpFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(content), "hello"); n != 2 {
+		t.Fatalf("expected 2 outputs from the restarted runs, got %d (%q)", n, string(content))
+	}
+
+	if err := p.Stop(ctx); err != nil && !errors.Is(err, ErrProcessKilled) {
+		t.Fatal(err)
+	}
+}
+
+func TestProcessWithStallTimeoutActiveOutput(t *testing.T) {
+	t.Parallel()
+
+	// keeps printing, so never considered stalled
+	p, err := New(
+		[][]string{
+			{"bash", "-c", "for i in 1 2 3 4 5 6 7 8; do echo $i; sleep 0.1; done"},
+		},
+		WithStallTimeout(300*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := p.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	lines, errc := p.StdoutLines(ctx)
+	for range lines {
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	if err := p.WaitContext(ctx); err != nil {
+		t.Fatalf("expected the process to complete, got %v", err)
+	}
+	if err := p.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := New([][]string{{"echo"}}, WithStallTimeout(-time.Second)); err == nil {
+		t.Fatal("expected error for negative stall timeout")
+	}
+}
+
+func TestValidateCommands(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	script := filepath.Join(dir, "helper.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho ok\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	notExecutable := filepath.Join(dir, "not-executable.sh")
+	if err := os.WriteFile(notExecutable, []byte("#!/bin/sh\necho ok\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		commands   [][]string
+		bash       bool
+		workingDir string
+		wantErr    bool
+	}{
+		// the script lines are not resolved as the commands
+		{name: "bash compound", commands: [][]string{{"echo hello && echo 111 | grep 1"}}, bash: true},
+		{name: "bash for loop", commands: [][]string{{"for i in 1 2 3; do echo $i; done"}}, bash: true},
+		{name: "bash if", commands: [][]string{{"if true; then echo ok; fi"}}, bash: true},
+		{name: "bash env prefix", commands: [][]string{{"FOO=bar env"}}, bash: true},
+		{name: "bash multiple lines", commands: [][]string{{"cd /tmp"}, {"ls -la"}}, bash: true},
+		{name: "bash empty line", commands: [][]string{{""}}, bash: true, wantErr: true},
+
+		{name: "argv", commands: [][]string{{"echo", "hello"}}},
+		{name: "absolute path", commands: [][]string{{script, "arg"}}},
+		{name: "relative path in working dir", commands: [][]string{{"./helper.sh"}}, workingDir: dir},
+		{name: "relative path not found", commands: [][]string{{"./helper-does-not-exist.sh"}}, workingDir: dir, wantErr: true},
+		{name: "absolute path not found", commands: [][]string{{filepath.Join(dir, "not-exist")}}, wantErr: true},
+		{name: "not executable", commands: [][]string{{notExecutable}}, wantErr: true},
+		{name: "directory", commands: [][]string{{dir}}, wantErr: true},
+		{name: "not argv", commands: [][]string{{"echo hello"}}, wantErr: true},
+		{name: "command not found", commands: [][]string{{"command-does-not-exist"}}, wantErr: true},
+		{name: "multiple commands without bash", commands: [][]string{{"echo", "a"}, {"echo", "b"}}, wantErr: true},
+		{name: "empty argv", commands: [][]string{{}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCommands(tt.commands, tt.bash, tt.workingDir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateCommands() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProcessBashCompoundCommand(t *testing.T) {
+	t.Parallel()
+
+	p, err := New(
+		[][]string{
+			// sleep to keep the pipes open until the lines are read
+			// (the pipes are closed once the command exits)
+			{"for i in 1 2 3; do echo $i; done && sleep 1"},
+		},
+		WithRunAsBashScript(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := p.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	lines, errc := p.StdoutLines(ctx)
+	got := make([]string, 0)
+	for line := range lines {
+		got = append(got, line)
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, []string{"1", "2", "3"}) {
+		t.Fatalf("expected [1 2 3], got %v", got)
+	}
+	if err := p.WaitContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProcessAllowedCommands(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	script := filepath.Join(dir, "helper.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho ok\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "helper-link.sh")
+	if err := os.Symlink(script, link); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		commands [][]string
+		allowed  []string
+		opts     []OpOption
+		// the command name in the error, empty if allowed
+		denied string
+	}{
+		{name: "allowed by name", commands: [][]string{{"echo", "hello"}}, allowed: []string{"echo"}},
+		{name: "allowed by path", commands: [][]string{{script}}, allowed: []string{script}},
+		{name: "symlink to the allowed path", commands: [][]string{{link}}, allowed: []string{script}},
+		{name: "allowed symlink", commands: [][]string{{script}}, allowed: []string{link}},
+		{name: "relative path in working dir", commands: [][]string{{"./helper.sh"}}, allowed: []string{script}, opts: []OpOption{WithWorkingDir(dir)}},
+		{name: "allowlist with missing command", commands: [][]string{{"echo", "hello"}}, allowed: []string{"command-does-not-exist", "echo"}},
+		{name: "bash allowed", commands: [][]string{{"echo hello && echo 111"}}, allowed: []string{"bash"}, opts: []OpOption{WithRunAsBashScript()}},
+
+		{name: "denied", commands: [][]string{{"echo", "hello"}}, allowed: []string{script}, denied: "echo"},
+		{name: "denied empty allowlist", commands: [][]string{{"echo", "hello"}}, allowed: []string{}, denied: "echo"},
+		{name: "denied bash", commands: [][]string{{"echo hello"}}, allowed: []string{"echo"}, opts: []OpOption{WithRunAsBashScript()}, denied: "bash"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]OpOption{WithAllowedCommands(tt.allowed)}, tt.opts...)
+
+			// the dry run rejects the same commands as New
+			_, verr := Validate(tt.commands, opts...)
+			if tt.denied != "" && !errors.Is(verr, ErrCommandNotAllowed) {
+				t.Fatalf("expected %v from Validate, got %v", ErrCommandNotAllowed, verr)
+			}
+			if tt.denied == "" && verr != nil {
+				t.Fatalf("expected no error from Validate, got %v", verr)
+			}
+
+			p, err := New(tt.commands, opts...)
+			if tt.denied != "" {
+				if !errors.Is(err, ErrCommandNotAllowed) {
+					t.Fatalf("expected %v, got %v", ErrCommandNotAllowed, err)
+				}
+				if !strings.Contains(err.Error(), fmt.Sprintf("%q", tt.denied)) {
+					t.Fatalf("expected the error to name the command %q, got %v", tt.denied, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p == nil {
+				t.Fatal("expected a process")
+			}
+		})
+	}
+
+	if _, err := New([][]string{{"echo", "hello"}}, WithAllowedCommands([]string{""})); err == nil {
+		t.Fatal("expected error for the empty allowed command")
+	}
+}
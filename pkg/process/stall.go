@@ -0,0 +1,94 @@
+package process
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/leptonai/gpud/log"
+)
+
+// ErrProcessStalled wraps the exit error of the process that was stopped
+// for no output within the stall timeout (see WithStallTimeout).
+var ErrProcessStalled = errors.New("process stalled with no output")
+
+// activityReader records the time of the last read bytes.
+type activityReader struct {
+	r    io.ReadCloser
+	last *atomic.Int64
+}
+
+func (ar *activityReader) Read(b []byte) (int, error) {
+	n, err := ar.r.Read(b)
+	if n > 0 {
+		ar.last.Store(time.Now().UnixNano())
+	}
+	return n, err
+}
+
+func (ar *activityReader) Close() error {
+	return ar.r.Close()
+}
+
+// activityWriter records the time of the last written bytes.
+type activityWriter struct {
+	w    io.Writer
+	last *atomic.Int64
+}
+
+func (aw *activityWriter) Write(b []byte) (int, error) {
+	if len(b) > 0 {
+		aw.last.Store(time.Now().UnixNano())
+	}
+	return aw.w.Write(b)
+}
+
+// Returns the interval to check the output activity for the stall timeout.
+func stallCheckInterval(timeout time.Duration) time.Duration {
+	interval := timeout / 4
+	if interval < 10*time.Millisecond {
+		interval = 10 * time.Millisecond
+	}
+	return interval
+}
+
+// Watches the output activity of the current command run,
+// and stops the command if no output for the stall timeout.
+// The stop signal is sent first, escalated to SIGKILL after the graceful shutdown timeout,
+// so that the command exits with an error (and is restarted if configured).
+// Returns when the command exits.
+func (p *process) watchStall(proc *os.Process, exitedc <-chan struct{}) {
+	ticker := time.NewTicker(stallCheckInterval(p.stallTimeout))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-exitedc:
+			return
+		case <-ticker.C:
+		}
+
+		idle := time.Since(time.Unix(0, p.lastOutput.Load()))
+		if idle < p.stallTimeout {
+			continue
+		}
+
+		log.Logger.Warnw("process stalled with no output, stopping", "pid", proc.Pid, "idle", idle, "stallTimeout", p.stallTimeout)
+		p.stalled.Store(true)
+		if err := signalProcessGroup(proc, p.stopSignal); err != nil && !errors.Is(err, os.ErrProcessDone) {
+			log.Logger.Warnw("failed to send stop signal to stalled process", "error", err)
+		}
+
+		select {
+		case <-exitedc:
+		case <-time.After(p.gracefulShutdownTimeout):
+			if err := signalProcessGroup(proc, syscall.SIGKILL); err != nil && !errors.Is(err, os.ErrProcessDone) {
+				log.Logger.Warnw("failed to send SIGKILL to stalled process", "error", err)
+			}
+		}
+		return
+	}
+}
@@ -0,0 +1,62 @@
+package process
+
+import (
+	"sync"
+	"time"
+)
+
+// State is the restart state of a process, persisted via Store
+// so that the restart count and the backoff survive the restarts
+// of the supervising daemon (e.g., gpud upgrades).
+type State struct {
+	// RestartCount is the number of restarts so far.
+	RestartCount int `json:"restart_count"`
+	// LastExitCode is the exit code of the last run
+	// (-1 if the process was terminated by a signal).
+	LastExitCode int `json:"last_exit_code"`
+	// LastExitTime is the time of the last exit.
+	LastExitTime time.Time `json:"last_exit_time"`
+	// RestartInterval is the interval to wait before the next restart
+	// on an error exit. Zero means RestartConfig.Interval.
+	RestartInterval time.Duration `json:"restart_interval"`
+}
+
+// Store persists the process restart state.
+type Store interface {
+	// Returns the last saved state, or nil if nothing has been saved.
+	Load() (*State, error)
+	// Saves the state, overwriting the previous one.
+	Save(State) error
+}
+
+var _ Store = (*memoryStore)(nil)
+
+type memoryStore struct {
+	mu    sync.RWMutex
+	state *State
+}
+
+// Returns a new in-memory store, which does not survive the daemon restarts.
+// It is the default store, unless set via WithStateStore.
+func NewMemoryStore() Store {
+	return &memoryStore{}
+}
+
+func (s *memoryStore) Load() (*State, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.state == nil {
+		return nil, nil
+	}
+	copied := *s.state
+	return &copied, nil
+}
+
+func (s *memoryStore) Save(state State) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state = &state
+	return nil
+}
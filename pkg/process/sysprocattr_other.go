@@ -0,0 +1,24 @@
+//go:build !unix
+
+package process
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// Validates the daemon can run the process as the given uid/gid.
+func validateCredential(uid, gid uint32) error {
+	return errors.New("running as a different uid/gid is not supported on this platform")
+}
+
+// Sets the OS-specific process attributes.
+func (p *process) setSysProcAttr(cmd *exec.Cmd) {}
+
+// Sends the signal to the process
+// (process groups are not supported on this platform).
+func signalProcessGroup(proc *os.Process, sig syscall.Signal) error {
+	return proc.Signal(sig)
+}
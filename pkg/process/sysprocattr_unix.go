@@ -0,0 +1,54 @@
+//go:build unix
+
+package process
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// Validates the daemon can run the process as the given uid/gid.
+func validateCredential(uid, gid uint32) error {
+	if os.Geteuid() == 0 {
+		return nil
+	}
+	if uid == uint32(os.Geteuid()) && gid == uint32(os.Getegid()) {
+		return nil
+	}
+	return fmt.Errorf("cannot run as uid %d gid %d without root (euid %d): %w", uid, gid, os.Geteuid(), os.ErrPermission)
+}
+
+// Sets the OS-specific process attributes.
+func (p *process) setSysProcAttr(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+
+	// run in its own process group, so that the signals
+	// reach the child processes (e.g., spawned by the bash script)
+	cmd.SysProcAttr.Setpgid = true
+
+	if p.credential == nil {
+		return
+	}
+	cmd.SysProcAttr.Credential = &syscall.Credential{
+		Uid: p.credential.uid,
+		Gid: p.credential.gid,
+	}
+}
+
+// Sends the signal to the process group led by the process,
+// so that the child processes do not leak.
+// Falls back to the process itself if the group is not found.
+func signalProcessGroup(proc *os.Process, sig syscall.Signal) error {
+	if err := syscall.Kill(-proc.Pid, sig); err != nil {
+		if errors.Is(err, syscall.ESRCH) {
+			return proc.Signal(sig)
+		}
+		return err
+	}
+	return nil
+}